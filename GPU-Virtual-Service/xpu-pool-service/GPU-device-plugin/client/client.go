@@ -4,17 +4,18 @@
 
 // Package main implements xpu client tool
 // 用于 Kubernetes GPU 设备插件场景，根据 cgroup 路径查询/更新相关进程 ID 配置，用于资源管理和监控
+// 同时提供 devices、vgpus、pids、config 等子命令，用于在节点上查看 vGPU 的分配与使用情况
 package main
 
 import (
 	"context"
 	"flag"
 	"fmt"
-	"net"
 	"os"
-	"time"
+	"strings"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"huawei.com/vxpu-device-plugin/pkg/api/runtime/service"
 	"huawei.com/vxpu-device-plugin/pkg/log"
@@ -22,31 +23,20 @@ import (
 
 const (
 	pidsSockPath = "/var/lib/xpu/pids.sock"
-	dialTimeout  = 5
 )
 
+// dialSocket 通过 unix:// 目标建立到 Unix Socket 上 gRPC 服务的连接，连接在首次调用时建立
+func dialSocket(sockPath string) (*grpc.ClientConn, error) {
+	return grpc.NewClient("unix://"+sockPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
 func updatePidsConfig(cgroupPath string) error {
-	// 根据配置的 Unix Socket 建立到 pids service 的 gRPC 连接
-	conn, err := grpc.Dial(
-		pidsSockPath,
-		grpc.WithInsecure(),
-		grpc.WithBlock(),
-		grpc.WithTimeout(dialTimeout*time.Second),
-		grpc.WithDialer(
-			func(addr string, timeout time.Duration) (net.Conn, error) {
-				// 使用 Unix Domain Socket 进行拨号，确保超时控制
-				return net.DialTimeout("unix", addr, timeout)
-			}),
-	)
+	conn, err := dialSocket(pidsSockPath)
 	if err != nil {
 		// 连接建立失败，记录日志并返回错误
 		log.Errorf("grpc dial error: %v", err)
 		return err
 	}
-	if conn == nil {
-		// 防御式检查，避免 nil 连接导致后续调用 panic
-		return fmt.Errorf("client connection is nil")
-	}
 	defer conn.Close()
 
 	//根据 cgroup 路径查询/更新相关进程 ID 配置
@@ -59,10 +49,12 @@ func updatePidsConfig(cgroupPath string) error {
 	return nil
 }
 
-func main() {
+// runLegacy 兼容 CUDA hook 的调用方式：xpu-client-tool --cgroup-path <path>
+func runLegacy() {
 	// 通过命令行参数获取目标 cgroup 路径
 	var cgroupPath string
 	flag.StringVar(&cgroupPath, "cgroup-path", "", "cgroup path")
+	flag.Usage = usage
 	flag.Parse()
 
 	// 调用 gRPC 客户端根据 cgroup 路径同步 PID 配置
@@ -73,3 +65,33 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n  %s --cgroup-path <path>\n  %s <command> [flags] [args]\n\nCommands:\n",
+		os.Args[0], os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+func main() {
+	// 无子命令或以 "-" 开头的参数时保持原有的 --cgroup-path 调用方式
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+		runLegacy()
+		return
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+		if err := cmd.run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+	usage()
+	os.Exit(1)
+}
@@ -0,0 +1,382 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"huawei.com/vxpu-device-plugin/pkg/api/runtime/service"
+	"huawei.com/vxpu-device-plugin/pkg/output"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/types"
+)
+
+const (
+	vxpuConfigBaseDir     = "/etc/xpu"
+	vxpuConfigFileName    = "vgpu.config"
+	vxpuIdsConfigFileName = "vgpu-ids.config"
+	usedMemKey            = "UsedMem"
+	usedCoresKey          = "UsedCores"
+	defaultPeriod         = "60"
+	defaultHistoryRange   = 10 * time.Minute
+	milliwattsPerWatt     = 1000
+	requestTimeout        = 30 * time.Second
+	floatFormat           = "%.2f"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{name: "devices", summary: "list the physical xpus and their health", run: runDevices},
+	{name: "vgpus", summary: "list the vgpus allocated to pods with their limits and usage", run: runVgpus},
+	{name: "pids", summary: "list the xpu processes of <pod uid>/<container name>", run: runPids},
+	{name: "config", summary: "show the decoded vgpu config of <pod uid>/<container name>", run: runConfig},
+	{name: "history", summary: "show the usage history of xpus and vgpus", run: runHistory},
+	{name: "doctor", summary: "check the prerequisites of the vgpu stack on this node", run: runDoctor},
+}
+
+type commonFlags struct {
+	output   string
+	sockPath string
+}
+
+func newFlagSet(name, argsUsage string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	cf := &commonFlags{}
+	fs.StringVar(&cf.output, "o", output.Table, "output format, table or json")
+	fs.StringVar(&cf.sockPath, "pids-sock", pidsSockPath, "path of the pids service socket")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags] %s\n", os.Args[0], name, argsUsage)
+		fs.PrintDefaults()
+	}
+	return fs, cf
+}
+
+func parseFlags(fs *flag.FlagSet, cf *commonFlags, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return output.CheckFormat(cf.output)
+}
+
+// withPidsClient calls fn with a pids service client connected to the socket
+func withPidsClient(sockPath string, fn func(ctx context.Context, client service.PidsServiceClient) error) error {
+	conn, err := dialSocket(sockPath)
+	if err != nil {
+		return fmt.Errorf("connect to pids service %s error: %v", sockPath, err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	return fn(ctx, service.NewPidsServiceClient(conn))
+}
+
+func getAllVxpuInfo(sockPath string) ([]*types.XPUDevice, error) {
+	var xpuDevices map[string]*types.XPUDevice
+	err := withPidsClient(sockPath, func(ctx context.Context, client service.PidsServiceClient) error {
+		resp, err := client.GetAllVxpuInfo(ctx, &service.GetAllVxpuInfoRequest{Period: defaultPeriod})
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(resp.VxpuInfos), &xpuDevices)
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*types.XPUDevice, 0, len(xpuDevices))
+	for _, device := range xpuDevices {
+		res = append(res, device)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Index < res[j].Index })
+	return res, nil
+}
+
+// deviceView description of a physical xpu printed by the devices command
+type deviceView struct {
+	Index             int32
+	Id                string
+	Type              string
+	Health            bool
+	MemoryTotal       uint64
+	MemoryUsed        uint64
+	MemoryUtilization float64
+	XpuUtilization    float64
+	PowerUsage        uint32
+	Temperature       uint32
+	VxpuCount         int
+}
+
+func runDevices(args []string) error {
+	fs, cf := newFlagSet("devices", "")
+	if err := parseFlags(fs, cf, args); err != nil {
+		return err
+	}
+	xpuDevices, err := getAllVxpuInfo(cf.sockPath)
+	if err != nil {
+		return err
+	}
+	views := make([]deviceView, 0, len(xpuDevices))
+	for _, d := range xpuDevices {
+		views = append(views, deviceView{
+			Index:             d.Index,
+			Id:                d.Id,
+			Type:              d.Type,
+			Health:            d.Health,
+			MemoryTotal:       d.MemoryTotal,
+			MemoryUsed:        d.MemoryUsed,
+			MemoryUtilization: d.MemoryUtilization,
+			XpuUtilization:    d.XpuUtilization,
+			PowerUsage:        d.PowerUsage,
+			Temperature:       d.Temperature,
+			VxpuCount:         len(d.VxpuDeviceList),
+		})
+	}
+	if cf.output == output.JSON {
+		return output.PrintJSON(os.Stdout, views)
+	}
+	rows := make([][]string, 0, len(views))
+	for _, v := range views {
+		health := "Healthy"
+		if !v.Health {
+			health = "Unhealthy"
+		}
+		rows = append(rows, []string{strconv.Itoa(int(v.Index)), v.Id, v.Type, health,
+			strconv.FormatUint(v.MemoryTotal, 10), strconv.FormatUint(v.MemoryUsed, 10),
+			fmt.Sprintf(floatFormat, v.MemoryUtilization), fmt.Sprintf(floatFormat, v.XpuUtilization),
+			fmt.Sprintf(floatFormat, float64(v.PowerUsage)/milliwattsPerWatt), strconv.Itoa(int(v.Temperature)),
+			strconv.Itoa(v.VxpuCount)})
+	}
+	return output.PrintTable(os.Stdout, []string{"INDEX", "UUID", "TYPE", "HEALTH", "MEMORY(MiB)", "USED(MiB)",
+		"MEM(%)", "UTIL(%)", "POWER(W)", "TEMP(C)", "VGPUS"}, rows)
+}
+
+func runVgpus(args []string) error {
+	fs, cf := newFlagSet("vgpus", "")
+	podUID := fs.String("pod", "", "only list the vgpus of the pod uid")
+	if err := parseFlags(fs, cf, args); err != nil {
+		return err
+	}
+	xpuDevices, err := getAllVxpuInfo(cf.sockPath)
+	if err != nil {
+		return err
+	}
+	vxpus := make(types.VxpuDevices, 0)
+	for _, d := range xpuDevices {
+		for _, v := range d.VxpuDeviceList {
+			if *podUID == "" || v.PodUID == *podUID {
+				vxpus = append(vxpus, v)
+			}
+		}
+	}
+	sort.Slice(vxpus, func(i, j int) bool {
+		if vxpus[i].PodUID != vxpus[j].PodUID {
+			return vxpus[i].PodUID < vxpus[j].PodUID
+		}
+		if vxpus[i].ContainerName != vxpus[j].ContainerName {
+			return vxpus[i].ContainerName < vxpus[j].ContainerName
+		}
+		return vxpus[i].Id < vxpus[j].Id
+	})
+	if cf.output == output.JSON {
+		return output.PrintJSON(os.Stdout, vxpus)
+	}
+	rows := make([][]string, 0, len(vxpus))
+	for _, v := range vxpus {
+		exceeded := make([]string, 0)
+		if v.MemoryLimitExceeded {
+			exceeded = append(exceeded, "memory")
+		}
+		if v.CoreLimitExceeded {
+			exceeded = append(exceeded, "core")
+		}
+		rows = append(rows, []string{v.PodUID, v.ContainerName, v.Id, v.GpuId,
+			strconv.FormatInt(v.VxpuMemoryLimit, 10), strconv.FormatUint(v.VxpuMemoryUsed, 10),
+			fmt.Sprintf(floatFormat, v.VxpuMemoryUtilization), strconv.FormatInt(v.VxpuCoreLimit, 10),
+			fmt.Sprintf(floatFormat, v.VxpuCoreUtilization), strings.Join(exceeded, ",")})
+	}
+	return output.PrintTable(os.Stdout, []string{"POD", "CONTAINER", "VGPU", "GPU", "MEM_LIMIT(MiB)", "MEM_USED(MiB)",
+		"MEM(%)", "CORE_LIMIT(%)", "CORE(%)", "EXCEEDED"}, rows)
+}
+
+// parseContainerArg parses "<pod uid>/<container name>"
+func parseContainerArg(fs *flag.FlagSet) (string, string, error) {
+	if fs.NArg() != 1 {
+		return "", "", errors.New("expect one argument <pod uid>/<container name>")
+	}
+	items := strings.Split(fs.Arg(0), "/")
+	if len(items) != 2 || items[0] == "" || items[1] == "" || items[0] == ".." || items[1] == ".." {
+		return "", "", fmt.Errorf("invalid argument %q, expect <pod uid>/<container name>", fs.Arg(0))
+	}
+	return items[0], items[1], nil
+}
+
+func runPids(args []string) error {
+	fs, cf := newFlagSet("pids", "<pod uid>/<container name>")
+	period := fs.String("period", defaultPeriod, "sample window of the sm utilization, in seconds")
+	if err := parseFlags(fs, cf, args); err != nil {
+		return err
+	}
+	podUID, containerName, err := parseContainerArg(fs)
+	if err != nil {
+		return err
+	}
+	var processes types.ContainerProcesses
+	err = withPidsClient(cf.sockPath, func(ctx context.Context, client service.PidsServiceClient) error {
+		resp, err := client.GetContainerProcesses(ctx, &service.GetContainerProcessesRequest{
+			PodUID:        podUID,
+			ContainerName: containerName,
+			Period:        *period,
+		})
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(resp.ContainerProcesses), &processes)
+	})
+	if err != nil {
+		return err
+	}
+	if cf.output == output.JSON {
+		return output.PrintJSON(os.Stdout, processes)
+	}
+	rows := make([][]string, 0, len(processes))
+	for _, p := range processes {
+		rows = append(rows, []string{strconv.FormatUint(uint64(p.HostPid), 10),
+			strconv.FormatUint(uint64(p.ContainerPid), 10), p.Command, p.GpuId,
+			strconv.FormatUint(p.ProcessMemoryUsed, 10), strconv.FormatUint(p.ProcessCoreUtilization, 10)})
+	}
+	return output.PrintTable(os.Stdout, []string{"HOST_PID", "CONTAINER_PID", "COMMAND", "GPU", "MEM(MiB)", "SM(%)"}, rows)
+}
+
+// vxpuConfig decoded vgpu.config and vgpu-ids.config of a container
+type vxpuConfig struct {
+	PodUID        string
+	ContainerName string
+	UsedMem       int
+	UsedCores     int
+	VxpuIds       []string
+}
+
+// readVxpuConfig decodes the vgpu.config with "UsedMem:<n>" and "UsedCores:<n>" lines
+// and the vgpu-ids.config with a "<xpu uuid>-<vid>" line per vgpu in the dir
+func readVxpuConfig(dir string) (*vxpuConfig, error) {
+	conf := &vxpuConfig{VxpuIds: make([]string, 0)}
+	data, err := os.ReadFile(filepath.Join(dir, vxpuConfigFileName))
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		items := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(items) != 2 {
+			continue
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(items[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %q in %s", line, vxpuConfigFileName)
+		}
+		switch items[0] {
+		case usedMemKey:
+			conf.UsedMem = value
+		case usedCoresKey:
+			conf.UsedCores = value
+		default:
+			return nil, fmt.Errorf("unknown key %q in %s", items[0], vxpuConfigFileName)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(dir, vxpuIdsConfigFileName))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if idx := strings.LastIndex(line, "-"); idx <= 0 {
+			return nil, fmt.Errorf("invalid line %q in %s", line, vxpuIdsConfigFileName)
+		}
+		conf.VxpuIds = append(conf.VxpuIds, line)
+	}
+	return conf, scanner.Err()
+}
+
+func runConfig(args []string) error {
+	fs, cf := newFlagSet("config", "<pod uid>/<container name>")
+	baseDir := fs.String("config-dir", vxpuConfigBaseDir, "base dir of the vgpu configs")
+	if err := parseFlags(fs, cf, args); err != nil {
+		return err
+	}
+	podUID, containerName, err := parseContainerArg(fs)
+	if err != nil {
+		return err
+	}
+	conf, err := readVxpuConfig(filepath.Join(*baseDir, podUID, containerName))
+	if err != nil {
+		return err
+	}
+	conf.PodUID = podUID
+	conf.ContainerName = containerName
+	if cf.output == output.JSON {
+		return output.PrintJSON(os.Stdout, conf)
+	}
+	return output.PrintTable(os.Stdout, []string{"FIELD", "VALUE"}, [][]string{
+		{"POD", conf.PodUID},
+		{"CONTAINER", conf.ContainerName},
+		{"USED_MEM", strconv.Itoa(conf.UsedMem)},
+		{"USED_CORES", strconv.Itoa(conf.UsedCores)},
+		{"VGPU_IDS", strings.Join(conf.VxpuIds, ",")},
+	})
+}
+
+func runHistory(args []string) error {
+	fs, cf := newFlagSet("history", "")
+	id := fs.String("id", "", "only show the history of the xpu or vgpu id")
+	since := fs.Duration("since", defaultHistoryRange, "show the history in the duration until now")
+	if err := parseFlags(fs, cf, args); err != nil {
+		return err
+	}
+	var histories []types.UsageHistory
+	err := withPidsClient(cf.sockPath, func(ctx context.Context, client service.PidsServiceClient) error {
+		resp, err := client.GetVxpuHistory(ctx, &service.GetVxpuHistoryRequest{
+			StartTime: strconv.FormatInt(time.Now().Add(-*since).Unix(), 10),
+			Id:        *id,
+		})
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(resp.VxpuHistory), &histories)
+	})
+	if err != nil {
+		return err
+	}
+	if cf.output == output.JSON {
+		return output.PrintJSON(os.Stdout, histories)
+	}
+	rows := make([][]string, 0, len(histories))
+	for _, h := range histories {
+		rows = append(rows, []string{h.Id, h.Type, h.PodUID, h.ContainerName, strconv.Itoa(len(h.Samples)),
+			fmt.Sprintf(floatFormat, h.CoreUtilization.Avg), fmt.Sprintf(floatFormat, h.CoreUtilization.Max),
+			fmt.Sprintf(floatFormat, h.CoreUtilization.P95), fmt.Sprintf(floatFormat, h.MemoryUsed.Avg),
+			fmt.Sprintf(floatFormat, h.MemoryUsed.Max), fmt.Sprintf(floatFormat, h.MemoryUsed.P95)})
+	}
+	return output.PrintTable(os.Stdout, []string{"ID", "TYPE", "POD", "CONTAINER", "SAMPLES", "CORE_AVG(%)",
+		"CORE_MAX(%)", "CORE_P95(%)", "MEM_AVG(MiB)", "MEM_MAX(MiB)", "MEM_P95(MiB)"}, rows)
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeVxpuConfig writes the vgpu.config and the vgpu-ids.config into a temp dir, a file is skipped if
+// its content is nil
+func writeVxpuConfig(t *testing.T, config, ids *string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]*string{vxpuConfigFileName: config, vxpuIdsConfigFileName: ids}
+	for name, content := range files {
+		if content == nil {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(*content), 0644); err != nil {
+			t.Fatalf("write %s error: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestReadVxpuConfig(t *testing.T) {
+	const ids = "GPU-1b2c3d4e-0000-1111-2222-333344445555-0\n\nGPU-1b2c3d4e-0000-1111-2222-333344445555-1\n"
+	tests := []struct {
+		name    string
+		config  *string
+		ids     *string
+		want    *vxpuConfig
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			config: ptr("UsedMem:4096\nUsedCores:30\n"),
+			ids:    ptr(ids),
+			want: &vxpuConfig{UsedMem: 4096, UsedCores: 30, VxpuIds: []string{
+				"GPU-1b2c3d4e-0000-1111-2222-333344445555-0", "GPU-1b2c3d4e-0000-1111-2222-333344445555-1"}},
+		},
+		{
+			name:   "spaces and blank lines",
+			config: ptr("\n UsedMem: 1024 \nUsedCores:0"),
+			ids:    ptr(""),
+			want:   &vxpuConfig{UsedMem: 1024, VxpuIds: []string{}},
+		},
+		{name: "invalid value", config: ptr("UsedMem:1G\n"), ids: ptr(ids), wantErr: true},
+		{name: "unknown key", config: ptr("UsedMem:1\nUsedDisk:2\n"), ids: ptr(ids), wantErr: true},
+		{name: "invalid vgpu id", config: ptr("UsedMem:1\n"), ids: ptr("GPU0\n"), wantErr: true},
+		{name: "missing vgpu config", ids: ptr(ids), wantErr: true},
+		{name: "missing vgpu ids config", config: ptr("UsedMem:1\n"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readVxpuConfig(writeVxpuConfig(t, tt.config, tt.ids))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readVxpuConfig error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readVxpuConfig = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseContainerArg(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantPodUID    string
+		wantContainer string
+		wantErr       bool
+	}{
+		{name: "valid", args: []string{"pod-uid/main"}, wantPodUID: "pod-uid", wantContainer: "main"},
+		{name: "no argument", args: []string{}, wantErr: true},
+		{name: "two arguments", args: []string{"pod-uid/main", "extra"}, wantErr: true},
+		{name: "no container", args: []string{"pod-uid"}, wantErr: true},
+		{name: "empty container", args: []string{"pod-uid/"}, wantErr: true},
+		{name: "empty pod uid", args: []string{"/main"}, wantErr: true},
+		{name: "nested path", args: []string{"pod-uid/main/extra"}, wantErr: true},
+		{name: "parent pod uid", args: []string{"../main"}, wantErr: true},
+		{name: "parent container", args: []string{"pod-uid/.."}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("parse args error: %v", err)
+			}
+			podUID, containerName, err := parseContainerArg(fs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseContainerArg error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if podUID != tt.wantPodUID || containerName != tt.wantContainer {
+				t.Errorf("parseContainerArg = %q, %q, want %q, %q", podUID, containerName,
+					tt.wantPodUID, tt.wantContainer)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	"huawei.com/vxpu-device-plugin/pkg/lock"
+	"huawei.com/vxpu-device-plugin/pkg/output"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/types"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/util"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/xpu"
+)
+
+const (
+	checkPass = "pass"
+	checkFail = "fail"
+	checkWarn = "warn"
+
+	defaultLibPath      = "/usr/lib64/libcuda.so"
+	cudaOriginalPath    = "/opt/xpu/lib/libcuda-original.so"
+	clientToolPath      = "/opt/xpu/bin/xpu-client-tool"
+	pluginSockName      = "xpu.sock"
+	kubeletCheckpoint   = "kubelet_internal_checkpoint"
+	handshakePrefix     = "Reported_"
+	handshakeTimeFormat = "2006.01.02 15:04:05"
+	defaultMaxAge       = 2 * time.Minute
+	healthCheckTimeout  = 5 * time.Second
+)
+
+// hookMarker is compiled into libcuda_direct.so which executes the client tool to register the container pids
+var hookMarker = []byte(clientToolPath)
+
+// checkResult result of one doctor check
+type checkResult struct {
+	Name   string
+	Status string
+	Detail string
+	Hint   string `json:",omitempty"`
+}
+
+// doctorReport machine-readable report of the doctor command
+type doctorReport struct {
+	Node    string
+	Time    string
+	Passed  bool
+	Results []checkResult
+}
+
+type doctorOptions struct {
+	libPath      string
+	sockPath     string
+	resourceName string
+	nodeName     string
+	configDir    string
+	maxAge       time.Duration
+}
+
+func pass(name, detail string) checkResult {
+	return checkResult{Name: name, Status: checkPass, Detail: detail}
+}
+
+func fail(name, detail, hint string) checkResult {
+	return checkResult{Name: name, Status: checkFail, Detail: detail, Hint: hint}
+}
+
+func warn(name, detail, hint string) checkResult {
+	return checkResult{Name: name, Status: checkWarn, Detail: detail, Hint: hint}
+}
+
+func checkLibcudaHook(opts *doctorOptions) checkResult {
+	const name = "libcuda-hook"
+	const hint = "make sure the gpu-client-update daemonset is running, " +
+		"it replaces libcuda with libcuda_direct.so by cuda-client-update.sh"
+	realPath, err := filepath.EvalSymlinks(opts.libPath)
+	if err != nil {
+		return fail(name, fmt.Sprintf("resolve %s error: %v", opts.libPath, err), hint)
+	}
+	data, err := os.ReadFile(realPath)
+	if err != nil {
+		return fail(name, fmt.Sprintf("read %s error: %v", realPath, err), hint)
+	}
+	if !bytes.Contains(data, hookMarker) {
+		return fail(name, fmt.Sprintf("%s is not the vgpu hook build", realPath), hint)
+	}
+	return pass(name, fmt.Sprintf("%s is the vgpu hook build", realPath))
+}
+
+func checkLibcudaBackup(opts *doctorOptions) checkResult {
+	const name = "libcuda-backup"
+	const hint = "the original driver library is backed up by cuda-client-update.sh on its first run, " +
+		"reinstall the nvidia driver and restart the gpu-client-update daemonset if it is lost"
+	data, err := os.ReadFile(cudaOriginalPath)
+	if err != nil {
+		return fail(name, fmt.Sprintf("read %s error: %v", cudaOriginalPath, err), hint)
+	}
+	if len(data) == 0 || bytes.Contains(data, hookMarker) {
+		return fail(name, fmt.Sprintf("%s is not the original driver library", cudaOriginalPath), hint)
+	}
+	return pass(name, fmt.Sprintf("%s exists", cudaOriginalPath))
+}
+
+func checkClientTool(opts *doctorOptions) checkResult {
+	const name = "client-tool"
+	info, err := os.Stat(clientToolPath)
+	if err != nil {
+		return fail(name, fmt.Sprintf("stat %s error: %v", clientToolPath, err),
+			"the client tool is installed by cuda-client-update.sh, check the gpu-client-update daemonset")
+	}
+	if info.Mode()&0111 == 0 {
+		return fail(name, fmt.Sprintf("%s is not executable", clientToolPath),
+			"restart the gpu-client-update daemonset to reinstall the client tool")
+	}
+	return pass(name, fmt.Sprintf("%s is installed", clientToolPath))
+}
+
+// checkSocketHealth calls the grpc health service on the unix socket
+func checkSocketHealth(name, sockPath, service, hint string) checkResult {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	conn, err := dialSocket(sockPath)
+	if err != nil {
+		return fail(name, fmt.Sprintf("connect to %s error: %v", sockPath, err), hint)
+	}
+	defer conn.Close()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if status.Code(err) == codes.Unimplemented {
+		return warn(name, fmt.Sprintf("%s answers but has no health service", sockPath),
+			"upgrade the device plugin to report the serving status")
+	}
+	if err != nil {
+		return fail(name, fmt.Sprintf("health check on %s error: %v", sockPath, err), hint)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fail(name, fmt.Sprintf("%s is %s", sockPath, resp.Status), hint)
+	}
+	return pass(name, fmt.Sprintf("%s is serving", sockPath))
+}
+
+func checkPidsSocket(opts *doctorOptions) checkResult {
+	return checkSocketHealth("pids-socket", opts.sockPath, "PidsService",
+		"check the logs of the gpu-device-plugin pod on this node")
+}
+
+func checkPluginSocket(opts *doctorOptions) checkResult {
+	return checkSocketHealth("plugin-socket", filepath.Join(v1beta1.DevicePluginPath, pluginSockName),
+		"v1beta1.DevicePlugin", "check the logs of the gpu-device-plugin pod on this node")
+}
+
+func checkKubeletRegistration(opts *doctorOptions) checkResult {
+	const name = "kubelet-registration"
+	const hint = "the plugin registers itself on start and when kubelet restarts, " +
+		"restart the gpu-device-plugin pod on this node"
+	if _, err := os.Stat(v1beta1.KubeletSocket); err != nil {
+		return fail(name, fmt.Sprintf("kubelet socket %s error: %v", v1beta1.KubeletSocket, err),
+			"check that kubelet is running and the device plugin dir is mounted")
+	}
+	data, err := os.ReadFile(filepath.Join(v1beta1.DevicePluginPath, kubeletCheckpoint))
+	if err != nil {
+		return fail(name, fmt.Sprintf("read kubelet checkpoint error: %v", err), hint)
+	}
+	var checkpoint struct {
+		Data struct {
+			RegisteredDevices map[string][]string
+		}
+	}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return fail(name, fmt.Sprintf("decode kubelet checkpoint error: %v", err), hint)
+	}
+	devices, ok := checkpoint.Data.RegisteredDevices[opts.resourceName]
+	if !ok {
+		return fail(name, fmt.Sprintf("%s is not registered with kubelet", opts.resourceName), hint)
+	}
+	return pass(name, fmt.Sprintf("%s is registered with %d devices", opts.resourceName, len(devices)))
+}
+
+func checkNodeAnnotations(opts *doctorOptions) []checkResult {
+	const hint = "the annotations are reported by the gpu-device-plugin every 30 seconds, " +
+		"check its logs and its permission to patch nodes"
+	if lock.GetClient() == nil {
+		return []checkResult{fail("node-annotations", "no kubernetes client",
+			"set KUBECONFIG or run the tool in a pod with a service account")}
+	}
+	node, err := util.GetNode(opts.nodeName)
+	if err != nil {
+		return []checkResult{fail("node-annotations", fmt.Sprintf("get node %s error: %v", opts.nodeName, err),
+			"set the node name with -node-name")}
+	}
+	annos := node.Annotations
+	results := make([]checkResult, 0)
+
+	register, ok := annos[xpu.NodeVXPURegister]
+	devices := util.DecodeNodeDevices(register)
+	switch {
+	case !ok:
+		results = append(results, fail("register-annotation", xpu.NodeVXPURegister+" not found", hint))
+	case len(devices) == 0:
+		results = append(results, fail("register-annotation", xpu.NodeVXPURegister+" has no devices", hint))
+	default:
+		results = append(results, pass("register-annotation", fmt.Sprintf("%d devices registered", len(devices))))
+	}
+
+	handshake := annos[xpu.NodeVXPUHandshake]
+	reported, err := time.ParseInLocation(handshakeTimeFormat, strings.TrimPrefix(handshake, handshakePrefix),
+		time.Local)
+	switch {
+	case !strings.HasPrefix(handshake, handshakePrefix) || err != nil:
+		results = append(results, fail("handshake-annotation",
+			fmt.Sprintf("%s is invalid: %q", xpu.NodeVXPUHandshake, handshake), hint))
+	case time.Since(reported) > opts.maxAge:
+		results = append(results, fail("handshake-annotation",
+			fmt.Sprintf("last reported at %s, older than %v", reported.Format(handshakeTimeFormat), opts.maxAge), hint))
+	default:
+		results = append(results, pass("handshake-annotation",
+			"last reported at "+reported.Format(handshakeTimeFormat)))
+	}
+
+	if _, ok := annos[xpu.NodeXpuTopology]; !ok {
+		results = append(results, warn("topology-annotation", xpu.NodeXpuTopology+" not found", hint))
+	} else {
+		results = append(results, pass("topology-annotation", xpu.NodeXpuTopology+" exists"))
+	}
+
+	results = append(results, checkPodAnnotations(opts, devices))
+	return results
+}
+
+// checkPodAnnotations checks that the vgpus assigned to the pods on the node decode to registered devices
+func checkPodAnnotations(opts *doctorOptions, devices map[string]*types.XPUDevice) checkResult {
+	const name = "pod-annotations"
+	selector := fields.SelectorFromSet(fields.Set{"spec.nodeName": opts.nodeName})
+	podList, err := util.ListPods(metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return fail(name, fmt.Sprintf("list pods on node %s error: %v", opts.nodeName, err),
+			"check the permission to list pods")
+	}
+	count := 0
+	for _, pod := range podList.Items {
+		assigned, ok := pod.Annotations[xpu.AssignedIDs]
+		if !ok {
+			continue
+		}
+		count++
+		for _, cd := range util.DecodePodDevices(assigned) {
+			for _, d := range cd {
+				if _, ok := devices[d.UUID]; !ok {
+					return fail(name, fmt.Sprintf("pod %s/%s is assigned to unknown gpu %s",
+						pod.Namespace, pod.Name, d.UUID), "delete the pod to let it be rescheduled")
+				}
+			}
+		}
+	}
+	return pass(name, fmt.Sprintf("%d pods with vgpus decoded", count))
+}
+
+// checkVxpuConfigs checks that the vgpu configs of every container in the config dir decode
+func checkVxpuConfigs(opts *doctorOptions) checkResult {
+	const name = "vgpu-configs"
+	const hint = "the configs are written by the device plugin on allocation, recreate the pod"
+	podDirs, err := os.ReadDir(opts.configDir)
+	if err != nil {
+		return fail(name, fmt.Sprintf("read %s error: %v", opts.configDir, err),
+			"check that "+opts.configDir+" is mounted to the device plugin")
+	}
+	count := 0
+	for _, podDir := range podDirs {
+		if !podDir.IsDir() {
+			continue
+		}
+		containerDirs, err := os.ReadDir(filepath.Join(opts.configDir, podDir.Name()))
+		if err != nil {
+			return fail(name, err.Error(), hint)
+		}
+		for _, containerDir := range containerDirs {
+			if !containerDir.IsDir() {
+				continue
+			}
+			dir := filepath.Join(opts.configDir, podDir.Name(), containerDir.Name())
+			if _, err := readVxpuConfig(dir); err != nil {
+				return fail(name, fmt.Sprintf("decode %s error: %v", dir, err), hint)
+			}
+			count++
+		}
+	}
+	return pass(name, fmt.Sprintf("%d container configs decoded", count))
+}
+
+func runDoctor(args []string) error {
+	fs, cf := newFlagSet("doctor", "")
+	opts := &doctorOptions{}
+	fs.StringVar(&opts.libPath, "lib-path", defaultLibPath, "path of the libcuda.so used by the containers")
+	fs.StringVar(&opts.resourceName, "resource-name", xpu.VxpuNumber, "resource name of the device plugin")
+	fs.StringVar(&opts.nodeName, "node-name", os.Getenv("NODE_NAME"), "node name, defaults to the hostname")
+	fs.StringVar(&opts.configDir, "config-dir", vxpuConfigBaseDir, "base dir of the vgpu configs")
+	fs.DurationVar(&opts.maxAge, "max-age", defaultMaxAge, "maximum age of the handshake annotation")
+	if err := parseFlags(fs, cf, args); err != nil {
+		return err
+	}
+	opts.sockPath = cf.sockPath
+	if opts.nodeName == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		opts.nodeName = hostname
+	}
+
+	results := []checkResult{
+		checkLibcudaHook(opts),
+		checkLibcudaBackup(opts),
+		checkClientTool(opts),
+		checkPidsSocket(opts),
+		checkPluginSocket(opts),
+		checkKubeletRegistration(opts),
+	}
+	results = append(results, checkNodeAnnotations(opts)...)
+	results = append(results, checkVxpuConfigs(opts))
+
+	report := doctorReport{Node: opts.nodeName, Time: time.Now().Format(time.RFC3339), Passed: true,
+		Results: results}
+	for _, r := range results {
+		if r.Status == checkFail {
+			report.Passed = false
+		}
+	}
+
+	var err error
+	if cf.output == output.JSON {
+		err = output.PrintJSON(os.Stdout, report)
+	} else {
+		rows := make([][]string, 0, len(results))
+		for _, r := range results {
+			rows = append(rows, []string{r.Name, strings.ToUpper(r.Status), r.Detail, r.Hint})
+		}
+		err = output.PrintTable(os.Stdout, []string{"CHECK", "STATUS", "DETAIL", "HINT"}, rows)
+	}
+	if err != nil {
+		return err
+	}
+	if !report.Passed {
+		return errors.New("some checks failed")
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const testService = "PidsService"
+
+// serveHealth serves a grpc server on a unix socket in a temp dir, the health service is registered
+// if healthServer is not nil
+func serveHealth(t *testing.T, healthServer *health.Server) string {
+	t.Helper()
+	sockPath := filepath.Join(t.TempDir(), "test.sock")
+	listener, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("listen on %s error: %v", sockPath, err)
+	}
+	srv := grpc.NewServer()
+	if healthServer != nil {
+		healthpb.RegisterHealthServer(srv, healthServer)
+	}
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+	return sockPath
+}
+
+func TestCheckSocketHealth(t *testing.T) {
+	serving := health.NewServer()
+	serving.SetServingStatus(testService, healthpb.HealthCheckResponse_SERVING)
+	notServing := health.NewServer()
+	notServing.SetServingStatus(testService, healthpb.HealthCheckResponse_NOT_SERVING)
+
+	tests := []struct {
+		name       string
+		sockPath   string
+		wantStatus string
+		wantDetail string
+	}{
+		{name: "serving", sockPath: serveHealth(t, serving), wantStatus: checkPass, wantDetail: "is serving"},
+		{name: "not serving", sockPath: serveHealth(t, notServing), wantStatus: checkFail,
+			wantDetail: "is NOT_SERVING"},
+		{name: "no health service", sockPath: serveHealth(t, nil), wantStatus: checkWarn,
+			wantDetail: "has no health service"},
+		{name: "missing socket", sockPath: filepath.Join(t.TempDir(), "missing.sock"), wantStatus: checkFail,
+			wantDetail: "health check on"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := checkSocketHealth("socket", tt.sockPath, testService, "hint")
+			if res.Status != tt.wantStatus || !strings.Contains(res.Detail, tt.wantDetail) {
+				t.Errorf("checkSocketHealth = %+v, want status %s with detail %q", res, tt.wantStatus, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestCheckLibcudaHook(t *testing.T) {
+	dir := t.TempDir()
+	hooked := filepath.Join(dir, "libcuda_direct.so")
+	original := filepath.Join(dir, "libcuda-original.so")
+	link := filepath.Join(dir, "libcuda.so")
+	if err := os.WriteFile(hooked, append([]byte("ELF..."), hookMarker...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(original, []byte("ELF..."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(hooked, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		libPath string
+		want    string
+	}{
+		{name: "hook build through symlink", libPath: link, want: checkPass},
+		{name: "original library", libPath: original, want: checkFail},
+		{name: "missing library", libPath: filepath.Join(dir, "missing.so"), want: checkFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := checkLibcudaHook(&doctorOptions{libPath: tt.libPath}); res.Status != tt.want {
+				t.Errorf("checkLibcudaHook = %+v, want status %s", res, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckVxpuConfigs(t *testing.T) {
+	writeContainer := func(base, pod, container, config string) {
+		dir := filepath.Join(base, pod, container)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, vxpuConfigFileName), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, vxpuIdsConfigFileName), []byte("GPU-0-0\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	valid := t.TempDir()
+	writeContainer(valid, "pod-a", "main", "UsedMem:1024\nUsedCores:30\n")
+	writeContainer(valid, "pod-b", "main", "UsedMem:2048\nUsedCores:0\n")
+	writeContainer(valid, "pod-b", "sidecar", "UsedMem:512\nUsedCores:10\n")
+	// the other files in the container dirs, such as pids.config, are ignored
+	if err := os.WriteFile(filepath.Join(valid, "pod-a", "main", "pids.config"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	broken := t.TempDir()
+	writeContainer(broken, "pod-a", "main", "UsedMem:1G\n")
+
+	tests := []struct {
+		name       string
+		configDir  string
+		wantStatus string
+		wantDetail string
+	}{
+		{name: "valid", configDir: valid, wantStatus: checkPass, wantDetail: "3 container configs decoded"},
+		{name: "empty", configDir: t.TempDir(), wantStatus: checkPass, wantDetail: "0 container configs decoded"},
+		{name: "broken config", configDir: broken, wantStatus: checkFail, wantDetail: "decode"},
+		{name: "missing dir", configDir: filepath.Join(valid, "missing"), wantStatus: checkFail,
+			wantDetail: "read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := checkVxpuConfigs(&doctorOptions{configDir: tt.configDir})
+			if res.Status != tt.wantStatus || !strings.Contains(res.Detail, tt.wantDetail) {
+				t.Errorf("checkVxpuConfigs = %+v, want status %s with detail %q", res, tt.wantStatus, tt.wantDetail)
+			}
+		})
+	}
+}
@@ -7,12 +7,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
@@ -22,6 +24,8 @@ import (
 	"huawei.com/vxpu-device-plugin/pkg/plugin"
 	"huawei.com/vxpu-device-plugin/pkg/plugin/config"
 	"huawei.com/vxpu-device-plugin/pkg/plugin/xpu"
+	"huawei.com/vxpu-device-plugin/pkg/supervisor"
+	"huawei.com/vxpu-device-plugin/pkg/violation"
 	"huawei.com/vxpu-device-plugin/watchers"
 )
 
@@ -29,20 +33,38 @@ const (
 	xpuSockPath           = "xpu.sock"                       // XPU 设备插件的 Unix Socket 文件名
 	defaultDeviceSplitNum = 2                                // 默认设备拆分数量
 	defaultLogDir         = "/var/log/xpu/xpu-device-plugin" // 默认日志目录
+	defaultHistoryRetain  = time.Hour                        // 默认使用率历史保留时长
+	defaultSamplePeriod   = 30 * time.Second                 // 默认使用率采样间隔
+	defaultViolationWait  = 5 * time.Minute                  // 默认超限持续时长
+	defaultCoreTolerance  = 10                               // 默认算力超限容忍百分比
+	registerMinBackoff    = 5 * time.Second                  // 设备注册失败后的最小重启间隔
+	registerMaxBackoff    = 5 * time.Minute                  // 设备注册失败后的最大重启间隔
 )
 
 var (
 	resourceName string // 资源名称，通过命令行参数设置
 )
 
-func events(watcher *fsnotify.Watcher, sigs chan os.Signal, pluginInst *plugin.DevicePlugin) bool {
+// notify 非阻塞地通知重启，已有未处理的重启通知时直接丢弃
+func notify(ch chan<- struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func events(ctx context.Context, cancel context.CancelFunc, watcher *fsnotify.Watcher, sigs chan os.Signal,
+	restart chan<- struct{}) {
 	for {
 		select {
+		case <-ctx.Done():
+			return
+
 		case event := <-watcher.Events:
 			// 监听 kubelet socket 创建事件，当 kubelet 重启时会重新创建 socket
 			if event.Name == v1beta1.KubeletSocket && event.Op&fsnotify.Create == fsnotify.Create {
 				log.Infof("inotify: %s created, restarting.", v1beta1.KubeletSocket)
-				return true // 返回 true 触发插件重启，以重新连接 kubelet
+				notify(restart) // 触发插件重启，以重新连接 kubelet
 			}
 
 		case err := <-watcher.Errors:
@@ -55,12 +77,12 @@ func events(watcher *fsnotify.Watcher, sigs chan os.Signal, pluginInst *plugin.D
 			case syscall.SIGHUP:
 				// SIGHUP 信号：优雅重启，重新加载配置
 				log.Infoln("Received SIGHUP, restarting.")
-				return true // 返回 true 触发插件重启
+				notify(restart) // 触发插件重启
 			default:
-				// 其他信号（SIGINT、SIGTERM、SIGQUIT）：优雅关闭
+				// 其他信号（SIGINT、SIGTERM、SIGQUIT）：按启动的逆序优雅关闭所有组件
 				log.Infof("Received signal %v, shutting down.", s)
-				pluginInst.Stop() // 停止设备插件服务
-				return false      // 返回 false 退出主循环
+				cancel()
+				return
 			}
 		}
 	}
@@ -72,7 +94,10 @@ func start() error {
 
 	// 初始化日志系统，日志文件保存在配置的日志目录
 	logFileName := path.Join(config.LogDir, "xpu-device-plugin.log")
-	log.InitLogging(logFileName)
+	log.SetComponent("xpu-device-plugin")
+	if err := log.InitLogging(logFileName); err != nil {
+		return fmt.Errorf("failed to init logging: %v", err)
+	}
 
 	// 初始化 XPU 设备发现模块，扫描系统中的 GPU/NPU 设备
 	if err := xpu.Init(); err != nil {
@@ -95,44 +120,65 @@ func start() error {
 	log.Infof("Starting OS watcher.")
 	sigs := watchers.NewOSWatcher(syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 
-	// 创建并启动设备缓存，用于缓存设备信息和状态
+	// 创建并启动设备缓存，用于缓存设备信息和状态，由 supervisor 在关闭时停止
 	cache := plugin.NewDeviceCache()
 	cache.Start()
-	defer cache.Stop() // 确保停止设备缓存
+	// 检查是否有可用设备，如果没有设备则无法提供服务
+	if len(cache.GetCache()) == 0 {
+		cache.Stop()
+		return fmt.Errorf("no devices to serve for current node")
+	}
 
-	// 创建并启动设备注册器，用于向 Kubernetes API Server 注册设备资源
+	// 设备注册器，用于向 Kubernetes API Server 注册设备资源
 	register := plugin.NewDeviceRegister(cache)
-	register.Start()
 
-	// 启动 PIDs 服务，提供 gRPC 服务供客户端查询进程 ID 配置
+	// PIDs 服务相关组件，提供 gRPC 服务供客户端查询进程 ID 配置
 	// 这个服务会被 client/client.go 中的客户端工具调用
-	service.Start()
-
-	pluginInst := plugin.NewDevicePlugin(resourceName, cache, filepath.Clean(filepath.Join(v1beta1.DevicePluginPath, xpuSockPath)))
-
-	// 检查是否有可用设备，如果没有设备则无法提供服务
-	if len(pluginInst.Devices()) == 0 {
-		return fmt.Errorf("no devices to serve for current node")
+	serviceComponents, err := service.Components()
+	if err != nil {
+		return fmt.Errorf("failed to create pids service: %v", err)
 	}
 
-	// 主循环：启动插件并处理事件
-	// 当 events 函数返回 false 时（收到关闭信号），退出循环
-	for {
-		// 启动设备插件，向 kubelet 注册设备资源
-		if err := pluginInst.Start(); err != nil {
-			log.Errorf("Start vxpu device plugin failed!")
-			return err
-		}
+	pluginInst := plugin.NewDevicePlugin(resourceName, cache, filepath.Clean(filepath.Join(v1beta1.DevicePluginPath, xpuSockPath)))
 
-		// 等待并处理事件（文件系统事件或系统信号）
-		// 如果返回 true，表示需要重启插件（kubelet 重启或收到 SIGHUP）
-		// 如果返回 false，表示需要关闭程序（收到关闭信号）
-		if restart := events(watcher, sigs, pluginInst); !restart {
-			break // 退出主循环，程序正常关闭
-		}
-		// 如果需要重启，循环会继续，插件会重新启动
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// 处理文件系统事件和系统信号，kubelet 重启或收到 SIGHUP 时重启插件，收到关闭信号时取消 ctx
+	restart := make(chan struct{}, 1)
+	go events(ctx, cancel, watcher, sigs, restart)
+	// 收到 SIGUSR1 时切换到 debug 日志级别，收到 SIGUSR2 时恢复 log-level 设置的级别
+	go log.WatchLevelSignals(ctx)
+
+	// 由 supervisor 按顺序逐个启动各组件，设置 WaitReady 的组件就绪后才启动下一个
+	// 按重启策略重启失败的组件，关闭时按启动的逆序停止
+	sv := supervisor.New()
+	sv.Subscribe(func(status supervisor.Status) {
+		log.Infof("component %s is %s", status.Name, status.State)
+	})
+	// pids.sock 上的 gRPC 健康检查服务跟随各组件的状态
+	service.ReportHealth(sv)
+	sv.Add(supervisor.Spec{
+		Component: supervisor.Func("device-cache", cache.Run),
+		Policy:    supervisor.RestartNever,
+		Critical:  true,
+	})
+	sv.Add(supervisor.Spec{
+		Component:  supervisor.Func("device-register", register.Run),
+		Policy:     supervisor.RestartOnFailure,
+		MinBackoff: registerMinBackoff,
+		MaxBackoff: registerMaxBackoff,
+	})
+	for _, spec := range serviceComponents {
+		sv.Add(spec)
 	}
-	return nil
+	sv.Add(supervisor.Spec{
+		Component: supervisor.Func("device-plugin", func(ctx context.Context) error {
+			return pluginInst.Run(ctx, restart)
+		}),
+		Policy:    supervisor.RestartOnFailure,
+		WaitReady: true,
+	})
+	return sv.Run(ctx)
 }
 
 func main() {
@@ -147,6 +193,24 @@ func main() {
 	flag.StringVar(&resourceName, "resource-name", xpu.VxpuNumber, "resource name")
 	// GPU 类型配置文件：GPU 类型配置文件的绝对路径
 	flag.StringVar(&config.GPUTypeConfig, "gpu-type-config", "", "the abs path map of gpu type config file")
+	// 使用率历史保留时长：内存中保留 GPU/vGPU 使用率历史的时长，为 0 时关闭历史记录
+	flag.DurationVar(&config.HistoryRetention, "history-retention", defaultHistoryRetain,
+		"retention of the in-memory usage history, 0 disables the history")
+	// 使用率采样间隔：使用率历史与超限检查共用同一个采样器
+	flag.DurationVar(&config.SampleInterval, "sample-interval", defaultSamplePeriod,
+		"interval of sampling the xpu and vxpu usage for the usage history and the limit check")
+	// gRPC 反射服务：开启后可通过 grpcurl 等工具调试 xpu.sock 和 pids.sock
+	flag.BoolVar(&config.EnableReflection, "grpc-reflection", false,
+		"register the grpc reflection service on the plugin and pids sockets")
+	// 超限处理动作：vGPU 持续超出显存或算力限制时的处理动作，为空时关闭超限检查
+	flag.StringVar(&config.ViolationActions, "violation-actions", violation.ActionMetric,
+		"comma separated actions taken when a vxpu exceeds its limit: event, annotation, metric, sigterm")
+	// 超限持续时长：vGPU 持续超限多久后执行处理动作
+	flag.DurationVar(&config.ViolationWindow, "violation-window", defaultViolationWait,
+		"how long a vxpu exceeds its limit before the actions are taken")
+	// 算力超限容忍百分比：算力使用率超过限制该百分比后才视为超限
+	flag.Int64Var(&config.ViolationCoreTolerance, "violation-core-tolerance", defaultCoreTolerance,
+		"percentage of core utilization allowed above the core limit")
 
 	// 解析命令行参数
 	flag.Parse()
@@ -0,0 +1,232 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei.com/vxpu-device-plugin/pkg/graph"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/types"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/util"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/xpu"
+)
+
+const (
+	handshakePrefix     = "Reported_"
+	handshakeTimeFormat = "2006.01.02 15:04:05"
+	coresPerGpu         = 100
+)
+
+// allocation one vgpu assigned to a container
+type allocation struct {
+	Namespace string
+	Pod       string
+	Container string
+	Node      string
+	GpuId     string
+	Vid       int32
+	Memory    int32
+	Cores     int32
+	Phase     string
+}
+
+// gpuInventory capacity and allocations of one physical gpu
+type gpuInventory struct {
+	Node        string
+	Index       int32
+	Id          string
+	Type        string
+	Health      bool
+	Count       uint32
+	MemoryTotal uint64
+	// VgpusUsed, MemoryUsed and CoresUsed are summed from the pods assigned to the gpu
+	VgpusUsed   int32
+	MemoryUsed  int32
+	CoresUsed   int32
+	Allocations []allocation
+	// Reported is the usage reported in the node-vgpu-used annotation, nil if absent
+	Reported *types.DeviceUsed `json:",omitempty"`
+}
+
+// nodeInventory vgpu state of one node decoded from its annotations
+type nodeInventory struct {
+	Name      string
+	Handshake string
+	Gpus      []*gpuInventory
+	Topology  graph.TopologyGraph
+}
+
+// stuckPod pod whose vgpu binding has not completed
+type stuckPod struct {
+	Namespace string
+	Pod       string
+	Node      string
+	Phase     string
+	BindTime  string
+	Age       string
+}
+
+// inventory cluster-wide vgpu state
+type inventory struct {
+	Nodes []*nodeInventory
+	Stuck []stuckPod
+}
+
+func (n *nodeInventory) gpu(id string) *gpuInventory {
+	for _, g := range n.Gpus {
+		if g.Id == id {
+			return g
+		}
+	}
+	return nil
+}
+
+func newNodeInventory(node *v1.Node) *nodeInventory {
+	annos := node.Annotations
+	ni := &nodeInventory{Name: node.Name, Handshake: strings.TrimPrefix(annos[xpu.NodeVXPUHandshake], handshakePrefix)}
+	used := util.DecodeNodeDevicesUsed(annos[xpu.NodeVXPUUsed])
+	for id, dev := range util.DecodeNodeDevices(annos[xpu.NodeVXPURegister]) {
+		ni.Gpus = append(ni.Gpus, &gpuInventory{
+			Node:        node.Name,
+			Index:       dev.Index,
+			Id:          id,
+			Type:        dev.Type,
+			Health:      dev.Health,
+			Count:       dev.Count,
+			MemoryTotal: dev.MemoryTotal,
+			Reported:    used[id],
+		})
+	}
+	sort.Slice(ni.Gpus, func(i, j int) bool { return ni.Gpus[i].Index < ni.Gpus[j].Index })
+	if topology, ok := annos[xpu.NodeXpuTopology]; ok {
+		if g, err := graph.Deserialize(topology); err == nil {
+			ni.Topology = g
+		}
+	}
+	return ni
+}
+
+// podNode the node a vgpu pod is assigned to by the scheduler
+func podNode(pod *v1.Pod) string {
+	if node, ok := pod.Annotations[xpu.AssignedNode]; ok {
+		return node
+	}
+	return pod.Spec.NodeName
+}
+
+// podAllocations decode the vgpus assigned to the containers of a pod
+func podAllocations(pod *v1.Pod) []allocation {
+	var allocations []allocation
+	for vxpuIdx, cd := range util.DecodePodDevices(pod.Annotations[xpu.AssignedIDs]) {
+		container := fmt.Sprintf("#%d", vxpuIdx)
+		if idx := util.GetContainerIdxByVxpuIdx(pod, vxpuIdx); idx != -1 {
+			container = pod.Spec.Containers[idx].Name
+		}
+		for _, d := range cd {
+			allocations = append(allocations, allocation{
+				Namespace: pod.Namespace,
+				Pod:       pod.Name,
+				Container: container,
+				Node:      podNode(pod),
+				GpuId:     d.UUID,
+				Vid:       d.Vid,
+				Memory:    d.Usedmem,
+				Cores:     d.Usedcores,
+				Phase:     pod.Annotations[types.DeviceBindPhase],
+			})
+		}
+	}
+	return allocations
+}
+
+// newStuckPod returns the pod and whether it has been binding for stuckAfter, the age is counted from
+// the bind time or from the creation if the bind time is absent
+func newStuckPod(pod *v1.Pod, now time.Time, stuckAfter time.Duration) (stuckPod, bool) {
+	sp := stuckPod{
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		Node:      podNode(pod),
+		Phase:     pod.Annotations[types.DeviceBindPhase],
+	}
+	since := pod.CreationTimestamp.Time
+	if bindTime, ok := util.GetBindTime(*pod); ok {
+		since = time.Unix(int64(bindTime), 0)
+		sp.BindTime = since.Format(time.RFC3339)
+	}
+	if since.IsZero() {
+		return sp, stuckAfter <= 0
+	}
+	age := now.Sub(since)
+	sp.Age = age.Truncate(time.Second).String()
+	return sp, age >= stuckAfter
+}
+
+// loadInventory decode the vgpu state of the nodes, all nodes if nodeName is empty,
+// and of the pods in the namespace, all namespaces if it is empty
+func loadInventory(nodeName, namespace string, stuckAfter time.Duration) (*inventory, error) {
+	opts := metav1.ListOptions{}
+	if nodeName != "" {
+		opts.FieldSelector = "metadata.name=" + nodeName
+	}
+	nodeList, err := util.ListNodes(opts)
+	if err != nil {
+		return nil, fmt.Errorf("list nodes error: %v", err)
+	}
+	podList, err := util.ListNamespacedPods(namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list pods error: %v", err)
+	}
+	return buildInventory(nodeList.Items, podList.Items, time.Now(), stuckAfter), nil
+}
+
+// buildInventory decode the vgpu state of the nodes and the pods assigned to them
+func buildInventory(nodeItems []v1.Node, podItems []v1.Pod, now time.Time, stuckAfter time.Duration) *inventory {
+	inv := &inventory{}
+	nodes := make(map[string]*nodeInventory)
+	for i := range nodeItems {
+		node := &nodeItems[i]
+		if _, ok := node.Annotations[xpu.NodeVXPURegister]; !ok {
+			continue
+		}
+		ni := newNodeInventory(node)
+		nodes[ni.Name] = ni
+		inv.Nodes = append(inv.Nodes, ni)
+	}
+	sort.Slice(inv.Nodes, func(i, j int) bool { return inv.Nodes[i].Name < inv.Nodes[j].Name })
+
+	for i := range podItems {
+		pod := &podItems[i]
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		ni, ok := nodes[podNode(pod)]
+		if !ok {
+			continue
+		}
+		phase := pod.Annotations[types.DeviceBindPhase]
+		if phase == types.DeviceBindAllocating || phase == types.DeviceBindFailed {
+			if sp, stuck := newStuckPod(pod, now, stuckAfter); stuck {
+				inv.Stuck = append(inv.Stuck, sp)
+			}
+		}
+		for _, a := range podAllocations(pod) {
+			g := ni.gpu(a.GpuId)
+			if g == nil {
+				continue
+			}
+			g.VgpusUsed++
+			g.MemoryUsed += a.Memory
+			g.CoresUsed += a.Cores
+			g.Allocations = append(g.Allocations, a)
+		}
+	}
+	return inv
+}
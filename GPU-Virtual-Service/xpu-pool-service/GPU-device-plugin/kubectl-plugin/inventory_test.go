@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei.com/vxpu-device-plugin/pkg/plugin/types"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/xpu"
+)
+
+const testNode = "node-1"
+
+var testNow = time.Unix(10000, 0)
+
+func newTestNode(name string) v1.Node {
+	return v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{
+		xpu.NodeVXPURegister:  "1,GPU-1,4,16384,A100,false,0:0,GPU-0,4,16384,A100,true,0:",
+		xpu.NodeVXPUUsed:      "0,GPU-0,1,4096,30:",
+		xpu.NodeVXPUHandshake: handshakePrefix + "2025.01.02 03:04:05",
+	}}}
+}
+
+// newTestPod returns a pod on the test node whose containers request vgpus, assigned the devices in ids
+func newTestPod(name, ids string, containers ...string) v1.Pod {
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: map[string]string{
+			xpu.AssignedNode:      testNode,
+			xpu.AssignedIDs:       ids,
+			types.DeviceBindPhase: types.DeviceBindSuccess,
+		}},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+	for _, c := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: c, Resources: v1.ResourceRequirements{
+			Limits: v1.ResourceList{xpu.VxpuNumber: resource.MustParse("1")}}})
+	}
+	return pod
+}
+
+// newBindingPod returns a pod in the bind phase, bound age ago
+func newBindingPod(name, phase string, age time.Duration) v1.Pod {
+	pod := newTestPod(name, "")
+	pod.Annotations[types.DeviceBindPhase] = phase
+	pod.Annotations[types.DeviceBindTime] = strconv.FormatInt(testNow.Add(-age).Unix(), 10)
+	return pod
+}
+
+func TestNewNodeInventory(t *testing.T) {
+	node := newTestNode(testNode)
+	ni := newNodeInventory(&node)
+	if ni.Name != testNode || ni.Handshake != "2025.01.02 03:04:05" {
+		t.Errorf("node = %s, handshake = %q", ni.Name, ni.Handshake)
+	}
+	if len(ni.Gpus) != 2 || ni.Gpus[0].Id != "GPU-0" || ni.Gpus[1].Id != "GPU-1" {
+		t.Fatalf("gpus = %+v, want GPU-0 and GPU-1 sorted by index", ni.Gpus)
+	}
+	want := &gpuInventory{Node: testNode, Index: 0, Id: "GPU-0", Type: "A100", Health: true, Count: 4,
+		MemoryTotal: 16384, Reported: &types.DeviceUsed{Id: "GPU-0", Count: 1, Usedmem: 4096, Usedcores: 30}}
+	if !reflect.DeepEqual(ni.Gpus[0], want) {
+		t.Errorf("GPU-0 = %+v, want %+v", ni.Gpus[0], want)
+	}
+	if ni.Gpus[1].Health || ni.Gpus[1].Reported != nil {
+		t.Errorf("GPU-1 = %+v, want unhealthy without reported usage", ni.Gpus[1])
+	}
+	if ni.gpu("GPU-1") != ni.Gpus[1] || ni.gpu("GPU-9") != nil {
+		t.Error("gpu should look up the gpus by uuid")
+	}
+}
+
+func TestPodAllocations(t *testing.T) {
+	pod := newTestPod("train", "0,GPU-0,A100,4096,30,1:;1,GPU-1,A100,2048,20,2:0,GPU-0,A100,1024,10,3:",
+		"main", "sidecar")
+	pod.Spec.Containers = append([]v1.Container{{Name: "init"}}, pod.Spec.Containers...)
+	got := podAllocations(&pod)
+	want := []allocation{
+		{Namespace: "default", Pod: "train", Container: "main", Node: testNode, GpuId: "GPU-0", Vid: 1,
+			Memory: 4096, Cores: 30, Phase: types.DeviceBindSuccess},
+		{Namespace: "default", Pod: "train", Container: "sidecar", Node: testNode, GpuId: "GPU-1", Vid: 2,
+			Memory: 2048, Cores: 20, Phase: types.DeviceBindSuccess},
+		{Namespace: "default", Pod: "train", Container: "sidecar", Node: testNode, GpuId: "GPU-0", Vid: 3,
+			Memory: 1024, Cores: 10, Phase: types.DeviceBindSuccess},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("podAllocations = %+v, want %+v", got, want)
+	}
+
+	// the devices of a vgpu request without a matching container are kept
+	unmatched := newTestPod("orphan", "0,GPU-0,A100,1024,10,0:")
+	if got := podAllocations(&unmatched); len(got) != 1 || got[0].Container != "#0" {
+		t.Errorf("podAllocations = %+v, want one allocation of container #0", got)
+	}
+}
+
+func TestBuildInventory(t *testing.T) {
+	nodes := []v1.Node{newTestNode("node-2"), newTestNode(testNode), {ObjectMeta: metav1.ObjectMeta{Name: "cpu"}}}
+	completed := newTestPod("completed", "0,GPU-0,A100,1024,10,0:", "main")
+	completed.Status.Phase = v1.PodSucceeded
+	other := newTestPod("other-node", "0,GPU-0,A100,1024,10,0:", "main")
+	other.Annotations[xpu.AssignedNode] = "cpu"
+	pods := []v1.Pod{
+		newTestPod("a", "0,GPU-0,A100,4096,30,0:", "main"),
+		newTestPod("b", "0,GPU-0,A100,2048,20,1:", "main"),
+		newTestPod("c", "0,GPU-9,A100,2048,20,0:", "main"),
+		completed,
+		other,
+		newBindingPod("allocating-new", types.DeviceBindAllocating, time.Minute),
+		newBindingPod("allocating-old", types.DeviceBindAllocating, 10*time.Minute),
+		newBindingPod("failed-old", types.DeviceBindFailed, 6*time.Minute),
+	}
+	inv := buildInventory(nodes, pods, testNow, 5*time.Minute)
+
+	if len(inv.Nodes) != 2 || inv.Nodes[0].Name != testNode || inv.Nodes[1].Name != "node-2" {
+		t.Fatalf("nodes = %+v, want the vgpu nodes sorted by name", inv.Nodes)
+	}
+	g := inv.Nodes[0].gpu("GPU-0")
+	if g.VgpusUsed != 2 || g.MemoryUsed != 6144 || g.CoresUsed != 50 || len(g.Allocations) != 2 {
+		t.Errorf("GPU-0 = %+v, want the allocations of pods a and b", g)
+	}
+	if g := inv.Nodes[1].gpu("GPU-0"); g.VgpusUsed != 0 {
+		t.Errorf("GPU-0 of node-2 = %+v, want no allocation", g)
+	}
+
+	stuck := make([]string, 0, len(inv.Stuck))
+	for _, sp := range inv.Stuck {
+		stuck = append(stuck, sp.Pod)
+	}
+	if want := []string{"allocating-old", "failed-old"}; !reflect.DeepEqual(stuck, want) {
+		t.Errorf("stuck = %v, want %v", stuck, want)
+	}
+	if inv.Stuck[0].Age != "10m0s" || inv.Stuck[1].Phase != types.DeviceBindFailed {
+		t.Errorf("stuck = %+v", inv.Stuck)
+	}
+}
+
+func TestNewStuckPod(t *testing.T) {
+	created := newTestPod("created", "")
+	created.Annotations[types.DeviceBindPhase] = types.DeviceBindAllocating
+	created.CreationTimestamp = metav1.NewTime(testNow.Add(-time.Hour))
+	unknown := newTestPod("unknown", "")
+	tests := []struct {
+		name       string
+		pod        v1.Pod
+		stuckAfter time.Duration
+		wantStuck  bool
+		wantAge    string
+	}{
+		{name: "bound recently", pod: newBindingPod("p", types.DeviceBindAllocating, time.Minute),
+			stuckAfter: 5 * time.Minute, wantAge: "1m0s"},
+		{name: "bound at the threshold", pod: newBindingPod("p", types.DeviceBindAllocating, 5*time.Minute),
+			stuckAfter: 5 * time.Minute, wantStuck: true, wantAge: "5m0s"},
+		{name: "no threshold", pod: newBindingPod("p", types.DeviceBindFailed, 0), wantStuck: true,
+			wantAge: "0s"},
+		{name: "age from the creation", pod: created, stuckAfter: 5 * time.Minute, wantStuck: true,
+			wantAge: "1h0m0s"},
+		{name: "unknown age", pod: unknown, stuckAfter: 5 * time.Minute},
+		{name: "unknown age without threshold", pod: unknown, wantStuck: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp, stuck := newStuckPod(&tt.pod, testNow, tt.stuckAfter)
+			if stuck != tt.wantStuck || sp.Age != tt.wantAge {
+				t.Errorf("newStuckPod = %+v, %v, want age %q and stuck %v", sp, stuck, tt.wantAge, tt.wantStuck)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package main implements the kubectl-vgpu plugin
+// 解析节点与 Pod 上的 vGPU 注解，展示集群范围内的 vGPU 容量、分配情况与拓扑
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"huawei.com/vxpu-device-plugin/pkg/lock"
+	"huawei.com/vxpu-device-plugin/pkg/output"
+)
+
+// defaultStuckAfter how long a pod binds before it is listed as stuck, the scheduler retries for
+// a while after a failed bind
+const defaultStuckAfter = 5 * time.Minute
+
+type command struct {
+	name    string
+	summary string
+	run     func(inv *inventory, opts *options) error
+}
+
+type options struct {
+	output     string
+	node       string
+	gpu        string
+	namespace  string
+	kubeconfig string
+	context    string
+	stuckAfter time.Duration
+}
+
+var commands = []command{
+	{name: "nodes", summary: "show vgpu capacity against allocation per node", run: runNodes},
+	{name: "gpus", summary: "show vgpu capacity against allocation per physical gpu", run: runGpus},
+	{name: "pods", summary: "list the pods on each physical gpu", run: runPods},
+	{name: "stuck", summary: "list the pods stuck in allocating or failed bind phase for longer than --stuck-after", run: runStuck},
+	{name: "topology", summary: "render the gpu topology matrix of the nodes", run: runTopology},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage:\n  kubectl vgpu <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nFlags:\n")
+	newFlagSet("", &options{}).PrintDefaults()
+}
+
+func newFlagSet(name string, opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&opts.output, "o", output.Table, "output format, table or json")
+	fs.StringVar(&opts.node, "node", "", "only show the given node")
+	fs.StringVar(&opts.gpu, "gpu", "", "only show the gpu with the given uuid")
+	fs.StringVar(&opts.namespace, "n", "", "only show the pods in the namespace, shorthand of --namespace")
+	fs.StringVar(&opts.namespace, "namespace", "", "only show the pods in the namespace")
+	fs.StringVar(&opts.kubeconfig, "kubeconfig", "", "path of the kubeconfig file")
+	fs.StringVar(&opts.context, "context", "", "name of the kubeconfig context to use")
+	fs.DurationVar(&opts.stuckAfter, "stuck-after", defaultStuckAfter,
+		"only list the pods which have been binding for longer than the duration")
+	return fs
+}
+
+func run(cmd command, args []string) error {
+	opts := &options{}
+	fs := newFlagSet(cmd.name, opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := output.CheckFormat(opts.output); err != nil {
+		return err
+	}
+	if opts.kubeconfig != "" || opts.context != "" {
+		if err := lock.NewClientForContext(opts.kubeconfig, opts.context); err != nil {
+			return fmt.Errorf("create kubernetes client error: %v", err)
+		}
+	}
+	if lock.GetClient() == nil {
+		return fmt.Errorf("no kubernetes client, check KUBECONFIG or --kubeconfig")
+	}
+	inv, err := loadInventory(opts.node, opts.namespace, opts.stuckAfter)
+	if err != nil {
+		return err
+	}
+	return cmd.run(inv, opts)
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage()
+		os.Exit(1)
+	}
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+		if err := run(cmd, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+	usage()
+	os.Exit(1)
+}
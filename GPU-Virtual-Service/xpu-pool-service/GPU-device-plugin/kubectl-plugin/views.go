@@ -0,0 +1,189 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"huawei.com/vxpu-device-plugin/pkg/output"
+)
+
+const (
+	healthyText   = "Healthy"
+	unhealthyText = "Unhealthy"
+	noValue       = "-"
+)
+
+// nodeSummary totals of the gpus on a node
+type nodeSummary struct {
+	Name        string
+	Handshake   string
+	Gpus        int
+	Unhealthy   int
+	VgpusTotal  uint32
+	VgpusUsed   int32
+	MemoryTotal uint64
+	MemoryUsed  int32
+	CoresTotal  int
+	CoresUsed   int32
+	Pods        int
+}
+
+func ratio(used, total any) string {
+	return fmt.Sprintf("%v/%v", used, total)
+}
+
+func summarize(n *nodeInventory) nodeSummary {
+	s := nodeSummary{Name: n.Name, Handshake: n.Handshake, Gpus: len(n.Gpus), CoresTotal: len(n.Gpus) * coresPerGpu}
+	pods := make(map[string]struct{})
+	for _, g := range n.Gpus {
+		if !g.Health {
+			s.Unhealthy++
+		}
+		s.VgpusTotal += g.Count
+		s.VgpusUsed += g.VgpusUsed
+		s.MemoryTotal += g.MemoryTotal
+		s.MemoryUsed += g.MemoryUsed
+		s.CoresUsed += g.CoresUsed
+		for _, a := range g.Allocations {
+			pods[a.Namespace+"/"+a.Pod] = struct{}{}
+		}
+	}
+	s.Pods = len(pods)
+	return s
+}
+
+func runNodes(inv *inventory, opts *options) error {
+	summaries := make([]nodeSummary, 0, len(inv.Nodes))
+	for _, n := range inv.Nodes {
+		summaries = append(summaries, summarize(n))
+	}
+	if opts.output == output.JSON {
+		return output.PrintJSON(os.Stdout, summaries)
+	}
+	rows := make([][]string, 0, len(summaries))
+	for _, s := range summaries {
+		rows = append(rows, []string{s.Name, strconv.Itoa(s.Gpus), strconv.Itoa(s.Unhealthy),
+			ratio(s.VgpusUsed, s.VgpusTotal), ratio(s.MemoryUsed, s.MemoryTotal),
+			ratio(s.CoresUsed, s.CoresTotal), strconv.Itoa(s.Pods), s.Handshake})
+	}
+	return output.PrintTable(os.Stdout, []string{"NODE", "GPUS", "UNHEALTHY", "VGPUS", "MEMORY(MiB)", "CORES(%)",
+		"PODS", "REPORTED"}, rows)
+}
+
+// selectGpus the gpus matching the gpu filter
+func selectGpus(inv *inventory, opts *options) []*gpuInventory {
+	var gpus []*gpuInventory
+	for _, n := range inv.Nodes {
+		for _, g := range n.Gpus {
+			if opts.gpu == "" || g.Id == opts.gpu {
+				gpus = append(gpus, g)
+			}
+		}
+	}
+	return gpus
+}
+
+func runGpus(inv *inventory, opts *options) error {
+	gpus := selectGpus(inv, opts)
+	if opts.output == output.JSON {
+		return output.PrintJSON(os.Stdout, gpus)
+	}
+	rows := make([][]string, 0, len(gpus))
+	for _, g := range gpus {
+		health := healthyText
+		if !g.Health {
+			health = unhealthyText
+		}
+		reported := noValue
+		if g.Reported != nil {
+			reported = fmt.Sprintf("%d/%d/%d", g.Reported.Count, g.Reported.Usedmem, g.Reported.Usedcores)
+		}
+		rows = append(rows, []string{g.Node, strconv.Itoa(int(g.Index)), g.Id, g.Type, health,
+			ratio(g.VgpusUsed, g.Count), ratio(g.MemoryUsed, g.MemoryTotal), ratio(g.CoresUsed, coresPerGpu),
+			reported})
+	}
+	return output.PrintTable(os.Stdout, []string{"NODE", "INDEX", "UUID", "TYPE", "HEALTH", "VGPUS", "MEMORY(MiB)",
+		"CORES(%)", "REPORTED(VGPUS/MEM/CORES)"}, rows)
+}
+
+func runPods(inv *inventory, opts *options) error {
+	var allocations []allocation
+	for _, g := range selectGpus(inv, opts) {
+		allocations = append(allocations, g.Allocations...)
+	}
+	if opts.output == output.JSON {
+		return output.PrintJSON(os.Stdout, allocations)
+	}
+	rows := make([][]string, 0, len(allocations))
+	for _, a := range allocations {
+		phase := a.Phase
+		if phase == "" {
+			phase = noValue
+		}
+		rows = append(rows, []string{a.Node, a.GpuId, a.Namespace, a.Pod, a.Container, strconv.Itoa(int(a.Vid)),
+			strconv.Itoa(int(a.Memory)), strconv.Itoa(int(a.Cores)), phase})
+	}
+	return output.PrintTable(os.Stdout, []string{"NODE", "GPU", "NAMESPACE", "POD", "CONTAINER", "VID", "MEMORY(MiB)",
+		"CORES(%)", "PHASE"}, rows)
+}
+
+func runStuck(inv *inventory, opts *options) error {
+	if opts.output == output.JSON {
+		return output.PrintJSON(os.Stdout, inv.Stuck)
+	}
+	rows := make([][]string, 0, len(inv.Stuck))
+	for _, sp := range inv.Stuck {
+		age := sp.Age
+		if age == "" {
+			age = noValue
+		}
+		rows = append(rows, []string{sp.Namespace, sp.Pod, sp.Node, strings.ToUpper(sp.Phase), age})
+	}
+	return output.PrintTable(os.Stdout, []string{"NAMESPACE", "POD", "NODE", "PHASE", "AGE"}, rows)
+}
+
+func runTopology(inv *inventory, opts *options) error {
+	if opts.output == output.JSON {
+		topologies := make(map[string][][]int, len(inv.Nodes))
+		for _, n := range inv.Nodes {
+			topologies[n.Name] = n.Topology
+		}
+		return output.PrintJSON(os.Stdout, topologies)
+	}
+	for i, n := range inv.Nodes {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Node %s:\n", n.Name)
+		if len(n.Topology) == 0 {
+			fmt.Println("  no topology reported")
+			continue
+		}
+		header := []string{""}
+		for j := range n.Topology {
+			header = append(header, "GPU"+strconv.Itoa(j))
+		}
+		rows := make([][]string, 0, len(n.Topology))
+		for j, row := range n.Topology {
+			cells := []string{"GPU" + strconv.Itoa(j)}
+			for k, v := range row {
+				if j == k {
+					cells = append(cells, "X")
+					continue
+				}
+				cells = append(cells, strconv.Itoa(v))
+			}
+			rows = append(rows, cells)
+		}
+		if err := output.PrintTable(os.Stdout, header, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestInventory() *inventory {
+	return &inventory{Nodes: []*nodeInventory{
+		{Name: "node-1", Gpus: []*gpuInventory{
+			{Id: "GPU-0", Health: true, Count: 4, MemoryTotal: 16384, VgpusUsed: 2, MemoryUsed: 6144,
+				CoresUsed: 50, Allocations: []allocation{
+					{Namespace: "default", Pod: "a"}, {Namespace: "default", Pod: "b"}}},
+			{Id: "GPU-1", Count: 4, MemoryTotal: 16384, VgpusUsed: 1, MemoryUsed: 1024, CoresUsed: 10,
+				Allocations: []allocation{{Namespace: "default", Pod: "a"}}},
+		}},
+		{Name: "node-2", Gpus: []*gpuInventory{{Id: "GPU-2", Health: true, Count: 2, MemoryTotal: 8192}}},
+	}}
+}
+
+func TestSummarize(t *testing.T) {
+	inv := newTestInventory()
+	got := summarize(inv.Nodes[0])
+	want := nodeSummary{Name: "node-1", Gpus: 2, Unhealthy: 1, VgpusTotal: 8, VgpusUsed: 3, MemoryTotal: 32768,
+		MemoryUsed: 7168, CoresTotal: 2 * coresPerGpu, CoresUsed: 60, Pods: 2}
+	if got != want {
+		t.Errorf("summarize = %+v, want %+v", got, want)
+	}
+	if got := summarize(&nodeInventory{Name: "empty"}); got != (nodeSummary{Name: "empty"}) {
+		t.Errorf("summarize of a node without gpus = %+v", got)
+	}
+}
+
+func TestSelectGpus(t *testing.T) {
+	tests := []struct {
+		name string
+		gpu  string
+		want []string
+	}{
+		{name: "all", want: []string{"GPU-0", "GPU-1", "GPU-2"}},
+		{name: "one", gpu: "GPU-2", want: []string{"GPU-2"}},
+		{name: "unknown", gpu: "GPU-9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			for _, g := range selectGpus(newTestInventory(), &options{gpu: tt.gpu}) {
+				ids = append(ids, g.Id)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("selectGpus = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
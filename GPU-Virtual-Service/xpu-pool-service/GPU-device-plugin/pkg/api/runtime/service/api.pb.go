@@ -201,6 +201,222 @@ func (x *GetAllVxpuInfoResponse) GetVxpuInfos() string {
 	return ""
 }
 
+type GetContainerProcessesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PodUID        string `protobuf:"bytes,1,opt,name=PodUID,proto3" json:"PodUID,omitempty"`
+	ContainerName string `protobuf:"bytes,2,opt,name=ContainerName,proto3" json:"ContainerName,omitempty"`
+	Period        string `protobuf:"bytes,3,opt,name=Period,proto3" json:"Period,omitempty"`
+}
+
+func (x *GetContainerProcessesRequest) Reset() {
+	*x = GetContainerProcessesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetContainerProcessesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetContainerProcessesRequest) ProtoMessage() {}
+
+func (x *GetContainerProcessesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*GetContainerProcessesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetContainerProcessesRequest) GetPodUID() string {
+	if x != nil {
+		return x.PodUID
+	}
+	return ""
+}
+
+func (x *GetContainerProcessesRequest) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *GetContainerProcessesRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+type GetContainerProcessesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ContainerProcesses string `protobuf:"bytes,1,opt,name=ContainerProcesses,proto3" json:"ContainerProcesses,omitempty"`
+}
+
+func (x *GetContainerProcessesResponse) Reset() {
+	*x = GetContainerProcessesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetContainerProcessesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetContainerProcessesResponse) ProtoMessage() {}
+
+func (x *GetContainerProcessesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*GetContainerProcessesResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetContainerProcessesResponse) GetContainerProcesses() string {
+	if x != nil {
+		return x.ContainerProcesses
+	}
+	return ""
+}
+
+type GetVxpuHistoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StartTime string `protobuf:"bytes,1,opt,name=StartTime,proto3" json:"StartTime,omitempty"`
+	EndTime   string `protobuf:"bytes,2,opt,name=EndTime,proto3" json:"EndTime,omitempty"`
+	Id        string `protobuf:"bytes,3,opt,name=Id,proto3" json:"Id,omitempty"`
+}
+
+func (x *GetVxpuHistoryRequest) Reset() {
+	*x = GetVxpuHistoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetVxpuHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVxpuHistoryRequest) ProtoMessage() {}
+
+func (x *GetVxpuHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*GetVxpuHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetVxpuHistoryRequest) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *GetVxpuHistoryRequest) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *GetVxpuHistoryRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetVxpuHistoryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VxpuHistory string `protobuf:"bytes,1,opt,name=VxpuHistory,proto3" json:"VxpuHistory,omitempty"`
+}
+
+func (x *GetVxpuHistoryResponse) Reset() {
+	*x = GetVxpuHistoryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetVxpuHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVxpuHistoryResponse) ProtoMessage() {}
+
+func (x *GetVxpuHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (*GetVxpuHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetVxpuHistoryResponse) GetVxpuHistory() string {
+	if x != nil {
+		return x.VxpuHistory
+	}
+	return ""
+}
+
 var File_api_proto protoreflect.FileDescriptor
 
 var file_api_proto_rawDesc = []byte{
@@ -217,17 +433,49 @@ var file_api_proto_rawDesc = []byte{
 	0x69, 0x6f, 0x64, 0x22, 0x36, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70,
 	0x75, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a,
 	0x09, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x73, 0x32, 0x82, 0x01, 0x0a, 0x0b,
-	0x50, 0x69, 0x64, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x47,
-	0x65, 0x74, 0x50, 0x69, 0x64, 0x73, 0x12, 0x0f, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x69, 0x64, 0x73,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x69, 0x64,
-	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x0e, 0x47,
-	0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x16, 0x2e,
-	0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78,
-	0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
-	0x42, 0x0c, 0x5a, 0x0a, 0x2e, 0x2f, 0x3b, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x09, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x73, 0x22, 0x74, 0x0a, 0x1c, 0x47,
+	0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x63, 0x65,
+	0x73, 0x73, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x50,
+	0x6f, 0x64, 0x55, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x50, 0x6f, 0x64,
+	0x55, 0x49, 0x44, 0x12, 0x24, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72,
+	0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x43, 0x6f, 0x6e, 0x74,
+	0x61, 0x69, 0x6e, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x50, 0x65, 0x72,
+	0x69, 0x6f, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x50, 0x65, 0x72, 0x69, 0x6f,
+	0x64, 0x22, 0x4f, 0x0a, 0x1d, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65,
+	0x72, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x2e, 0x0a, 0x12, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x50,
+	0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12,
+	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73,
+	0x65, 0x73, 0x22, 0x5f, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x56, 0x78, 0x70, 0x75, 0x48, 0x69, 0x73,
+	0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x53,
+	0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x45, 0x6e, 0x64,
+	0x54, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x45, 0x6e, 0x64, 0x54,
+	0x69, 0x6d, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x49, 0x64, 0x22, 0x3a, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x56, 0x78, 0x70, 0x75, 0x48, 0x69,
+	0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a,
+	0x0b, 0x56, 0x78, 0x70, 0x75, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x56, 0x78, 0x70, 0x75, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x32,
+	0xa1, 0x02, 0x0a, 0x0b, 0x50, 0x69, 0x64, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x2e, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x50, 0x69, 0x64, 0x73, 0x12, 0x0f, 0x2e, 0x47, 0x65, 0x74,
+	0x50, 0x69, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x47, 0x65,
+	0x74, 0x50, 0x69, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
+	0x43, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66,
+	0x6f, 0x12, 0x16, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e,
+	0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x47, 0x65, 0x74, 0x41,
+	0x6c, 0x6c, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x58, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x12, 0x1d, 0x2e,
+	0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x63,
+	0x65, 0x73, 0x73, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x47,
+	0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x63, 0x65,
+	0x73, 0x73, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x43,
+	0x0a, 0x0e, 0x47, 0x65, 0x74, 0x56, 0x78, 0x70, 0x75, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79,
+	0x12, 0x16, 0x2e, 0x47, 0x65, 0x74, 0x56, 0x78, 0x70, 0x75, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72,
+	0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x47, 0x65, 0x74, 0x56, 0x78,
+	0x70, 0x75, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x42, 0x0c, 0x5a, 0x0a, 0x2e, 0x2f, 0x3b, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -242,24 +490,32 @@ func file_api_proto_rawDescGZIP() []byte {
 	return file_api_proto_rawDescData
 }
 
-var file_api_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_api_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_api_proto_goTypes = []any{
-	(*GetPidsRequest)(nil),         // 0: server.GetPidsRequest
-	(*GetPidsResponse)(nil),        // 1: server.GetPidsResponse
-	(*GetAllVxpuInfoRequest)(nil),  // 2: server.GetAllVxpuInfoRequest
-	(*GetAllVxpuInfoResponse)(nil), // 3: server.GetAllVxpuInfoResponse
+	(*GetPidsRequest)(nil),                // 0: GetPidsRequest
+	(*GetPidsResponse)(nil),               // 1: GetPidsResponse
+	(*GetAllVxpuInfoRequest)(nil),         // 2: GetAllVxpuInfoRequest
+	(*GetAllVxpuInfoResponse)(nil),        // 3: GetAllVxpuInfoResponse
+	(*GetContainerProcessesRequest)(nil),  // 4: GetContainerProcessesRequest
+	(*GetContainerProcessesResponse)(nil), // 5: GetContainerProcessesResponse
+	(*GetVxpuHistoryRequest)(nil),         // 6: GetVxpuHistoryRequest
+	(*GetVxpuHistoryResponse)(nil),        // 7: GetVxpuHistoryResponse
 }
 
 var file_api_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	2, // [0:0] is the sub-list for method input_type
-	1, // [0:0] is the sub-list for extension type_name
-	3, // [0:0] is the sub-list for extension extendee
-	2, // [0:0] is the sub-list for field type_name
-	0,
-	0,
-	0,
-	0,
+	0, // 0: PidsService.GetPids:input_type -> GetPidsRequest
+	2, // 1: PidsService.GetAllVxpuInfo:input_type -> GetAllVxpuInfoRequest
+	4, // 2: PidsService.GetContainerProcesses:input_type -> GetContainerProcessesRequest
+	6, // 3: PidsService.GetVxpuHistory:input_type -> GetVxpuHistoryRequest
+	1, // 4: PidsService.GetPids:output_type -> GetPidsResponse
+	3, // 5: PidsService.GetAllVxpuInfo:output_type -> GetAllVxpuInfoResponse
+	5, // 6: PidsService.GetContainerProcesses:output_type -> GetContainerProcessesResponse
+	7, // 7: PidsService.GetVxpuHistory:output_type -> GetVxpuHistoryResponse
+	4, // [4:8] is the sub-list for method output_type
+	0, // [0:4] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_init() }
@@ -282,25 +538,25 @@ func file_api_proto_init() {
 			}
 		}
 		file_api_proto_msgTypes[1].Exporter = func(v any, i int) any {
-			switch v := v.(*GetPidsResponse); i {
+			switch val := v.(*GetPidsResponse); i {
 			case 0:
-				return &v.state
+				return &val.state
 			case 1:
-				return &v.sizeCache
+				return &val.sizeCache
 			case 2:
-				return &v.unknownFields
+				return &val.unknownFields
 			default:
 				return nil
 			}
 		}
 		file_api_proto_msgTypes[2].Exporter = func(v any, i int) any {
-			switch v := v.(*GetAllVxpuInfoRequest); i {
+			switch val := v.(*GetAllVxpuInfoRequest); i {
 			case 0:
-				return &v.state
+				return &val.state
 			case 1:
-				return &v.sizeCache
+				return &val.sizeCache
 			case 2:
-				return &v.unknownFields
+				return &val.unknownFields
 			default:
 				return nil
 			}
@@ -317,6 +573,54 @@ func file_api_proto_init() {
 				return nil
 			}
 		}
+		file_api_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch val := v.(*GetContainerProcessesRequest); i {
+			case 0:
+				return &val.state
+			case 1:
+				return &val.sizeCache
+			case 2:
+				return &val.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch val := v.(*GetContainerProcessesResponse); i {
+			case 0:
+				return &val.state
+			case 1:
+				return &val.sizeCache
+			case 2:
+				return &val.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch val := v.(*GetVxpuHistoryRequest); i {
+			case 0:
+				return &val.state
+			case 1:
+				return &val.sizeCache
+			case 2:
+				return &val.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch val := v.(*GetVxpuHistoryResponse); i {
+			case 0:
+				return &val.state
+			case 1:
+				return &val.sizeCache
+			case 2:
+				return &val.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -324,7 +628,7 @@ func file_api_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -333,7 +637,7 @@ func file_api_proto_init() {
 		MessageInfos:      file_api_proto_msgTypes,
 	}.Build()
 	File_api_proto = out.File
+	file_api_proto_rawDesc = nil
 	file_api_proto_goTypes = nil
 	file_api_proto_depIdxs = nil
-	file_api_proto_rawDesc = nil
 }
@@ -15,13 +15,17 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	PidsService_GetPids_FullMethodName        = "/PidsService/GetPids"
-	PidsService_GetAllVxpuInfo_FullMethodName = "/PidsService/GetAllVxpuInfo"
+	PidsService_GetPids_FullMethodName               = "/PidsService/GetPids"
+	PidsService_GetAllVxpuInfo_FullMethodName        = "/PidsService/GetAllVxpuInfo"
+	PidsService_GetContainerProcesses_FullMethodName = "/PidsService/GetContainerProcesses"
+	PidsService_GetVxpuHistory_FullMethodName        = "/PidsService/GetVxpuHistory"
 )
 
 type PidsServiceClient interface {
 	GetPids(ctx context.Context, in *GetPidsRequest, opts ...grpc.CallOption) (*GetPidsResponse, error)
 	GetAllVxpuInfo(ctx context.Context, in *GetAllVxpuInfoRequest, opts ...grpc.CallOption) (*GetAllVxpuInfoResponse, error)
+	GetContainerProcesses(ctx context.Context, in *GetContainerProcessesRequest, opts ...grpc.CallOption) (*GetContainerProcessesResponse, error)
+	GetVxpuHistory(ctx context.Context, in *GetVxpuHistoryRequest, opts ...grpc.CallOption) (*GetVxpuHistoryResponse, error)
 }
 
 type pidsServiceClient struct {
@@ -50,9 +54,29 @@ func (c *pidsServiceClient) GetAllVxpuInfo(ctx context.Context, in *GetAllVxpuIn
 	return out, nil
 }
 
+func (c *pidsServiceClient) GetContainerProcesses(ctx context.Context, in *GetContainerProcessesRequest, opts ...grpc.CallOption) (*GetContainerProcessesResponse, error) {
+	out := new(GetContainerProcessesResponse)
+	err := c.cc.Invoke(ctx, PidsService_GetContainerProcesses_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pidsServiceClient) GetVxpuHistory(ctx context.Context, in *GetVxpuHistoryRequest, opts ...grpc.CallOption) (*GetVxpuHistoryResponse, error) {
+	out := new(GetVxpuHistoryResponse)
+	err := c.cc.Invoke(ctx, PidsService_GetVxpuHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 type PidsServiceServer interface {
 	GetPids(context.Context, *GetPidsRequest) (*GetPidsResponse, error)
 	GetAllVxpuInfo(context.Context, *GetAllVxpuInfoRequest) (*GetAllVxpuInfoResponse, error)
+	GetContainerProcesses(context.Context, *GetContainerProcessesRequest) (*GetContainerProcessesResponse, error)
+	GetVxpuHistory(context.Context, *GetVxpuHistoryRequest) (*GetVxpuHistoryResponse, error)
 	mustEmbedUnimplementedPidsServiceServer()
 }
 
@@ -62,11 +86,15 @@ type UnimplementedPidsServiceServer struct {
 func (UnimplementedPidsServiceServer) GetPids(context.Context, *GetPidsRequest) (*GetPidsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPids not implemented")
 }
-
 func (UnimplementedPidsServiceServer) GetAllVxpuInfo(context.Context, *GetAllVxpuInfoRequest) (*GetAllVxpuInfoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllVxpuInfo not implemented")
 }
-
+func (UnimplementedPidsServiceServer) GetContainerProcesses(context.Context, *GetContainerProcessesRequest) (*GetContainerProcessesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContainerProcesses not implemented")
+}
+func (UnimplementedPidsServiceServer) GetVxpuHistory(context.Context, *GetVxpuHistoryRequest) (*GetVxpuHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVxpuHistory not implemented")
+}
 func (UnimplementedPidsServiceServer) mustEmbedUnimplementedPidsServiceServer() {}
 
 type UnsafePidsServiceServer interface {
@@ -77,7 +105,7 @@ func RegisterPidsServiceServer(s grpc.ServiceRegistrar, srv PidsServiceServer) {
 	s.RegisterService(&PidsService_ServiceDesc, srv)
 }
 
-func _PidsService_GetPids_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _PidsService_GetPids_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
 	in := new(GetPidsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
@@ -89,13 +117,13 @@ func _PidsService_GetPids_Handler(srv interface{}, ctx context.Context, dec func
 		Server:     srv,
 		FullMethod: PidsService_GetPids_FullMethodName,
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+	handler := func(ctx context.Context, req any) (any, error) {
 		return srv.(PidsServiceServer).GetPids(ctx, req.(*GetPidsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _PidsService_GetAllVxpuInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _PidsService_GetAllVxpuInfo_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
 	in := new(GetAllVxpuInfoRequest)
 	if err := dec(in); err != nil {
 		return nil, err
@@ -107,12 +135,48 @@ func _PidsService_GetAllVxpuInfo_Handler(srv interface{}, ctx context.Context, d
 		Server:     srv,
 		FullMethod: PidsService_GetAllVxpuInfo_FullMethodName,
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+	handler := func(ctx context.Context, req any) (any, error) {
 		return srv.(PidsServiceServer).GetAllVxpuInfo(ctx, req.(*GetAllVxpuInfoRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PidsService_GetContainerProcesses_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetContainerProcessesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PidsServiceServer).GetContainerProcesses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PidsService_GetContainerProcesses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(PidsServiceServer).GetContainerProcesses(ctx, req.(*GetContainerProcessesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PidsService_GetVxpuHistory_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetVxpuHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PidsServiceServer).GetVxpuHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PidsService_GetVxpuHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(PidsServiceServer).GetVxpuHistory(ctx, req.(*GetVxpuHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var PidsService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "PidsService",
 	HandlerType: (*PidsServiceServer)(nil),
@@ -125,6 +189,14 @@ var PidsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAllVxpuInfo",
 			Handler:    _PidsService_GetAllVxpuInfo_Handler,
 		},
+		{
+			MethodName: "GetContainerProcesses",
+			Handler:    _PidsService_GetContainerProcesses_Handler,
+		},
+		{
+			MethodName: "GetVxpuHistory",
+			Handler:    _PidsService_GetVxpuHistory_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api.proto",
@@ -15,17 +15,25 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"huawei.com/vxpu-device-plugin/pkg/history"
 	"huawei.com/vxpu-device-plugin/pkg/log"
 	"huawei.com/vxpu-device-plugin/pkg/plugin/config"
 	"huawei.com/vxpu-device-plugin/pkg/plugin/types"
 	"huawei.com/vxpu-device-plugin/pkg/plugin/util"
 	"huawei.com/vxpu-device-plugin/pkg/plugin/xpu"
+	"huawei.com/vxpu-device-plugin/pkg/supervisor"
+	"huawei.com/vxpu-device-plugin/pkg/violation"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
@@ -38,6 +46,7 @@ const (
 	pidsSockPath                  = "/var/lib/xpu/pids.sock"
 	hostProcDir                   = "/hostproc"
 	procStatus                    = "status"
+	procComm                      = "comm"
 	nsPid                         = "NSpid:"
 	nsPidFieldCount               = 3
 	containerdPodIdPrefix         = "-pod"
@@ -50,6 +59,8 @@ const (
 	containerIdPrefixInDocker     = "docker://"
 	vxpuConfigBaseDir             = "/etc/xpu"
 	pidsConfigFileName            = "pids.config"
+	pidsConfigFieldCount          = 2
+	bytesPerMB                    = 1024 * 1024
 	configFilePerm                = 0644
 	pidsSockPerm                  = 0666
 	podDirCleanInterval           = 60
@@ -58,6 +69,7 @@ const (
 	defaultPeriod                 = 60
 	percentage                    = 100
 	float64BitsSize               = 64
+	pidsServiceComponent          = "pids-service"
 )
 
 // PidsServiceServerImpl implementation of pids service
@@ -112,7 +124,7 @@ func getPidMaps(hostPids []int) string {
 		procStatusPath := filepath.Clean(filepath.Join(hostProcDir, strconv.Itoa(hp), procStatus))
 		pids, err := readStatusFile(procStatusPath)
 		if err != nil {
-			klog.Warning("read proc status error: %v, path: %s", err, procStatusPath)
+			klog.Warningf("read proc status error: %v, path: %s", err, procStatusPath)
 		} else {
 			pidMaps = append(pidMaps, pids)
 		}
@@ -151,7 +163,7 @@ func getContainerName(cgroupPath string) (string, string, error) {
 	podId, containerId, err := parseCgroupPath(cgroupPath)
 	log.Infof("podID: %s, containerId: %s", podId, containerId)
 	if err != nil {
-		klog.Error("parse cgroup path error: %v", err)
+		klog.Errorf("parse cgroup path error: %v", err)
 		return "", "", err
 	}
 	selector := fields.SelectorFromSet(fields.Set{"spec.nodeName": config.NodeName, "status.phase": string(v1.PodRunning)})
@@ -206,6 +218,85 @@ func readPidsConfig(pidsConfigPath string) ([]uint32, error) {
 	return pids, nil
 }
 
+// readPidMapsConfig returns the map from host pid to container pid in pids.config
+func readPidMapsConfig(pidsConfigPath string) (map[uint32]uint32, error) {
+	f, err := os.OpenFile(pidsConfigPath, os.O_RDONLY, configFilePerm)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pidMaps := make(map[uint32]uint32)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		tmp := strings.Fields(scanner.Text())
+		if len(tmp) != pidsConfigFieldCount {
+			continue
+		}
+		hostPid, err := strconv.ParseUint(tmp[0], 10, 32)
+		if err != nil {
+			continue
+		}
+		containerPid, err := strconv.ParseUint(tmp[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		pidMaps[uint32(hostPid)] = uint32(containerPid)
+	}
+	return pidMaps, nil
+}
+
+// readVxpuIdsConfig returns the xpu uuids of the vxpus in vgpu-ids.config
+func readVxpuIdsConfig(vxpuIdsConfigPath string) ([]string, error) {
+	f, err := os.OpenFile(vxpuIdsConfigPath, os.O_RDONLY, configFilePerm)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var xpuIds []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// each line is formatted as "<xpu uuid>-<vid>"
+		line := strings.TrimSpace(scanner.Text())
+		idx := strings.LastIndex(line, "-")
+		if idx <= 0 {
+			continue
+		}
+		xpuIds = append(xpuIds, line[:idx])
+	}
+	return xpuIds, nil
+}
+
+// containerConfigDir returns the config dir of the container, podUID and containerName must be plain
+// names so that the dir never escapes vxpuConfigBaseDir
+func containerConfigDir(podUID, containerName string) (string, error) {
+	for _, name := range []string{podUID, containerName} {
+		if name == "" || name == "." || name == ".." || strings.ContainsRune(name, os.PathSeparator) {
+			return "", fmt.Errorf("invalid pod uid %s or container name %s", podUID, containerName)
+		}
+	}
+	return filepath.Join(vxpuConfigBaseDir, podUID, containerName), nil
+}
+
+func readProcComm(hostPid uint32) string {
+	commPath := filepath.Clean(filepath.Join(hostProcDir, strconv.FormatUint(uint64(hostPid), 10), procComm))
+	comm, err := os.ReadFile(commPath)
+	if err != nil {
+		log.Warningf("read proc comm error: %v, path: %s", err, commPath)
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
+
+func parsePeriod(p string) int {
+	period, err := strconv.Atoi(p)
+	if err != nil || period < minPeriod || period > maxPeriod {
+		period = defaultPeriod
+	}
+	return period
+}
+
 func writePidsConfig(pidsConfigPath, pidMaps string) error {
 	f, err := os.OpenFile(pidsConfigPath, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, configFilePerm)
 	if err != nil {
@@ -246,6 +337,19 @@ type void struct{}
 
 var val void
 
+// usageRecorder records the usage history, nil if the history is disabled
+var usageRecorder *history.Recorder
+
+// healthServer reports the serving status of the pids service and the supervised components
+var healthServer = health.NewServer()
+
+// healthMutex serializes the serving status updates so that a stale snapshot of the component
+// statuses never overwrites a newer one
+var healthMutex sync.Mutex
+
+// limitDetector detects the vxpus exceeding their limits, nil if the check is disabled
+var limitDetector *violation.Detector
+
 func cleanDestroyedPodDir() error {
 	podDirNames, err := getPodDirNames()
 	if err != nil {
@@ -351,13 +455,7 @@ func setVxpuDevices(vxpuDevices types.VxpuDevices,
 	return xpuDevices
 }
 
-// GetAllVgpuInfo get all vgpu info of the node
-func (PidsServiceServerImpl) GetAllVxpuInfo(ctx context.Context, req *GetAllVxpuInfoRequest) (*GetAllVxpuInfoResponse, error) {
-	period, err := strconv.Atoi(req.Period)
-	if err != nil || period < minPeriod || period > maxPeriod {
-		period = defaultPeriod
-	}
-
+func getAllVxpuInfo(period int) (map[string]*types.XPUDevice, error) {
 	// xpuDevices: map[uuid]xpuDevice
 	xpuDevices, err := util.GetXPUs()
 	if err != nil {
@@ -382,11 +480,79 @@ func (PidsServiceServerImpl) GetAllVxpuInfo(ctx context.Context, req *GetAllVxpu
 		}
 		// XpuUtilization = deviceUsageInfo.CoreUtil
 		v.XpuUtilization = float64(deviceUsageInfo.CoreUtil)
+		v.CoreUtilizationUnsupported = deviceUsageInfo.CoreUtilUnsupported
 		v.PowerUsage = deviceUsageInfo.PowerUsage
 		v.Temperature = deviceUsageInfo.Temperature
 		uidToProcessMap[v.Id] = processMap
 	}
-	xpuDevices = setVxpuDevices(vxpuDevices, xpuDevices, uidToProcessMap, pSet)
+	return setVxpuDevices(vxpuDevices, xpuDevices, uidToProcessMap, pSet), nil
+}
+
+func setLimitExceeded(xpuDevices map[string]*types.XPUDevice) {
+	if limitDetector == nil || !limitDetector.MetricEnabled() {
+		return
+	}
+	for _, device := range xpuDevices {
+		for i := range device.VxpuDeviceList {
+			v := &device.VxpuDeviceList[i]
+			v.MemoryLimitExceeded = limitDetector.Exceeded(*v, violation.LimitMemory)
+			v.CoreLimitExceeded = limitDetector.Exceeded(*v, violation.LimitCore)
+		}
+	}
+}
+
+// getVxpuProcessPids returns the host pids of the container which are using the xpu of the vxpu
+func getVxpuProcessPids(vxpu types.VxpuDevice) []uint32 {
+	pidsConfigPath := filepath.Clean(filepath.Join(vxpuConfigBaseDir, vxpu.PodUID, vxpu.ContainerName,
+		pidsConfigFileName))
+	pids, err := readPidsConfig(pidsConfigPath)
+	if err != nil {
+		log.Errorf("read pids config error: %v, path: %s", err, pidsConfigPath)
+		return nil
+	}
+	xpuDevices, err := util.GetXPUs()
+	if err != nil {
+		return nil
+	}
+	device, ok := xpuDevices[vxpu.GpuId]
+	if !ok {
+		return nil
+	}
+	_, processMap, err := xpu.GetXPUUsage(device.Index, minPeriod)
+	if err != nil {
+		return nil
+	}
+	res := make([]uint32, 0)
+	for _, pid := range pids {
+		if _, ok := processMap[pid]; ok {
+			res = append(res, pid)
+		}
+	}
+	return res
+}
+
+func newLimitDetector() (*violation.Detector, error) {
+	actions, err := violation.ParseActions(config.ViolationActions)
+	if err != nil {
+		return nil, err
+	}
+	if len(actions) == 0 || config.SampleInterval <= 0 {
+		return nil, nil
+	}
+	return violation.NewDetector(violation.Config{
+		Actions:       actions,
+		Window:        config.ViolationWindow,
+		CoreTolerance: config.ViolationCoreTolerance,
+	}, getVxpuProcessPids), nil
+}
+
+// GetAllVgpuInfo get all vgpu info of the node
+func (PidsServiceServerImpl) GetAllVxpuInfo(ctx context.Context, req *GetAllVxpuInfoRequest) (*GetAllVxpuInfoResponse, error) {
+	xpuDevices, err := getAllVxpuInfo(parsePeriod(req.Period))
+	if err != nil {
+		return nil, err
+	}
+	setLimitExceeded(xpuDevices)
 	jsonVgpuInfos, err := json.Marshal(xpuDevices)
 	if err != nil {
 		return nil, err
@@ -394,34 +560,264 @@ func (PidsServiceServerImpl) GetAllVxpuInfo(ctx context.Context, req *GetAllVxpu
 	return &GetAllVxpuInfoResponse{VxpuInfos: string(jsonVgpuInfos)}, nil
 }
 
-// Start run pids service
-func Start() {
+// GetContainerProcesses get the xpu processes of the container and their usage
+func (PidsServiceServerImpl) GetContainerProcesses(ctx context.Context,
+	req *GetContainerProcessesRequest) (*GetContainerProcessesResponse, error) {
+	if req.PodUID == "" || req.ContainerName == "" {
+		return nil, errors.New("pod uid or container name is empty")
+	}
+	period := parsePeriod(req.Period)
+
+	containerDir, err := containerConfigDir(req.PodUID, req.ContainerName)
+	if err != nil {
+		return nil, err
+	}
+	// pidMaps: map[hostPid]containerPid
+	pidMaps, err := readPidMapsConfig(filepath.Join(containerDir, pidsConfigFileName))
+	if err != nil {
+		return nil, err
+	}
+	xpuIds, err := readVxpuIdsConfig(filepath.Join(containerDir, xpu.VxpuIdsConfigFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	// xpuDevices: map[uuid]xpuDevice
+	xpuDevices, err := util.GetXPUs()
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make(types.ContainerProcesses, 0)
+	visited := make(map[string]void)
+	for _, xpuId := range xpuIds {
+		device, ok := xpuDevices[xpuId]
+		if !ok {
+			continue
+		}
+		if _, ok := visited[xpuId]; ok {
+			continue
+		}
+		visited[xpuId] = val
+		_, processMap, err := xpu.GetXPUUsage(device.Index, int32(period))
+		if err != nil {
+			return nil, err
+		}
+		for hostPid, containerPid := range pidMaps {
+			pUsage, ok := processMap[hostPid]
+			if !ok {
+				continue
+			}
+			processes = append(processes, types.ContainerProcess{
+				HostPid:                hostPid,
+				ContainerPid:           containerPid,
+				Command:                readProcComm(hostPid),
+				GpuId:                  xpuId,
+				ProcessMemoryUsed:      pUsage.ProcessMem / bytesPerMB,
+				ProcessCoreUtilization: pUsage.ProcessCoreUtilization,
+			})
+		}
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		if processes[i].GpuId != processes[j].GpuId {
+			return processes[i].GpuId < processes[j].GpuId
+		}
+		return processes[i].HostPid < processes[j].HostPid
+	})
+
+	jsonProcesses, err := json.Marshal(processes)
+	if err != nil {
+		return nil, err
+	}
+	return &GetContainerProcessesResponse{ContainerProcesses: string(jsonProcesses)}, nil
+}
+
+// GetVxpuHistory get the usage history of xpus and vxpus in a time range
+func (PidsServiceServerImpl) GetVxpuHistory(ctx context.Context,
+	req *GetVxpuHistoryRequest) (*GetVxpuHistoryResponse, error) {
+	if usageRecorder == nil {
+		return nil, errors.New("usage history is disabled")
+	}
+	now := time.Now().Unix()
+	start, err := parseTimestamp(req.StartTime, now-int64(config.HistoryRetention.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseTimestamp(req.EndTime, now)
+	if err != nil {
+		return nil, err
+	}
+	if start > end {
+		return nil, fmt.Errorf("start time %d is after end time %d", start, end)
+	}
+
+	jsonHistory, err := json.Marshal(usageRecorder.Query(start, end, req.Id))
+	if err != nil {
+		return nil, err
+	}
+	return &GetVxpuHistoryResponse{VxpuHistory: string(jsonHistory)}, nil
+}
+
+// parseTimestamp parses unix seconds, defaultValue is returned if s is empty
+func parseTimestamp(s string, defaultValue int64) (int64, error) {
+	if s == "" {
+		return defaultValue, nil
+	}
+	ts, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %s: %v", s, err)
+	}
+	return ts, nil
+}
+
+// usageConsumer consumes the usage of all xpus and their vxpus sampled at ts
+type usageConsumer func(ts time.Time, xpuDevices map[string]*types.XPUDevice)
+
+// sampleUsage samples the usage every interval and feeds it to all consumers until ctx is cancelled,
+// so that the consumers share one query of the node pods and NVML per interval
+func sampleUsage(ctx context.Context, interval time.Duration, consumers []usageConsumer) error {
+	period := int(interval.Seconds())
+	if period < minPeriod {
+		period = minPeriod
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			xpuDevices, err := getAllVxpuInfo(period)
+			if err != nil {
+				log.Warningf("sample usage error: %v", err)
+				continue
+			}
+			now := time.Now()
+			for _, consume := range consumers {
+				consume(now, xpuDevices)
+			}
+		}
+	}
+}
+
+// cleanPodDirs removes the config dirs of destroyed pods periodically until ctx is cancelled
+func cleanPodDirs(ctx context.Context) error {
+	ticker := time.NewTicker(time.Second * podDirCleanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := cleanDestroyedPodDir(); err != nil {
+				return fmt.Errorf("clean destroyed pod dir error: %v", err)
+			}
+		}
+	}
+}
+
+func servingStatus(status supervisor.Status) healthpb.HealthCheckResponse_ServingStatus {
+	if status.State == supervisor.StateRunning && status.Ready {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+// setServingStatus reports every component with its name as the service name, the pids service
+// follows the pids-service component, and the empty service name stands for the overall health
+// which is serving only if all components are serving
+func setServingStatus(statuses []supervisor.Status) {
+	overall := healthpb.HealthCheckResponse_SERVING
+	for _, status := range statuses {
+		serving := servingStatus(status)
+		healthServer.SetServingStatus(status.Name, serving)
+		if status.Name == pidsServiceComponent {
+			healthServer.SetServingStatus(PidsService_ServiceDesc.ServiceName, serving)
+		}
+		if serving != healthpb.HealthCheckResponse_SERVING {
+			overall = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	healthServer.SetServingStatus("", overall)
+}
+
+// ReportHealth makes the serving status of the pids health server follow the states of the components
+func ReportHealth(sv *supervisor.Supervisor) {
+	update := func() {
+		healthMutex.Lock()
+		defer healthMutex.Unlock()
+		setServingStatus(sv.Statuses())
+	}
+	sv.Subscribe(func(supervisor.Status) { update() })
+	update()
+}
+
+// Serve run pids service until ctx is cancelled
+func Serve(ctx context.Context) error {
 	srv := grpc.NewServer()
 	RegisterPidsServiceServer(srv, PidsServiceServerImpl{})
+	healthpb.RegisterHealthServer(srv, healthServer)
+	if config.EnableReflection {
+		reflection.Register(srv)
+	}
 	err := syscall.Unlink(pidsSockPath)
 	if err != nil && !os.IsNotExist(err) {
-		return
+		return fmt.Errorf("unlink %s error: %v", pidsSockPath, err)
 	}
 	listener, err := net.Listen("unix", pidsSockPath)
 	if err != nil {
-		return
+		return fmt.Errorf("listen on %s error: %v", pidsSockPath, err)
 	}
 	err = os.Chmod(pidsSockPath, pidsSockPerm)
 	if err != nil {
-		return
+		listener.Close()
+		return fmt.Errorf("modify pids socket file permissions error: %v", err)
 	}
+	supervisor.Ready(ctx)
+	errCh := make(chan error, 1)
 	go func() {
-		err := srv.Serve(listener)
-		if err != nil {
-		}
-	}()
-	go func() {
-		for {
-			time.Sleep(time.Second * podDirCleanInterval)
-			err := cleanDestroyedPodDir()
-			if err != nil {
-				break
-			}
-		}
+		errCh <- srv.Serve(listener)
 	}()
+	select {
+	case <-ctx.Done():
+		srv.GracefulStop()
+		return nil
+	case err := <-errCh:
+		return fmt.Errorf("pids service serve error: %v", err)
+	}
+}
+
+// Components returns the subsystems of the pids service in start order
+func Components() ([]supervisor.Spec, error) {
+	detector, err := newLimitDetector()
+	if err != nil {
+		return nil, err
+	}
+	specs := []supervisor.Spec{
+		// the pids socket is mounted into the containers allocated by the device plugin
+		{Component: supervisor.Func(pidsServiceComponent, Serve), Policy: supervisor.RestartOnFailure, WaitReady: true},
+		{
+			Component:  supervisor.Func("pod-dir-cleaner", cleanPodDirs),
+			Policy:     supervisor.RestartOnFailure,
+			MinBackoff: time.Second * podDirCleanInterval,
+		},
+	}
+	consumers := make([]usageConsumer, 0)
+	if config.HistoryRetention > 0 && config.SampleInterval > 0 {
+		usageRecorder = history.NewRecorder(config.HistoryRetention, config.SampleInterval)
+		consumers = append(consumers, usageRecorder.Record)
+	}
+	if detector != nil {
+		limitDetector = detector
+		consumers = append(consumers, detector.Check)
+	}
+	if len(consumers) > 0 {
+		specs = append(specs, supervisor.Spec{
+			Component: supervisor.Func("usage-sampler", func(ctx context.Context) error {
+				return sampleUsage(ctx, config.SampleInterval, consumers)
+			}),
+			Policy: supervisor.RestartOnFailure,
+		})
+	}
+	return specs, nil
 }
@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"huawei.com/vxpu-device-plugin/pkg/supervisor"
+)
+
+func writeTestFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.config")
+	if err := os.WriteFile(path, []byte(content), configFilePerm); err != nil {
+		t.Fatalf("write %s error: %v", path, err)
+	}
+	return path
+}
+
+func TestReadPidMapsConfig(t *testing.T) {
+	path := writeTestFile(t, "1234        1          \n"+
+		"5678        25\n"+
+		"\n"+
+		"bad         3\n"+
+		"42\n"+
+		"7 8 9\n"+
+		"4294967296  4\n")
+	pidMaps, err := readPidMapsConfig(path)
+	if err != nil {
+		t.Fatalf("readPidMapsConfig error: %v", err)
+	}
+	want := map[uint32]uint32{1234: 1, 5678: 25}
+	if !reflect.DeepEqual(pidMaps, want) {
+		t.Errorf("readPidMapsConfig = %v, want %v", pidMaps, want)
+	}
+
+	if _, err := readPidMapsConfig(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("readPidMapsConfig of a missing file should fail")
+	}
+}
+
+func TestReadVxpuIdsConfig(t *testing.T) {
+	path := writeTestFile(t, "GPU-1b2c3d4e-0000-1111-2222-333344445555-0\n"+
+		"  GPU-1b2c3d4e-0000-1111-2222-333344445555-1  \n"+
+		"\n"+
+		"-3\n"+
+		"nodash\n"+
+		"GPU-aaaa-2\n")
+	xpuIds, err := readVxpuIdsConfig(path)
+	if err != nil {
+		t.Fatalf("readVxpuIdsConfig error: %v", err)
+	}
+	want := []string{
+		"GPU-1b2c3d4e-0000-1111-2222-333344445555",
+		"GPU-1b2c3d4e-0000-1111-2222-333344445555",
+		"GPU-aaaa",
+	}
+	if !reflect.DeepEqual(xpuIds, want) {
+		t.Errorf("readVxpuIdsConfig = %v, want %v", xpuIds, want)
+	}
+
+	if _, err := readVxpuIdsConfig(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("readVxpuIdsConfig of a missing file should fail")
+	}
+}
+
+func TestContainerConfigDir(t *testing.T) {
+	tests := []struct {
+		name          string
+		podUID        string
+		containerName string
+		want          string
+		wantErr       bool
+	}{
+		{name: "valid", podUID: "pod-uid", containerName: "main",
+			want: filepath.Join(vxpuConfigBaseDir, "pod-uid", "main")},
+		{name: "empty pod uid", podUID: "", containerName: "main", wantErr: true},
+		{name: "empty container", podUID: "pod-uid", containerName: "", wantErr: true},
+		{name: "dot pod uid", podUID: ".", containerName: "main", wantErr: true},
+		{name: "parent pod uid", podUID: "..", containerName: "passwd", wantErr: true},
+		{name: "parent container", podUID: "pod-uid", containerName: "..", wantErr: true},
+		{name: "traversal in pod uid", podUID: "../../root", containerName: "main", wantErr: true},
+		{name: "traversal in container", podUID: "pod-uid", containerName: "../other/main", wantErr: true},
+		{name: "absolute container", podUID: "pod-uid", containerName: "/etc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := containerConfigDir(tt.podUID, tt.containerName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("containerConfigDir error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("containerConfigDir = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetServingStatus(t *testing.T) {
+	const (
+		serving    = healthpb.HealthCheckResponse_SERVING
+		notServing = healthpb.HealthCheckResponse_NOT_SERVING
+	)
+	pidsService := supervisor.Status{Name: pidsServiceComponent, State: supervisor.StateRunning, Ready: true}
+	sampler := supervisor.Status{Name: "usage-sampler", State: supervisor.StateRunning, Ready: true}
+	tests := []struct {
+		name     string
+		statuses []supervisor.Status
+		want     map[string]healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{
+			name:     "all running",
+			statuses: []supervisor.Status{pidsService, sampler},
+			want: map[string]healthpb.HealthCheckResponse_ServingStatus{"": serving,
+				PidsService_ServiceDesc.ServiceName: serving, pidsServiceComponent: serving, sampler.Name: serving},
+		},
+		{
+			name: "pids service not ready",
+			statuses: []supervisor.Status{{Name: pidsServiceComponent, State: supervisor.StateRunning},
+				sampler},
+			want: map[string]healthpb.HealthCheckResponse_ServingStatus{"": notServing,
+				PidsService_ServiceDesc.ServiceName: notServing, sampler.Name: serving},
+		},
+		{
+			name:     "component in backoff",
+			statuses: []supervisor.Status{pidsService, {Name: sampler.Name, State: supervisor.StateBackoff}},
+			want: map[string]healthpb.HealthCheckResponse_ServingStatus{"": notServing,
+				PidsService_ServiceDesc.ServiceName: serving, sampler.Name: notServing},
+		},
+		{
+			name:     "stopped",
+			statuses: []supervisor.Status{{Name: pidsServiceComponent, State: supervisor.StateStopped}},
+			want: map[string]healthpb.HealthCheckResponse_ServingStatus{"": notServing,
+				PidsService_ServiceDesc.ServiceName: notServing},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setServingStatus(tt.statuses)
+			for service, want := range tt.want {
+				resp, err := healthServer.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+				if err != nil {
+					t.Fatalf("check %q error: %v", service, err)
+				}
+				if resp.Status != want {
+					t.Errorf("status of %q = %v, want %v", service, resp.Status, want)
+				}
+			}
+		})
+	}
+}
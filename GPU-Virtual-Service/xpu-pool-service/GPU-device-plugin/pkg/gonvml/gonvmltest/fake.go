@@ -0,0 +1,314 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package gonvmltest implements the fake devices of gonvml for the tests of the health checker and exporter,
+// it is imported by the tests only
+package gonvmltest
+
+import (
+	"huawei.com/vxpu-device-plugin/pkg/gonvml"
+)
+
+// FakeDevice is a device whose values are set by the fields instead of read from the NVML library.
+// Rets overrides the return value of the method with the same name, such as
+// Rets["GetFanSpeed"] = ErrorNotSupported, the methods not in Rets return Success.
+type FakeDevice struct {
+	Name                  string
+	UUID                  string
+	Index                 int
+	Memory                gonvml.MemoryV2
+	Utilization           gonvml.Utilization
+	Processes             []gonvml.ProcessInfoV1
+	ProcessSamples        []gonvml.ProcessUtilizationSample
+	MultiGpuBoard         int
+	Topology              gonvml.GpuTopologyLevel
+	NearestGpus           []gonvml.Device
+	Temperature           uint32
+	PowerUsage            uint32
+	EccErrors             map[gonvml.EccCounterType]map[gonvml.MemoryErrorType]uint64
+	Clocks                map[gonvml.ClockType]uint32
+	ClocksThrottleReasons uint64
+	PcieThroughput        map[gonvml.PcieUtilCounter]uint32
+	PcieReplayCounter     uint32
+	NvLinkStates          []gonvml.EnableState
+	NvLinkErrors          []map[gonvml.NvLinkErrorCounter]uint64
+	RetiredPages          map[gonvml.PageRetirementCause][]uint64
+	RetiredPagesPending   gonvml.EnableState
+	RemappedRows          gonvml.RemappedRows
+	FanSpeed              uint32
+	EncoderUtilization    uint32
+	DecoderUtilization    uint32
+	SamplingPeriodUs      uint32
+	MigMode               int
+	PendingMigMode        int
+	// MigDevices the MIG devices by index, the nil ones are not found
+	MigDevices        []*FakeDevice
+	Parent            *FakeDevice
+	GpuInstanceId     int
+	ComputeInstanceId int
+	Attributes        gonvml.DeviceAttributes
+	Rets              map[string]gonvml.NvmlRetType
+}
+
+var _ gonvml.Device = &FakeDevice{}
+
+func (d *FakeDevice) ret(method string) gonvml.NvmlRetType {
+	return d.Rets[method]
+}
+
+// GetMemoryInfoV2 returns Memory
+func (d *FakeDevice) GetMemoryInfoV2() (gonvml.MemoryV2, gonvml.NvmlRetType) {
+	return d.Memory, d.ret("GetMemoryInfoV2")
+}
+
+// GetName returns Name
+func (d *FakeDevice) GetName() (string, gonvml.NvmlRetType) {
+	return d.Name, d.ret("GetName")
+}
+
+// RegisterEvents does nothing, the events of fake device are sent by FakeEventSet
+func (d *FakeDevice) RegisterEvents(uint64, gonvml.EventSet) gonvml.NvmlRetType {
+	return d.ret("RegisterEvents")
+}
+
+// GetUUID returns UUID
+func (d *FakeDevice) GetUUID() (string, gonvml.NvmlRetType) {
+	return d.UUID, d.ret("GetUUID")
+}
+
+// GetIndex returns Index
+func (d *FakeDevice) GetIndex() (int, gonvml.NvmlRetType) {
+	return d.Index, d.ret("GetIndex")
+}
+
+// GetUtilizationRates returns Utilization
+func (d *FakeDevice) GetUtilizationRates() (gonvml.Utilization, gonvml.NvmlRetType) {
+	return d.Utilization, d.ret("GetUtilizationRates")
+}
+
+// GetComputeRunningProcesses returns Processes
+func (d *FakeDevice) GetComputeRunningProcesses() ([]gonvml.ProcessInfoV1, gonvml.NvmlRetType) {
+	return d.Processes, d.ret("GetComputeRunningProcesses")
+}
+
+// DeviceGetProcessUtilization returns the ProcessSamples later than timestamp
+func (d *FakeDevice) DeviceGetProcessUtilization(timestamp uint64) (
+	[]gonvml.ProcessUtilizationSample, gonvml.NvmlRetType) {
+	samples := make([]gonvml.ProcessUtilizationSample, 0, len(d.ProcessSamples))
+	for _, sample := range d.ProcessSamples {
+		if sample.TimeStamp > timestamp {
+			samples = append(samples, sample)
+		}
+	}
+	return samples, d.ret("DeviceGetProcessUtilization")
+}
+
+// GetMultiGpuBoard returns MultiGpuBoard
+func (d *FakeDevice) GetMultiGpuBoard() (int, gonvml.NvmlRetType) {
+	return d.MultiGpuBoard, d.ret("GetMultiGpuBoard")
+}
+
+// GetTopologyCommonAncestor returns Topology whatever the other device is
+func (d *FakeDevice) GetTopologyCommonAncestor(gonvml.Device) (gonvml.GpuTopologyLevel, gonvml.NvmlRetType) {
+	return d.Topology, d.ret("GetTopologyCommonAncestor")
+}
+
+// GetTopologyNearestGpus returns NearestGpus whatever the level is
+func (d *FakeDevice) GetTopologyNearestGpus(gonvml.GpuTopologyLevel) ([]gonvml.Device, gonvml.NvmlRetType) {
+	return d.NearestGpus, d.ret("GetTopologyNearestGpus")
+}
+
+// GetTemperature returns Temperature
+func (d *FakeDevice) GetTemperature(gonvml.NvmlTemperatureSensors) (uint32, gonvml.NvmlRetType) {
+	return d.Temperature, d.ret("GetTemperature")
+}
+
+// GetPowerUsage returns PowerUsage
+func (d *FakeDevice) GetPowerUsage() (uint32, gonvml.NvmlRetType) {
+	return d.PowerUsage, d.ret("GetPowerUsage")
+}
+
+// GetTotalEccErrors returns the EccErrors of the counter type and error type
+func (d *FakeDevice) GetTotalEccErrors(errorType gonvml.MemoryErrorType, counterType gonvml.EccCounterType) (
+	uint64, gonvml.NvmlRetType) {
+	return d.EccErrors[counterType][errorType], d.ret("GetTotalEccErrors")
+}
+
+// GetClockInfo returns the Clocks of the clock type
+func (d *FakeDevice) GetClockInfo(clockType gonvml.ClockType) (uint32, gonvml.NvmlRetType) {
+	return d.Clocks[clockType], d.ret("GetClockInfo")
+}
+
+// GetCurrentClocksThrottleReasons returns ClocksThrottleReasons
+func (d *FakeDevice) GetCurrentClocksThrottleReasons() (uint64, gonvml.NvmlRetType) {
+	return d.ClocksThrottleReasons, d.ret("GetCurrentClocksThrottleReasons")
+}
+
+// GetPcieThroughput returns the PcieThroughput of the counter
+func (d *FakeDevice) GetPcieThroughput(counter gonvml.PcieUtilCounter) (uint32, gonvml.NvmlRetType) {
+	return d.PcieThroughput[counter], d.ret("GetPcieThroughput")
+}
+
+// GetPcieReplayCounter returns PcieReplayCounter
+func (d *FakeDevice) GetPcieReplayCounter() (uint32, gonvml.NvmlRetType) {
+	return d.PcieReplayCounter, d.ret("GetPcieReplayCounter")
+}
+
+// GetNvLinkState returns the NvLinkStates of the link, the links out of NvLinkStates are invalid
+func (d *FakeDevice) GetNvLinkState(link int) (gonvml.EnableState, gonvml.NvmlRetType) {
+	if link < 0 || link >= len(d.NvLinkStates) {
+		return gonvml.FeatureDisabled, gonvml.ErrorInvalidArgument
+	}
+	return d.NvLinkStates[link], d.ret("GetNvLinkState")
+}
+
+// GetNvLinkErrorCounter returns the NvLinkErrors of the link and counter
+func (d *FakeDevice) GetNvLinkErrorCounter(link int, counter gonvml.NvLinkErrorCounter) (uint64, gonvml.NvmlRetType) {
+	if link < 0 || link >= len(d.NvLinkStates) {
+		return 0, gonvml.ErrorInvalidArgument
+	}
+	if link >= len(d.NvLinkErrors) {
+		return 0, d.ret("GetNvLinkErrorCounter")
+	}
+	return d.NvLinkErrors[link][counter], d.ret("GetNvLinkErrorCounter")
+}
+
+// GetRetiredPages returns the RetiredPages of the cause
+func (d *FakeDevice) GetRetiredPages(cause gonvml.PageRetirementCause) ([]uint64, gonvml.NvmlRetType) {
+	return append([]uint64{}, d.RetiredPages[cause]...), d.ret("GetRetiredPages")
+}
+
+// GetRetiredPagesPendingStatus returns RetiredPagesPending
+func (d *FakeDevice) GetRetiredPagesPendingStatus() (gonvml.EnableState, gonvml.NvmlRetType) {
+	return d.RetiredPagesPending, d.ret("GetRetiredPagesPendingStatus")
+}
+
+// GetRemappedRows returns RemappedRows
+func (d *FakeDevice) GetRemappedRows() (gonvml.RemappedRows, gonvml.NvmlRetType) {
+	return d.RemappedRows, d.ret("GetRemappedRows")
+}
+
+// GetFanSpeed returns FanSpeed
+func (d *FakeDevice) GetFanSpeed() (uint32, gonvml.NvmlRetType) {
+	return d.FanSpeed, d.ret("GetFanSpeed")
+}
+
+// GetEncoderUtilization returns EncoderUtilization and SamplingPeriodUs
+func (d *FakeDevice) GetEncoderUtilization() (uint32, uint32, gonvml.NvmlRetType) {
+	return d.EncoderUtilization, d.SamplingPeriodUs, d.ret("GetEncoderUtilization")
+}
+
+// GetDecoderUtilization returns DecoderUtilization and SamplingPeriodUs
+func (d *FakeDevice) GetDecoderUtilization() (uint32, uint32, gonvml.NvmlRetType) {
+	return d.DecoderUtilization, d.SamplingPeriodUs, d.ret("GetDecoderUtilization")
+}
+
+// GetMigMode returns MigMode and PendingMigMode
+func (d *FakeDevice) GetMigMode() (int, int, gonvml.NvmlRetType) {
+	return d.MigMode, d.PendingMigMode, d.ret("GetMigMode")
+}
+
+// GetMaxMigDeviceCount returns the length of MigDevices
+func (d *FakeDevice) GetMaxMigDeviceCount() (int, gonvml.NvmlRetType) {
+	return len(d.MigDevices), d.ret("GetMaxMigDeviceCount")
+}
+
+// GetMigDeviceHandleByIndex returns the MigDevices of the index
+func (d *FakeDevice) GetMigDeviceHandleByIndex(index int) (gonvml.Device, gonvml.NvmlRetType) {
+	if index < 0 || index >= len(d.MigDevices) {
+		return nil, gonvml.ErrorInvalidArgument
+	}
+	if d.MigDevices[index] == nil {
+		return nil, gonvml.ErrorNotFound
+	}
+	return d.MigDevices[index], d.ret("GetMigDeviceHandleByIndex")
+}
+
+// GetDeviceHandleFromMigDeviceHandle returns Parent
+func (d *FakeDevice) GetDeviceHandleFromMigDeviceHandle() (gonvml.Device, gonvml.NvmlRetType) {
+	if d.Parent == nil {
+		return nil, gonvml.ErrorInvalidArgument
+	}
+	return d.Parent, d.ret("GetDeviceHandleFromMigDeviceHandle")
+}
+
+// IsMigDeviceHandle returns whether Parent is set
+func (d *FakeDevice) IsMigDeviceHandle() (bool, gonvml.NvmlRetType) {
+	return d.Parent != nil, d.ret("IsMigDeviceHandle")
+}
+
+// GetGpuInstanceId returns GpuInstanceId
+func (d *FakeDevice) GetGpuInstanceId() (int, gonvml.NvmlRetType) {
+	return d.GpuInstanceId, d.ret("GetGpuInstanceId")
+}
+
+// GetComputeInstanceId returns ComputeInstanceId
+func (d *FakeDevice) GetComputeInstanceId() (int, gonvml.NvmlRetType) {
+	return d.ComputeInstanceId, d.ret("GetComputeInstanceId")
+}
+
+// GetAttributes returns Attributes
+func (d *FakeDevice) GetAttributes() (gonvml.DeviceAttributes, gonvml.NvmlRetType) {
+	return d.Attributes, d.ret("GetAttributes")
+}
+
+// FakeEventSet is an event set whose events are sent by the channel Events,
+// Wait returns ErrorTimeout when no event is sent
+type FakeEventSet struct {
+	Events chan gonvml.EventData
+}
+
+var _ gonvml.EventSet = &FakeEventSet{}
+
+// NewFakeEventSet creates a fake event set with the buffer of size events
+func NewFakeEventSet(size int) *FakeEventSet {
+	return &FakeEventSet{Events: make(chan gonvml.EventData, size)}
+}
+
+// Free does nothing
+func (set *FakeEventSet) Free() gonvml.NvmlRetType {
+	return gonvml.Success
+}
+
+// Wait returns the event sent to Events, the timeout is ignored
+func (set *FakeEventSet) Wait(uint32) (gonvml.EventData, gonvml.NvmlRetType) {
+	select {
+	case data := <-set.Events:
+		return data, gonvml.Success
+	default:
+		return gonvml.EventData{}, gonvml.ErrorTimeout
+	}
+}
+
+// UseFakeDevices replaces the api adapters of the devices with the fake devices, the index of
+// fake device is the position in devices, and the MIG devices are found by uuid as well.
+// The returned function restores the adapters.
+func UseFakeDevices(devices []*FakeDevice) func() {
+	getCount, getByIndex, getByUUID := gonvml.DeviceGetCount, gonvml.DeviceGetHandleByIndex, gonvml.DeviceGetHandleByUUID
+	gonvml.DeviceGetCount = func() (int, gonvml.NvmlRetType) {
+		return len(devices), gonvml.Success
+	}
+	gonvml.DeviceGetHandleByIndex = func(index int) (gonvml.Device, gonvml.NvmlRetType) {
+		if index < 0 || index >= len(devices) {
+			return nil, gonvml.ErrorInvalidArgument
+		}
+		return devices[index], gonvml.Success
+	}
+	gonvml.DeviceGetHandleByUUID = func(uuid string) (gonvml.Device, gonvml.NvmlRetType) {
+		for _, device := range devices {
+			if device.UUID == uuid {
+				return device, gonvml.Success
+			}
+			for _, migDevice := range device.MigDevices {
+				if migDevice != nil && migDevice.UUID == uuid {
+					return migDevice, gonvml.Success
+				}
+			}
+		}
+		return nil, gonvml.ErrorNotFound
+	}
+	return func() {
+		gonvml.DeviceGetCount, gonvml.DeviceGetHandleByIndex, gonvml.DeviceGetHandleByUUID = getCount, getByIndex, getByUUID
+	}
+}
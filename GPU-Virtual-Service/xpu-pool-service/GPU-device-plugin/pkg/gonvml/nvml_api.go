@@ -29,6 +29,27 @@ type Device interface {
 	GetTopologyNearestGpus(GpuTopologyLevel) ([]Device, NvmlRetType)
 	GetTemperature(NvmlTemperatureSensors) (uint32, NvmlRetType)
 	GetPowerUsage() (uint32, NvmlRetType)
+	GetTotalEccErrors(MemoryErrorType, EccCounterType) (uint64, NvmlRetType)
+	GetClockInfo(ClockType) (uint32, NvmlRetType)
+	GetCurrentClocksThrottleReasons() (uint64, NvmlRetType)
+	GetPcieThroughput(PcieUtilCounter) (uint32, NvmlRetType)
+	GetPcieReplayCounter() (uint32, NvmlRetType)
+	GetNvLinkState(link int) (EnableState, NvmlRetType)
+	GetNvLinkErrorCounter(link int, counter NvLinkErrorCounter) (uint64, NvmlRetType)
+	GetRetiredPages(PageRetirementCause) ([]uint64, NvmlRetType)
+	GetRetiredPagesPendingStatus() (EnableState, NvmlRetType)
+	GetRemappedRows() (RemappedRows, NvmlRetType)
+	GetFanSpeed() (uint32, NvmlRetType)
+	GetEncoderUtilization() (utilization uint32, samplingPeriodUs uint32, ret NvmlRetType)
+	GetDecoderUtilization() (utilization uint32, samplingPeriodUs uint32, ret NvmlRetType)
+	GetMigMode() (currentMode int, pendingMode int, ret NvmlRetType)
+	GetMaxMigDeviceCount() (int, NvmlRetType)
+	GetMigDeviceHandleByIndex(index int) (Device, NvmlRetType)
+	GetDeviceHandleFromMigDeviceHandle() (Device, NvmlRetType)
+	IsMigDeviceHandle() (bool, NvmlRetType)
+	GetGpuInstanceId() (int, NvmlRetType)
+	GetComputeInstanceId() (int, NvmlRetType)
+	GetAttributes() (DeviceAttributes, NvmlRetType)
 }
 
 // EventSet define nvml EventSet interface
@@ -32,3 +32,24 @@ type ProcessUtilizationSample struct {
 	EncUtil   uint32
 	DecUtil   uint32
 }
+
+// RemappedRows the rows remapped of the device memory
+type RemappedRows struct {
+	Correctable     uint32
+	Uncorrectable   uint32
+	Pending         bool
+	FailureOccurred bool
+}
+
+// DeviceAttributes the attributes of the device, such as the resources of the MIG device
+type DeviceAttributes struct {
+	MultiprocessorCount       uint32
+	SharedCopyEngineCount     uint32
+	SharedDecoderCount        uint32
+	SharedEncoderCount        uint32
+	SharedJpegCount           uint32
+	SharedOfaCount            uint32
+	GpuInstanceSliceCount     uint32
+	ComputeInstanceSliceCount uint32
+	MemorySizeMB              uint64
+}
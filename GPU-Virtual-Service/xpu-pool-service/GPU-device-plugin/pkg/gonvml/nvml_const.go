@@ -36,13 +36,13 @@ const (
 	ErrorNotSupported
 	ErrorNoPermission
 	ErrorAlreadyInitialized
-	ErrorNotFound NvmlRetType = 6
+	ErrorNotFound
 	ErrorInsufficientSize
 	ErrorInsufficientPower
 	ErrorDriverNotLoaded
 	ErrorTimeout
 	ErrorIrqIssue
-	ErrorLibraryNotFound NvmlRetType = 12
+	ErrorLibraryNotFound
 	ErrorFunctionNotFound
 	ErrorCorruptedInfo
 	ErrorGpuIsLost
@@ -77,3 +77,91 @@ type NvmlTemperatureSensors int32
 const (
 	NvmlTemperatureGpu NvmlTemperatureSensors = 0
 )
+
+// MemoryErrorType enumeration from nvml/nvml.h
+const (
+	MemoryErrorTypeCorrected   MemoryErrorType = 0
+	MemoryErrorTypeUncorrected MemoryErrorType = 1
+)
+
+// EccCounterType as declared in nvml/nvml.h
+type EccCounterType int32
+
+// EccCounterType enumeration from nvml/nvml.h
+const (
+	VolatileEcc  EccCounterType = 0
+	AggregateEcc EccCounterType = 1
+)
+
+// ClockType as declared in nvml/nvml.h
+type ClockType int32
+
+// ClockType enumeration from nvml/nvml.h
+const (
+	ClockGraphics ClockType = 0
+	ClockSM       ClockType = 1
+	ClockMem      ClockType = 2
+	ClockVideo    ClockType = 3
+)
+
+// The reasons of clocks throttle, the bits of nvmlDeviceGetCurrentClocksThrottleReasons as defined in nvml/nvml.h
+const (
+	ClocksThrottleReasonGpuIdle                   uint64 = 0x0000000000000001
+	ClocksThrottleReasonApplicationsClocksSetting uint64 = 0x0000000000000002
+	ClocksThrottleReasonSwPowerCap                uint64 = 0x0000000000000004
+	ClocksThrottleReasonHwSlowdown                uint64 = 0x0000000000000008
+	ClocksThrottleReasonSyncBoost                 uint64 = 0x0000000000000010
+	ClocksThrottleReasonSwThermalSlowdown         uint64 = 0x0000000000000020
+	ClocksThrottleReasonHwThermalSlowdown         uint64 = 0x0000000000000040
+	ClocksThrottleReasonHwPowerBrakeSlowdown      uint64 = 0x0000000000000080
+	ClocksThrottleReasonDisplayClockSetting       uint64 = 0x0000000000000100
+	ClocksThrottleReasonNone                      uint64 = 0x0000000000000000
+)
+
+// PcieUtilCounter as declared in nvml/nvml.h
+type PcieUtilCounter int32
+
+// PcieUtilCounter enumeration from nvml/nvml.h, the throughput is in KB/s
+const (
+	PcieUtilTxBytes PcieUtilCounter = 0
+	PcieUtilRxBytes PcieUtilCounter = 1
+)
+
+// EnableState as declared in nvml/nvml.h
+type EnableState int32
+
+// EnableState enumeration from nvml/nvml.h
+const (
+	FeatureDisabled EnableState = 0
+	FeatureEnabled  EnableState = 1
+)
+
+// NvlinkMaxLinks as defined in nvml/nvml.h
+const NvlinkMaxLinks = 18
+
+// NvLinkErrorCounter as declared in nvml/nvml.h
+type NvLinkErrorCounter int32
+
+// NvLinkErrorCounter enumeration from nvml/nvml.h
+const (
+	NvlinkErrorDlReplay   NvLinkErrorCounter = 0
+	NvlinkErrorDlRecovery NvLinkErrorCounter = 1
+	NvlinkErrorDlCrcFlit  NvLinkErrorCounter = 2
+	NvlinkErrorDlCrcData  NvLinkErrorCounter = 3
+	NvlinkErrorDlEccData  NvLinkErrorCounter = 4
+)
+
+// PageRetirementCause as declared in nvml/nvml.h
+type PageRetirementCause int32
+
+// PageRetirementCause enumeration from nvml/nvml.h
+const (
+	PageRetirementCauseMultipleSingleBitEccErrors PageRetirementCause = 0
+	PageRetirementCauseDoubleBitEccError          PageRetirementCause = 1
+)
+
+// MIG mode of nvmlDeviceGetMigMode as defined in nvml/nvml.h
+const (
+	DeviceMigDisable = 0
+	DeviceMigEnable  = 1
+)
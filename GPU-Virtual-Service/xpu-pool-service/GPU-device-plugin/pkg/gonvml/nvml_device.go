@@ -134,3 +134,154 @@ func (device nvmlDevice) GetPowerUsage() (uint32, NvmlRetType) {
 	ret := nvmlDeviceGetPowerUsageWrapper(device, &power)
 	return power, ret
 }
+
+func (device nvmlDevice) GetTotalEccErrors(errorType MemoryErrorType, counterType EccCounterType) (uint64, NvmlRetType) {
+	var eccCounts uint64
+	ret := nvmlDeviceGetTotalEccErrorsWrapper(device, errorType, counterType, &eccCounts)
+	return eccCounts, ret
+}
+
+func (device nvmlDevice) GetClockInfo(clockType ClockType) (uint32, NvmlRetType) {
+	var clock uint32
+	ret := nvmlDeviceGetClockInfoWrapper(device, clockType, &clock)
+	return clock, ret
+}
+
+func (device nvmlDevice) GetCurrentClocksThrottleReasons() (uint64, NvmlRetType) {
+	var reasons uint64
+	ret := nvmlDeviceGetCurrentClocksThrottleReasonsWrapper(device, &reasons)
+	return reasons, ret
+}
+
+func (device nvmlDevice) GetPcieThroughput(counter PcieUtilCounter) (uint32, NvmlRetType) {
+	var value uint32
+	ret := nvmlDeviceGetPcieThroughputWrapper(device, counter, &value)
+	return value, ret
+}
+
+func (device nvmlDevice) GetPcieReplayCounter() (uint32, NvmlRetType) {
+	var value uint32
+	ret := nvmlDeviceGetPcieReplayCounterWrapper(device, &value)
+	return value, ret
+}
+
+func (device nvmlDevice) GetNvLinkState(link int) (EnableState, NvmlRetType) {
+	var isActive EnableState
+	ret := nvmlDeviceGetNvLinkStateWrapper(device, uint32(link), &isActive)
+	return isActive, ret
+}
+
+func (device nvmlDevice) GetNvLinkErrorCounter(link int, counter NvLinkErrorCounter) (uint64, NvmlRetType) {
+	var value uint64
+	ret := nvmlDeviceGetNvLinkErrorCounterWrapper(device, uint32(link), counter, &value)
+	return value, ret
+}
+
+func (device nvmlDevice) GetRetiredPages(cause PageRetirementCause) ([]uint64, NvmlRetType) {
+	return retiredPages(func(count *uint32, addresses *uint64) NvmlRetType {
+		return nvmlDeviceGetRetiredPagesWrapper(device, cause, count, addresses)
+	})
+}
+
+// retiredPages queries the count of the pages by get with nil addresses, then the addresses
+func retiredPages(get func(count *uint32, addresses *uint64) NvmlRetType) ([]uint64, NvmlRetType) {
+	var count uint32
+	ret := get(&count, nil)
+	if ret != Success && ret != ErrorInsufficientSize {
+		return nil, ret
+	}
+	if count == 0 {
+		return []uint64{}, Success
+	}
+	addresses := make([]uint64, count)
+	ret = get(&count, &addresses[0])
+	return addresses[:count], ret
+}
+
+func (device nvmlDevice) GetRetiredPagesPendingStatus() (EnableState, NvmlRetType) {
+	var isPending EnableState
+	ret := nvmlDeviceGetRetiredPagesPendingStatusWrapper(device, &isPending)
+	return isPending, ret
+}
+
+func (device nvmlDevice) GetRemappedRows() (RemappedRows, NvmlRetType) {
+	var corrRows, uncRows, isPending, failureOccurred uint32
+	ret := nvmlDeviceGetRemappedRowsWrapper(device, &corrRows, &uncRows, &isPending, &failureOccurred)
+	return newRemappedRows(corrRows, uncRows, isPending, failureOccurred), ret
+}
+
+// newRemappedRows converts the values of nvmlDeviceGetRemappedRows, the flags are nonzero when set
+func newRemappedRows(corrRows, uncRows, isPending, failureOccurred uint32) RemappedRows {
+	return RemappedRows{
+		Correctable:     corrRows,
+		Uncorrectable:   uncRows,
+		Pending:         isPending != 0,
+		FailureOccurred: failureOccurred != 0,
+	}
+}
+
+func (device nvmlDevice) GetFanSpeed() (uint32, NvmlRetType) {
+	var speed uint32
+	ret := nvmlDeviceGetFanSpeedWrapper(device, &speed)
+	return speed, ret
+}
+
+func (device nvmlDevice) GetEncoderUtilization() (uint32, uint32, NvmlRetType) {
+	var utilization, samplingPeriodUs uint32
+	ret := nvmlDeviceGetEncoderUtilizationWrapper(device, &utilization, &samplingPeriodUs)
+	return utilization, samplingPeriodUs, ret
+}
+
+func (device nvmlDevice) GetDecoderUtilization() (uint32, uint32, NvmlRetType) {
+	var utilization, samplingPeriodUs uint32
+	ret := nvmlDeviceGetDecoderUtilizationWrapper(device, &utilization, &samplingPeriodUs)
+	return utilization, samplingPeriodUs, ret
+}
+
+func (device nvmlDevice) GetMigMode() (int, int, NvmlRetType) {
+	var currentMode, pendingMode uint32
+	ret := nvmlDeviceGetMigModeWrapper(device, &currentMode, &pendingMode)
+	return int(currentMode), int(pendingMode), ret
+}
+
+func (device nvmlDevice) GetMaxMigDeviceCount() (int, NvmlRetType) {
+	var count uint32
+	ret := nvmlDeviceGetMaxMigDeviceCountWrapper(device, &count)
+	return int(count), ret
+}
+
+func (device nvmlDevice) GetMigDeviceHandleByIndex(index int) (Device, NvmlRetType) {
+	var migDevice nvmlDevice
+	ret := nvmlDeviceGetMigDeviceHandleByIndexWrapper(device, uint32(index), &migDevice)
+	return migDevice, ret
+}
+
+func (migDevice nvmlDevice) GetDeviceHandleFromMigDeviceHandle() (Device, NvmlRetType) {
+	var device nvmlDevice
+	ret := nvmlDeviceGetDeviceHandleFromMigDeviceHandleWrapper(migDevice, &device)
+	return device, ret
+}
+
+func (device nvmlDevice) IsMigDeviceHandle() (bool, NvmlRetType) {
+	var isMigDevice uint32
+	ret := nvmlDeviceIsMigDeviceHandleWrapper(device, &isMigDevice)
+	return isMigDevice != 0, ret
+}
+
+func (device nvmlDevice) GetGpuInstanceId() (int, NvmlRetType) {
+	var id uint32
+	ret := nvmlDeviceGetGpuInstanceIdWrapper(device, &id)
+	return int(id), ret
+}
+
+func (device nvmlDevice) GetComputeInstanceId() (int, NvmlRetType) {
+	var id uint32
+	ret := nvmlDeviceGetComputeInstanceIdWrapper(device, &id)
+	return int(id), ret
+}
+
+func (device nvmlDevice) GetAttributes() (DeviceAttributes, NvmlRetType) {
+	var attributes DeviceAttributes
+	ret := nvmlDeviceGetAttributesWrapper(device, &attributes)
+	return attributes, ret
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package gonvml
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+// fakeRetiredPages returns the get of retiredPages which reports the pages like nvmlDeviceGetRetiredPages,
+// countRet is returned by the query of the count
+func fakeRetiredPages(pages []uint64, countRet NvmlRetType) func(count *uint32, addresses *uint64) NvmlRetType {
+	return func(count *uint32, addresses *uint64) NvmlRetType {
+		if addresses == nil {
+			*count = uint32(len(pages))
+			return countRet
+		}
+		n := copy(unsafe.Slice(addresses, *count), pages)
+		*count = uint32(n)
+		if n < len(pages) {
+			return ErrorInsufficientSize
+		}
+		return Success
+	}
+}
+
+func TestRetiredPages(t *testing.T) {
+	tests := []struct {
+		name    string
+		get     func(count *uint32, addresses *uint64) NvmlRetType
+		want    []uint64
+		wantRet NvmlRetType
+	}{
+		{name: "no page", get: fakeRetiredPages(nil, Success), want: []uint64{}, wantRet: Success},
+		{name: "count by success", get: fakeRetiredPages([]uint64{0x1000, 0x2000}, Success),
+			want: []uint64{0x1000, 0x2000}, wantRet: Success},
+		{name: "count by insufficient size", get: fakeRetiredPages([]uint64{0x3000}, ErrorInsufficientSize),
+			want: []uint64{0x3000}, wantRet: Success},
+		{name: "not supported", get: fakeRetiredPages([]uint64{0x1000}, ErrorNotSupported),
+			wantRet: ErrorNotSupported},
+		{name: "pages retired between the calls", get: func(count *uint32, addresses *uint64) NvmlRetType {
+			if addresses == nil {
+				*count = 1
+				return Success
+			}
+			return fakeRetiredPages([]uint64{0x1000, 0x2000}, Success)(count, addresses)
+		}, want: []uint64{0x1000}, wantRet: ErrorInsufficientSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ret := retiredPages(tt.get)
+			if !reflect.DeepEqual(got, tt.want) || ret != tt.wantRet {
+				t.Errorf("retiredPages = %v, %v, want %v, %v", got, ret, tt.want, tt.wantRet)
+			}
+		})
+	}
+}
+
+func TestNewRemappedRows(t *testing.T) {
+	tests := []struct {
+		corrRows, uncRows, isPending, failureOccurred uint32
+		want                                          RemappedRows
+	}{
+		{want: RemappedRows{}},
+		{corrRows: 3, uncRows: 1, isPending: 1, want: RemappedRows{Correctable: 3, Uncorrectable: 1, Pending: true}},
+		{isPending: 2, failureOccurred: 1, want: RemappedRows{Pending: true, FailureOccurred: true}},
+	}
+	for _, tt := range tests {
+		got := newRemappedRows(tt.corrRows, tt.uncRows, tt.isPending, tt.failureOccurred)
+		if got != tt.want {
+			t.Errorf("newRemappedRows(%d, %d, %d, %d) = %+v, want %+v", tt.corrRows, tt.uncRows, tt.isPending,
+				tt.failureOccurred, got, tt.want)
+		}
+	}
+}
+
+func TestReturnCodes(t *testing.T) {
+	// the values of nvmlReturn_t in nvml/nvml.h
+	tests := []struct {
+		name string
+		code NvmlRetType
+		want NvmlRetType
+	}{
+		{name: "ErrorAlreadyInitialized", code: ErrorAlreadyInitialized, want: 5},
+		{name: "ErrorNotFound", code: ErrorNotFound, want: 6},
+		{name: "ErrorInsufficientSize", code: ErrorInsufficientSize, want: 7},
+		{name: "ErrorTimeout", code: ErrorTimeout, want: 10},
+		{name: "ErrorLibraryNotFound", code: ErrorLibraryNotFound, want: 12},
+		{name: "ErrorFunctionNotFound", code: ErrorFunctionNotFound, want: 13},
+		{name: "ErrorGpuIsLost", code: ErrorGpuIsLost, want: 15},
+		{name: "ErrorUnknown", code: ErrorUnknown, want: 999},
+	}
+	for _, tt := range tests {
+		if tt.code != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, tt.code, tt.want)
+		}
+	}
+}
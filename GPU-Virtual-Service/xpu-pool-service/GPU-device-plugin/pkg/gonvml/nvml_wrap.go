@@ -53,6 +53,27 @@ typedef nvmlReturn_t (*NvmlSystemGetDriverVersionFunc)(char *version, unsigned i
 typedef nvmlReturn_t (*NvmlSystemGetCudaDriverVersionFunc)(int *cudaDriverVersion);
 typedef nvmlReturn_t (*NvmlDeviceGetTemperatureFunc)(nvmlDevice_t device, nvmlTemperatureSensors_t sensorType, unsigned int *temp);
 typedef nvmlReturn_t (*NvmlDeviceGetPowerUsageFunc)(nvmlDevice_t device, unsigned int *power);
+typedef nvmlReturn_t (*NvmlDeviceGetTotalEccErrorsFunc)(nvmlDevice_t device, nvmlMemoryErrorType_t errorType, nvmlEccCounterType_t counterType, unsigned long long *eccCounts);
+typedef nvmlReturn_t (*NvmlDeviceGetClockInfoFunc)(nvmlDevice_t device, nvmlClockType_t type, unsigned int *clock);
+typedef nvmlReturn_t (*NvmlDeviceGetCurrentClocksThrottleReasonsFunc)(nvmlDevice_t device, unsigned long long *clocksThrottleReasons);
+typedef nvmlReturn_t (*NvmlDeviceGetPcieThroughputFunc)(nvmlDevice_t device, nvmlPcieUtilCounter_t counter, unsigned int *value);
+typedef nvmlReturn_t (*NvmlDeviceGetPcieReplayCounterFunc)(nvmlDevice_t device, unsigned int *value);
+typedef nvmlReturn_t (*NvmlDeviceGetNvLinkStateFunc)(nvmlDevice_t device, unsigned int link, nvmlEnableState_t *isActive);
+typedef nvmlReturn_t (*NvmlDeviceGetNvLinkErrorCounterFunc)(nvmlDevice_t device, unsigned int link, nvmlNvLinkErrorCounter_t counter, unsigned long long *counterValue);
+typedef nvmlReturn_t (*NvmlDeviceGetRetiredPagesFunc)(nvmlDevice_t device, nvmlPageRetirementCause_t cause, unsigned int *pageCount, unsigned long long *addresses);
+typedef nvmlReturn_t (*NvmlDeviceGetRetiredPagesPendingStatusFunc)(nvmlDevice_t device, nvmlEnableState_t *isPending);
+typedef nvmlReturn_t (*NvmlDeviceGetRemappedRowsFunc)(nvmlDevice_t device, unsigned int *corrRows, unsigned int *uncRows, unsigned int *isPending, unsigned int *failureOccurred);
+typedef nvmlReturn_t (*NvmlDeviceGetFanSpeedFunc)(nvmlDevice_t device, unsigned int *speed);
+typedef nvmlReturn_t (*NvmlDeviceGetEncoderUtilizationFunc)(nvmlDevice_t device, unsigned int *utilization, unsigned int *samplingPeriodUs);
+typedef nvmlReturn_t (*NvmlDeviceGetDecoderUtilizationFunc)(nvmlDevice_t device, unsigned int *utilization, unsigned int *samplingPeriodUs);
+typedef nvmlReturn_t (*NvmlDeviceGetMigModeFunc)(nvmlDevice_t device, unsigned int *currentMode, unsigned int *pendingMode);
+typedef nvmlReturn_t (*NvmlDeviceGetMaxMigDeviceCountFunc)(nvmlDevice_t device, unsigned int *count);
+typedef nvmlReturn_t (*NvmlDeviceGetMigDeviceHandleByIndexFunc)(nvmlDevice_t device, unsigned int index, nvmlDevice_t *migDevice);
+typedef nvmlReturn_t (*NvmlDeviceGetDeviceHandleFromMigDeviceHandleFunc)(nvmlDevice_t migDevice, nvmlDevice_t *device);
+typedef nvmlReturn_t (*NvmlDeviceIsMigDeviceHandleFunc)(nvmlDevice_t device, unsigned int *isMigDevice);
+typedef nvmlReturn_t (*NvmlDeviceGetGpuInstanceIdFunc)(nvmlDevice_t device, unsigned int *id);
+typedef nvmlReturn_t (*NvmlDeviceGetComputeInstanceIdFunc)(nvmlDevice_t device, unsigned int *id);
+typedef nvmlReturn_t (*NvmlDeviceGetAttributesV2Func)(nvmlDevice_t device, nvmlDeviceAttributes_t *attributes);
 
 NvmlInitFunc nvmlInitFunc = NULL;
 NvmlInitWithFlagsFunc nvmlInitWithFlagsFunc = NULL;
@@ -79,6 +100,27 @@ NvmlSystemGetDriverVersionFunc nvmlSystemGetDriverVersionFunc = NULL;
 NvmlSystemGetCudaDriverVersionFunc nvmlSystemGetCudaDriverVersionFunc = NULL;
 NvmlDeviceGetTemperatureFunc nvmlDeviceGetTemperatureFunc = NULL;
 NvmlDeviceGetPowerUsageFunc nvmlDeviceGetPowerUsageFunc = NULL;
+NvmlDeviceGetTotalEccErrorsFunc nvmlDeviceGetTotalEccErrorsFunc = NULL;
+NvmlDeviceGetClockInfoFunc nvmlDeviceGetClockInfoFunc = NULL;
+NvmlDeviceGetCurrentClocksThrottleReasonsFunc nvmlDeviceGetCurrentClocksThrottleReasonsFunc = NULL;
+NvmlDeviceGetPcieThroughputFunc nvmlDeviceGetPcieThroughputFunc = NULL;
+NvmlDeviceGetPcieReplayCounterFunc nvmlDeviceGetPcieReplayCounterFunc = NULL;
+NvmlDeviceGetNvLinkStateFunc nvmlDeviceGetNvLinkStateFunc = NULL;
+NvmlDeviceGetNvLinkErrorCounterFunc nvmlDeviceGetNvLinkErrorCounterFunc = NULL;
+NvmlDeviceGetRetiredPagesFunc nvmlDeviceGetRetiredPagesFunc = NULL;
+NvmlDeviceGetRetiredPagesPendingStatusFunc nvmlDeviceGetRetiredPagesPendingStatusFunc = NULL;
+NvmlDeviceGetRemappedRowsFunc nvmlDeviceGetRemappedRowsFunc = NULL;
+NvmlDeviceGetFanSpeedFunc nvmlDeviceGetFanSpeedFunc = NULL;
+NvmlDeviceGetEncoderUtilizationFunc nvmlDeviceGetEncoderUtilizationFunc = NULL;
+NvmlDeviceGetDecoderUtilizationFunc nvmlDeviceGetDecoderUtilizationFunc = NULL;
+NvmlDeviceGetMigModeFunc nvmlDeviceGetMigModeFunc = NULL;
+NvmlDeviceGetMaxMigDeviceCountFunc nvmlDeviceGetMaxMigDeviceCountFunc = NULL;
+NvmlDeviceGetMigDeviceHandleByIndexFunc nvmlDeviceGetMigDeviceHandleByIndexFunc = NULL;
+NvmlDeviceGetDeviceHandleFromMigDeviceHandleFunc nvmlDeviceGetDeviceHandleFromMigDeviceHandleFunc = NULL;
+NvmlDeviceIsMigDeviceHandleFunc nvmlDeviceIsMigDeviceHandleFunc = NULL;
+NvmlDeviceGetGpuInstanceIdFunc nvmlDeviceGetGpuInstanceIdFunc = NULL;
+NvmlDeviceGetComputeInstanceIdFunc nvmlDeviceGetComputeInstanceIdFunc = NULL;
+NvmlDeviceGetAttributesV2Func nvmlDeviceGetAttributesV2Func = NULL;
 
 // In order not to depend on libnvidia-ml.so.1, the custom function is implemented as follows:
 nvmlReturn_t nvmlInit(void) {
@@ -181,6 +223,90 @@ nvmlReturn_t nvmlDeviceGetTopologyNearestGpus(nvmlDevice_t device, nvmlGpuTopolo
     return (nvmlDeviceGetTopologyNearestGpusFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetTopologyNearestGpusFunc(device, level, count, deviceArray);
 }
 
+nvmlReturn_t nvmlDeviceGetTotalEccErrors(nvmlDevice_t device, nvmlMemoryErrorType_t errorType, nvmlEccCounterType_t counterType, unsigned long long *eccCounts) {
+    return (nvmlDeviceGetTotalEccErrorsFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetTotalEccErrorsFunc(device, errorType, counterType, eccCounts);
+}
+
+nvmlReturn_t nvmlDeviceGetClockInfo(nvmlDevice_t device, nvmlClockType_t type, unsigned int *clock) {
+    return (nvmlDeviceGetClockInfoFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetClockInfoFunc(device, type, clock);
+}
+
+nvmlReturn_t nvmlDeviceGetCurrentClocksThrottleReasons(nvmlDevice_t device, unsigned long long *clocksThrottleReasons) {
+    return (nvmlDeviceGetCurrentClocksThrottleReasonsFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetCurrentClocksThrottleReasonsFunc(device, clocksThrottleReasons);
+}
+
+nvmlReturn_t nvmlDeviceGetPcieThroughput(nvmlDevice_t device, nvmlPcieUtilCounter_t counter, unsigned int *value) {
+    return (nvmlDeviceGetPcieThroughputFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetPcieThroughputFunc(device, counter, value);
+}
+
+nvmlReturn_t nvmlDeviceGetPcieReplayCounter(nvmlDevice_t device, unsigned int *value) {
+    return (nvmlDeviceGetPcieReplayCounterFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetPcieReplayCounterFunc(device, value);
+}
+
+nvmlReturn_t nvmlDeviceGetNvLinkState(nvmlDevice_t device, unsigned int link, nvmlEnableState_t *isActive) {
+    return (nvmlDeviceGetNvLinkStateFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetNvLinkStateFunc(device, link, isActive);
+}
+
+nvmlReturn_t nvmlDeviceGetNvLinkErrorCounter(nvmlDevice_t device, unsigned int link, nvmlNvLinkErrorCounter_t counter, unsigned long long *counterValue) {
+    return (nvmlDeviceGetNvLinkErrorCounterFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetNvLinkErrorCounterFunc(device, link, counter, counterValue);
+}
+
+nvmlReturn_t nvmlDeviceGetRetiredPages(nvmlDevice_t device, nvmlPageRetirementCause_t cause, unsigned int *pageCount, unsigned long long *addresses) {
+    return (nvmlDeviceGetRetiredPagesFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetRetiredPagesFunc(device, cause, pageCount, addresses);
+}
+
+nvmlReturn_t nvmlDeviceGetRetiredPagesPendingStatus(nvmlDevice_t device, nvmlEnableState_t *isPending) {
+    return (nvmlDeviceGetRetiredPagesPendingStatusFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetRetiredPagesPendingStatusFunc(device, isPending);
+}
+
+nvmlReturn_t nvmlDeviceGetRemappedRows(nvmlDevice_t device, unsigned int *corrRows, unsigned int *uncRows, unsigned int *isPending, unsigned int *failureOccurred) {
+    return (nvmlDeviceGetRemappedRowsFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetRemappedRowsFunc(device, corrRows, uncRows, isPending, failureOccurred);
+}
+
+nvmlReturn_t nvmlDeviceGetFanSpeed(nvmlDevice_t device, unsigned int *speed) {
+    return (nvmlDeviceGetFanSpeedFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetFanSpeedFunc(device, speed);
+}
+
+nvmlReturn_t nvmlDeviceGetEncoderUtilization(nvmlDevice_t device, unsigned int *utilization, unsigned int *samplingPeriodUs) {
+    return (nvmlDeviceGetEncoderUtilizationFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetEncoderUtilizationFunc(device, utilization, samplingPeriodUs);
+}
+
+nvmlReturn_t nvmlDeviceGetDecoderUtilization(nvmlDevice_t device, unsigned int *utilization, unsigned int *samplingPeriodUs) {
+    return (nvmlDeviceGetDecoderUtilizationFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetDecoderUtilizationFunc(device, utilization, samplingPeriodUs);
+}
+
+nvmlReturn_t nvmlDeviceGetMigMode(nvmlDevice_t device, unsigned int *currentMode, unsigned int *pendingMode) {
+    return (nvmlDeviceGetMigModeFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetMigModeFunc(device, currentMode, pendingMode);
+}
+
+nvmlReturn_t nvmlDeviceGetMaxMigDeviceCount(nvmlDevice_t device, unsigned int *count) {
+    return (nvmlDeviceGetMaxMigDeviceCountFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetMaxMigDeviceCountFunc(device, count);
+}
+
+nvmlReturn_t nvmlDeviceGetMigDeviceHandleByIndex(nvmlDevice_t device, unsigned int index, nvmlDevice_t *migDevice) {
+    return (nvmlDeviceGetMigDeviceHandleByIndexFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetMigDeviceHandleByIndexFunc(device, index, migDevice);
+}
+
+nvmlReturn_t nvmlDeviceGetDeviceHandleFromMigDeviceHandle(nvmlDevice_t migDevice, nvmlDevice_t *device) {
+    return (nvmlDeviceGetDeviceHandleFromMigDeviceHandleFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetDeviceHandleFromMigDeviceHandleFunc(migDevice, device);
+}
+
+nvmlReturn_t nvmlDeviceIsMigDeviceHandle(nvmlDevice_t device, unsigned int *isMigDevice) {
+    return (nvmlDeviceIsMigDeviceHandleFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceIsMigDeviceHandleFunc(device, isMigDevice);
+}
+
+nvmlReturn_t nvmlDeviceGetGpuInstanceId(nvmlDevice_t device, unsigned int *id) {
+    return (nvmlDeviceGetGpuInstanceIdFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetGpuInstanceIdFunc(device, id);
+}
+
+nvmlReturn_t nvmlDeviceGetComputeInstanceId(nvmlDevice_t device, unsigned int *id) {
+    return (nvmlDeviceGetComputeInstanceIdFunc == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetComputeInstanceIdFunc(device, id);
+}
+
+nvmlReturn_t nvmlDeviceGetAttributes_v2Hook(nvmlDevice_t device, nvmlDeviceAttributes_t *attributes) {
+    return (nvmlDeviceGetAttributesV2Func == NULL) ? NVML_ERROR_FUNCTION_NOT_FOUND : nvmlDeviceGetAttributesV2Func(device, attributes);
+}
+
 // Helper function to load a symbol and handle errors.
 static void loadSymbol(const char *symbolName, void **symbolPtr) {
     *symbolPtr = dlsym(nvmlHandle, symbolName);
@@ -222,6 +348,27 @@ nvmlReturn_t loadDlFunction(void) {
     loadSymbol("nvmlSystemGetCudaDriverVersion", (void**)(&nvmlSystemGetCudaDriverVersionFunc));
     loadSymbol("nvmlDeviceGetTemperature", (void**)(&nvmlDeviceGetTemperatureFunc));
     loadSymbol("nvmlDeviceGetPowerUsage", (void**)(&nvmlDeviceGetPowerUsageFunc));
+    loadSymbol("nvmlDeviceGetTotalEccErrors", (void**)(&nvmlDeviceGetTotalEccErrorsFunc));
+    loadSymbol("nvmlDeviceGetClockInfo", (void**)(&nvmlDeviceGetClockInfoFunc));
+    loadSymbol("nvmlDeviceGetCurrentClocksThrottleReasons", (void**)(&nvmlDeviceGetCurrentClocksThrottleReasonsFunc));
+    loadSymbol("nvmlDeviceGetPcieThroughput", (void**)(&nvmlDeviceGetPcieThroughputFunc));
+    loadSymbol("nvmlDeviceGetPcieReplayCounter", (void**)(&nvmlDeviceGetPcieReplayCounterFunc));
+    loadSymbol("nvmlDeviceGetNvLinkState", (void**)(&nvmlDeviceGetNvLinkStateFunc));
+    loadSymbol("nvmlDeviceGetNvLinkErrorCounter", (void**)(&nvmlDeviceGetNvLinkErrorCounterFunc));
+    loadSymbol("nvmlDeviceGetRetiredPages", (void**)(&nvmlDeviceGetRetiredPagesFunc));
+    loadSymbol("nvmlDeviceGetRetiredPagesPendingStatus", (void**)(&nvmlDeviceGetRetiredPagesPendingStatusFunc));
+    loadSymbol("nvmlDeviceGetRemappedRows", (void**)(&nvmlDeviceGetRemappedRowsFunc));
+    loadSymbol("nvmlDeviceGetFanSpeed", (void**)(&nvmlDeviceGetFanSpeedFunc));
+    loadSymbol("nvmlDeviceGetEncoderUtilization", (void**)(&nvmlDeviceGetEncoderUtilizationFunc));
+    loadSymbol("nvmlDeviceGetDecoderUtilization", (void**)(&nvmlDeviceGetDecoderUtilizationFunc));
+    loadSymbol("nvmlDeviceGetMigMode", (void**)(&nvmlDeviceGetMigModeFunc));
+    loadSymbol("nvmlDeviceGetMaxMigDeviceCount", (void**)(&nvmlDeviceGetMaxMigDeviceCountFunc));
+    loadSymbol("nvmlDeviceGetMigDeviceHandleByIndex", (void**)(&nvmlDeviceGetMigDeviceHandleByIndexFunc));
+    loadSymbol("nvmlDeviceGetDeviceHandleFromMigDeviceHandle", (void**)(&nvmlDeviceGetDeviceHandleFromMigDeviceHandleFunc));
+    loadSymbol("nvmlDeviceIsMigDeviceHandle", (void**)(&nvmlDeviceIsMigDeviceHandleFunc));
+    loadSymbol("nvmlDeviceGetGpuInstanceId", (void**)(&nvmlDeviceGetGpuInstanceIdFunc));
+    loadSymbol("nvmlDeviceGetComputeInstanceId", (void**)(&nvmlDeviceGetComputeInstanceIdFunc));
+    loadSymbol("nvmlDeviceGetAttributes_v2", (void**)(&nvmlDeviceGetAttributesV2Func));
 
     fprintf(stdout, "Load libnvidia-ml.so.1 success!");
     return NVML_SUCCESS;
@@ -440,3 +587,145 @@ func nvmlDeviceGetPowerUsageWrapper(nvmlDevice nvmlDevice, power *uint32) NvmlRe
 	cpower, _ := (*C.uint)(unsafe.Pointer(power)), cgoAllocsUnknown
 	return NvmlRetType(C.nvmlDeviceGetPowerUsage(cnvmlDevice, cpower))
 }
+
+func nvmlDeviceGetTotalEccErrorsWrapper(nvmlDevice nvmlDevice, ErrorType MemoryErrorType, CounterType EccCounterType, EccCounts *uint64) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cErrorType, _ := (C.nvmlMemoryErrorType_t)(ErrorType), cgoAllocsUnknown
+	cCounterType, _ := (C.nvmlEccCounterType_t)(CounterType), cgoAllocsUnknown
+	cEccCounts, _ := (*C.ulonglong)(unsafe.Pointer(EccCounts)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetTotalEccErrors(cnvmlDevice, cErrorType, cCounterType, cEccCounts))
+}
+
+func nvmlDeviceGetClockInfoWrapper(nvmlDevice nvmlDevice, Type ClockType, Clock *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cType, _ := (C.nvmlClockType_t)(Type), cgoAllocsUnknown
+	cClock, _ := (*C.uint)(unsafe.Pointer(Clock)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetClockInfo(cnvmlDevice, cType, cClock))
+}
+
+func nvmlDeviceGetCurrentClocksThrottleReasonsWrapper(nvmlDevice nvmlDevice, ClocksThrottleReasons *uint64) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cClocksThrottleReasons, _ := (*C.ulonglong)(unsafe.Pointer(ClocksThrottleReasons)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetCurrentClocksThrottleReasons(cnvmlDevice, cClocksThrottleReasons))
+}
+
+func nvmlDeviceGetPcieThroughputWrapper(nvmlDevice nvmlDevice, Counter PcieUtilCounter, Value *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cCounter, _ := (C.nvmlPcieUtilCounter_t)(Counter), cgoAllocsUnknown
+	cValue, _ := (*C.uint)(unsafe.Pointer(Value)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetPcieThroughput(cnvmlDevice, cCounter, cValue))
+}
+
+func nvmlDeviceGetPcieReplayCounterWrapper(nvmlDevice nvmlDevice, Value *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cValue, _ := (*C.uint)(unsafe.Pointer(Value)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetPcieReplayCounter(cnvmlDevice, cValue))
+}
+
+func nvmlDeviceGetNvLinkStateWrapper(nvmlDevice nvmlDevice, Link uint32, IsActive *EnableState) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cLink, _ := (C.uint)(Link), cgoAllocsUnknown
+	cIsActive, _ := (*C.nvmlEnableState_t)(unsafe.Pointer(IsActive)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetNvLinkState(cnvmlDevice, cLink, cIsActive))
+}
+
+func nvmlDeviceGetNvLinkErrorCounterWrapper(nvmlDevice nvmlDevice, Link uint32, Counter NvLinkErrorCounter, CounterValue *uint64) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cLink, _ := (C.uint)(Link), cgoAllocsUnknown
+	cCounter, _ := (C.nvmlNvLinkErrorCounter_t)(Counter), cgoAllocsUnknown
+	cCounterValue, _ := (*C.ulonglong)(unsafe.Pointer(CounterValue)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetNvLinkErrorCounter(cnvmlDevice, cLink, cCounter, cCounterValue))
+}
+
+func nvmlDeviceGetRetiredPagesWrapper(nvmlDevice nvmlDevice, Cause PageRetirementCause, PageCount *uint32, Addresses *uint64) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cCause, _ := (C.nvmlPageRetirementCause_t)(Cause), cgoAllocsUnknown
+	cPageCount, _ := (*C.uint)(unsafe.Pointer(PageCount)), cgoAllocsUnknown
+	cAddresses, _ := (*C.ulonglong)(unsafe.Pointer(Addresses)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetRetiredPages(cnvmlDevice, cCause, cPageCount, cAddresses))
+}
+
+func nvmlDeviceGetRetiredPagesPendingStatusWrapper(nvmlDevice nvmlDevice, IsPending *EnableState) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cIsPending, _ := (*C.nvmlEnableState_t)(unsafe.Pointer(IsPending)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetRetiredPagesPendingStatus(cnvmlDevice, cIsPending))
+}
+
+func nvmlDeviceGetRemappedRowsWrapper(nvmlDevice nvmlDevice, CorrRows *uint32, UncRows *uint32, IsPending *uint32, FailureOccurred *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cCorrRows, _ := (*C.uint)(unsafe.Pointer(CorrRows)), cgoAllocsUnknown
+	cUncRows, _ := (*C.uint)(unsafe.Pointer(UncRows)), cgoAllocsUnknown
+	cIsPending, _ := (*C.uint)(unsafe.Pointer(IsPending)), cgoAllocsUnknown
+	cFailureOccurred, _ := (*C.uint)(unsafe.Pointer(FailureOccurred)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetRemappedRows(cnvmlDevice, cCorrRows, cUncRows, cIsPending, cFailureOccurred))
+}
+
+func nvmlDeviceGetFanSpeedWrapper(nvmlDevice nvmlDevice, Speed *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cSpeed, _ := (*C.uint)(unsafe.Pointer(Speed)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetFanSpeed(cnvmlDevice, cSpeed))
+}
+
+func nvmlDeviceGetEncoderUtilizationWrapper(nvmlDevice nvmlDevice, Utilization *uint32, SamplingPeriodUs *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cUtilization, _ := (*C.uint)(unsafe.Pointer(Utilization)), cgoAllocsUnknown
+	cSamplingPeriodUs, _ := (*C.uint)(unsafe.Pointer(SamplingPeriodUs)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetEncoderUtilization(cnvmlDevice, cUtilization, cSamplingPeriodUs))
+}
+
+func nvmlDeviceGetDecoderUtilizationWrapper(nvmlDevice nvmlDevice, Utilization *uint32, SamplingPeriodUs *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cUtilization, _ := (*C.uint)(unsafe.Pointer(Utilization)), cgoAllocsUnknown
+	cSamplingPeriodUs, _ := (*C.uint)(unsafe.Pointer(SamplingPeriodUs)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetDecoderUtilization(cnvmlDevice, cUtilization, cSamplingPeriodUs))
+}
+
+func nvmlDeviceGetMigModeWrapper(nvmlDevice nvmlDevice, CurrentMode *uint32, PendingMode *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cCurrentMode, _ := (*C.uint)(unsafe.Pointer(CurrentMode)), cgoAllocsUnknown
+	cPendingMode, _ := (*C.uint)(unsafe.Pointer(PendingMode)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetMigMode(cnvmlDevice, cCurrentMode, cPendingMode))
+}
+
+func nvmlDeviceGetMaxMigDeviceCountWrapper(nvmlDevice nvmlDevice, Count *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cCount, _ := (*C.uint)(unsafe.Pointer(Count)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetMaxMigDeviceCount(cnvmlDevice, cCount))
+}
+
+func nvmlDeviceGetMigDeviceHandleByIndexWrapper(nvmlDevice nvmlDevice, Index uint32, MigDevice *nvmlDevice) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cIndex, _ := (C.uint)(Index), cgoAllocsUnknown
+	cMigDevice, _ := (*C.nvmlDevice_t)(unsafe.Pointer(MigDevice)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetMigDeviceHandleByIndex(cnvmlDevice, cIndex, cMigDevice))
+}
+
+func nvmlDeviceGetDeviceHandleFromMigDeviceHandleWrapper(MigDevice nvmlDevice, nvmlDevice *nvmlDevice) NvmlRetType {
+	cMigDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&MigDevice)), cgoAllocsUnknown
+	cnvmlDevice, _ := (*C.nvmlDevice_t)(unsafe.Pointer(nvmlDevice)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetDeviceHandleFromMigDeviceHandle(cMigDevice, cnvmlDevice))
+}
+
+func nvmlDeviceIsMigDeviceHandleWrapper(nvmlDevice nvmlDevice, IsMigDevice *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cIsMigDevice, _ := (*C.uint)(unsafe.Pointer(IsMigDevice)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceIsMigDeviceHandle(cnvmlDevice, cIsMigDevice))
+}
+
+func nvmlDeviceGetGpuInstanceIdWrapper(nvmlDevice nvmlDevice, Id *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cId, _ := (*C.uint)(unsafe.Pointer(Id)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetGpuInstanceId(cnvmlDevice, cId))
+}
+
+func nvmlDeviceGetComputeInstanceIdWrapper(nvmlDevice nvmlDevice, Id *uint32) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cId, _ := (*C.uint)(unsafe.Pointer(Id)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetComputeInstanceId(cnvmlDevice, cId))
+}
+
+func nvmlDeviceGetAttributesWrapper(nvmlDevice nvmlDevice, Attributes *DeviceAttributes) NvmlRetType {
+	cnvmlDevice, _ := *(*C.nvmlDevice_t)(unsafe.Pointer(&nvmlDevice)), cgoAllocsUnknown
+	cAttributes, _ := (*C.nvmlDeviceAttributes_t)(unsafe.Pointer(Attributes)), cgoAllocsUnknown
+	return NvmlRetType(C.nvmlDeviceGetAttributes_v2Hook(cnvmlDevice, cAttributes))
+}
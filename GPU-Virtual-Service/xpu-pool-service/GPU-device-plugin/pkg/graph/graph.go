@@ -5,6 +5,7 @@
 package graph
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -57,3 +58,26 @@ func (graph TopologyGraph) Serializer() string {
 	}
 	return strings.Join(result, ";")
 }
+
+// Deserialize parses a string produced by Serializer back into a square graph.
+func Deserialize(str string) (TopologyGraph, error) {
+	if str == "" {
+		return TopologyGraph{}, nil
+	}
+	rows := strings.Split(str, ";")
+	graph := NewTopologyGraph(len(rows))
+	for i, row := range rows {
+		tokens := strings.Split(row, ",")
+		if len(tokens) != len(rows) {
+			return nil, fmt.Errorf("row %d has %d items, expected %d", i, len(tokens), len(rows))
+		}
+		for j, token := range tokens {
+			v, err := strconv.Atoi(token)
+			if err != nil {
+				return nil, fmt.Errorf("row %d item %d: %v", i, j, err)
+			}
+			graph[i][j] = v
+		}
+	}
+	return graph, nil
+}
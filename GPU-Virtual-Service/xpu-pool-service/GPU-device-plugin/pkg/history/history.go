@@ -0,0 +1,202 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package history keeps the recent usage samples of xpus and vxpus in memory
+package history
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"huawei.com/vxpu-device-plugin/pkg/plugin/types"
+)
+
+const (
+	// XpuType type of the usage history of a physical xpu
+	XpuType = "xpu"
+	// VxpuType type of the usage history of a vxpu
+	VxpuType = "vxpu"
+
+	percentile95 = 0.95
+)
+
+type series struct {
+	meta    types.UsageHistory
+	samples []types.UsageSample
+	head    int
+	size    int
+}
+
+func newSeries(meta types.UsageHistory, capacity int) *series {
+	return &series{meta: meta, samples: make([]types.UsageSample, capacity)}
+}
+
+func (s *series) add(sample types.UsageSample) {
+	s.samples[(s.head+s.size)%len(s.samples)] = sample
+	if s.size < len(s.samples) {
+		s.size++
+		return
+	}
+	s.head = (s.head + 1) % len(s.samples)
+}
+
+func (s *series) last() types.UsageSample {
+	return s.samples[(s.head+s.size-1)%len(s.samples)]
+}
+
+// between returns the samples whose timestamp is in [start, end] in time order
+func (s *series) between(start, end int64) []types.UsageSample {
+	res := make([]types.UsageSample, 0)
+	for i := 0; i < s.size; i++ {
+		sample := s.samples[(s.head+i)%len(s.samples)]
+		if sample.Timestamp >= start && sample.Timestamp <= end {
+			res = append(res, sample)
+		}
+	}
+	return res
+}
+
+// Recorder records usage samples of xpus and vxpus into bounded ring buffers
+type Recorder struct {
+	mu        sync.RWMutex
+	retention time.Duration
+	capacity  int
+	series    map[string]*series
+}
+
+// NewRecorder returns a recorder keeping samples taken every interval for the retention
+func NewRecorder(retention, interval time.Duration) *Recorder {
+	capacity := 1
+	if interval > 0 && retention > interval {
+		capacity = int(retention / interval)
+	}
+	return &Recorder{
+		retention: retention,
+		capacity:  capacity,
+		series:    make(map[string]*series),
+	}
+}
+
+// seriesKey returns the key of the series, the vxpu ids are reused after the pods are released,
+// so the series of vxpus are keyed by the pod uid as well
+func seriesKey(meta types.UsageHistory) string {
+	if meta.PodUID == "" {
+		return meta.Id
+	}
+	return meta.PodUID + "/" + meta.Id
+}
+
+func (r *Recorder) record(meta types.UsageHistory, sample types.UsageSample) {
+	key := seriesKey(meta)
+	s, ok := r.series[key]
+	if !ok {
+		s = newSeries(meta, r.capacity)
+		r.series[key] = s
+	}
+	s.meta = meta
+	s.add(sample)
+}
+
+// Record records the usage of all xpus and their vxpus sampled at ts
+func (r *Recorder) Record(ts time.Time, xpuDevices map[string]*types.XPUDevice) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	timestamp := ts.Unix()
+	for _, device := range xpuDevices {
+		r.record(types.UsageHistory{Id: device.Id, GpuId: device.Id, Type: XpuType}, types.UsageSample{
+			Timestamp:         timestamp,
+			CoreUtilization:   device.XpuUtilization,
+			MemoryUsed:        device.MemoryUsed,
+			MemoryUtilization: device.MemoryUtilization,
+			PowerUsage:        device.PowerUsage,
+			Temperature:       device.Temperature,
+		})
+		for _, v := range device.VxpuDeviceList {
+			meta := types.UsageHistory{
+				Id:            v.Id,
+				GpuId:         v.GpuId,
+				Type:          VxpuType,
+				PodUID:        v.PodUID,
+				ContainerName: v.ContainerName,
+			}
+			r.record(meta, types.UsageSample{
+				Timestamp:         timestamp,
+				CoreUtilization:   v.VxpuCoreUtilization,
+				MemoryUsed:        v.VxpuMemoryUsed,
+				MemoryUtilization: v.VxpuMemoryUtilization,
+				PowerUsage:        device.PowerUsage,
+				Temperature:       device.Temperature,
+			})
+		}
+	}
+
+	// drop the series of vxpus which have been released longer than the retention
+	expired := ts.Add(-r.retention).Unix()
+	for key, s := range r.series {
+		if s.last().Timestamp < expired {
+			delete(r.series, key)
+		}
+	}
+}
+
+// Query returns the usage history in [start, end] of the xpu or vxpu with the id,
+// all xpus and vxpus are returned if the id is empty
+func (r *Recorder) Query(start, end int64, id string) []types.UsageHistory {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]types.UsageHistory, 0)
+	for _, s := range r.series {
+		if id != "" && s.meta.Id != id {
+			continue
+		}
+		samples := s.between(start, end)
+		if len(samples) == 0 {
+			continue
+		}
+		h := s.meta
+		h.Samples = samples
+		h.CoreUtilization = aggregate(samples, func(s types.UsageSample) float64 { return s.CoreUtilization })
+		h.MemoryUsed = aggregate(samples, func(s types.UsageSample) float64 { return float64(s.MemoryUsed) })
+		h.MemoryUtilization = aggregate(samples, func(s types.UsageSample) float64 { return s.MemoryUtilization })
+		h.PowerUsage = aggregate(samples, func(s types.UsageSample) float64 { return float64(s.PowerUsage) })
+		h.Temperature = aggregate(samples, func(s types.UsageSample) float64 { return float64(s.Temperature) })
+		res = append(res, h)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].GpuId != res[j].GpuId {
+			return res[i].GpuId < res[j].GpuId
+		}
+		if res[i].Id != res[j].Id {
+			return res[i].Id < res[j].Id
+		}
+		return res[i].PodUID < res[j].PodUID
+	})
+	return res
+}
+
+func aggregate(samples []types.UsageSample, value func(types.UsageSample) float64) types.UsageStatistics {
+	values := make([]float64, 0, len(samples))
+	sum := 0.0
+	for _, s := range samples {
+		v := value(s)
+		values = append(values, v)
+		sum += v
+	}
+	sort.Float64s(values)
+	// nearest-rank percentile
+	rank := int(math.Ceil(percentile95*float64(len(values)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return types.UsageStatistics{
+		Min: values[0],
+		Avg: sum / float64(len(values)),
+		Max: values[len(values)-1],
+		P95: values[rank],
+	}
+}
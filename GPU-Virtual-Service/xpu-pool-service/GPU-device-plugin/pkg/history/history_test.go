@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"huawei.com/vxpu-device-plugin/pkg/plugin/types"
+)
+
+const (
+	testGpuId  = "GPU-0"
+	testVxpuId = "GPU-0-0"
+	testPodUID = "pod-a"
+)
+
+var testStart = time.Unix(1000, 0)
+
+func newTestDevices(core float64, memoryUsed uint64, vxpus ...types.VxpuDevice) map[string]*types.XPUDevice {
+	return map[string]*types.XPUDevice{
+		testGpuId: {
+			Id:             testGpuId,
+			XpuUtilization: core,
+			MemoryUsed:     memoryUsed,
+			PowerUsage:     100,
+			Temperature:    50,
+			VxpuDeviceList: vxpus,
+		},
+	}
+}
+
+func newTestVxpu(podUID string, core float64) types.VxpuDevice {
+	return types.VxpuDevice{
+		Id:                  testVxpuId,
+		GpuId:               testGpuId,
+		PodUID:              podUID,
+		ContainerName:       "main",
+		VxpuCoreUtilization: core,
+	}
+}
+
+func timestamps(samples []types.UsageSample) []int64 {
+	res := make([]int64, 0, len(samples))
+	for _, s := range samples {
+		res = append(res, s.Timestamp)
+	}
+	return res
+}
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention time.Duration
+		interval  time.Duration
+		records   int
+		query     string
+		want      []int64
+	}{
+		{name: "below capacity", retention: 5 * time.Second, interval: time.Second, records: 3, query: testGpuId,
+			want: []int64{1000, 1001, 1002}},
+		{name: "ring buffer drops the oldest", retention: 3 * time.Second, interval: time.Second, records: 5,
+			query: testGpuId, want: []int64{1002, 1003, 1004}},
+		{name: "retention shorter than interval keeps one", retention: time.Second, interval: time.Minute,
+			records: 3, query: testGpuId, want: []int64{1002}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRecorder(tt.retention, tt.interval)
+			for i := 0; i < tt.records; i++ {
+				r.Record(testStart.Add(time.Duration(i)*time.Second), newTestDevices(float64(i), 0))
+			}
+			res := r.Query(0, testStart.Add(time.Hour).Unix(), tt.query)
+			if len(res) != 1 {
+				t.Fatalf("Query returned %d histories, want 1", len(res))
+			}
+			if got := timestamps(res[0].Samples); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("samples = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordExpiresReleasedVxpus(t *testing.T) {
+	r := NewRecorder(10*time.Second, time.Second)
+	r.Record(testStart, newTestDevices(0, 0, newTestVxpu(testPodUID, 10)))
+	r.Record(testStart.Add(5*time.Second), newTestDevices(0, 0))
+	if res := r.Query(0, testStart.Add(time.Hour).Unix(), testVxpuId); len(res) != 1 {
+		t.Fatalf("released vxpu should be kept within the retention, got %d histories", len(res))
+	}
+	r.Record(testStart.Add(11*time.Second), newTestDevices(0, 0))
+	if res := r.Query(0, testStart.Add(time.Hour).Unix(), testVxpuId); len(res) != 0 {
+		t.Errorf("released vxpu should expire after the retention, got %d histories", len(res))
+	}
+}
+
+func TestRecordKeysVxpuByPod(t *testing.T) {
+	r := NewRecorder(time.Minute, time.Second)
+	r.Record(testStart, newTestDevices(0, 0, newTestVxpu("pod-a", 10)))
+	// the vxpu id is reused by another pod after pod-a is released
+	r.Record(testStart.Add(time.Second), newTestDevices(0, 0, newTestVxpu("pod-b", 20)))
+
+	res := r.Query(0, testStart.Add(time.Hour).Unix(), testVxpuId)
+	if len(res) != 2 {
+		t.Fatalf("Query returned %d histories, want 2", len(res))
+	}
+	for i, want := range []struct {
+		podUID string
+		core   float64
+	}{{"pod-a", 10}, {"pod-b", 20}} {
+		if res[i].PodUID != want.podUID || len(res[i].Samples) != 1 ||
+			res[i].Samples[0].CoreUtilization != want.core {
+			t.Errorf("history %d = %+v, want pod %s with one sample of core %v", i, res[i], want.podUID, want.core)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	r := NewRecorder(time.Minute, time.Second)
+	for i := 0; i < 5; i++ {
+		r.Record(testStart.Add(time.Duration(i)*time.Second),
+			newTestDevices(float64(i), uint64(i), newTestVxpu(testPodUID, float64(i*10))))
+	}
+	tests := []struct {
+		name       string
+		start, end int64
+		id         string
+		wantIds    []string
+		wantTimes  []int64
+	}{
+		{name: "all", start: 0, end: 2000, wantIds: []string{testGpuId, testVxpuId},
+			wantTimes: []int64{1000, 1001, 1002, 1003, 1004}},
+		{name: "inclusive range", start: 1001, end: 1003, id: testGpuId, wantIds: []string{testGpuId},
+			wantTimes: []int64{1001, 1002, 1003}},
+		{name: "vxpu", start: 1004, end: 1004, id: testVxpuId, wantIds: []string{testVxpuId},
+			wantTimes: []int64{1004}},
+		{name: "unknown id", start: 0, end: 2000, id: "GPU-9", wantIds: []string{}},
+		{name: "empty range", start: 2000, end: 3000, wantIds: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := r.Query(tt.start, tt.end, tt.id)
+			ids := make([]string, 0)
+			for _, h := range res {
+				ids = append(ids, h.Id)
+				if got := timestamps(h.Samples); !reflect.DeepEqual(got, tt.wantTimes) {
+					t.Errorf("samples of %s = %v, want %v", h.Id, got, tt.wantTimes)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantIds) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIds)
+			}
+		})
+	}
+}
+
+func TestQueryAggregation(t *testing.T) {
+	r := NewRecorder(time.Minute, time.Second)
+	for i, core := range []float64{40, 10, 30, 20} {
+		r.Record(testStart.Add(time.Duration(i)*time.Second), newTestDevices(core, uint64(core)))
+	}
+	res := r.Query(0, 2000, testGpuId)
+	if len(res) != 1 {
+		t.Fatalf("Query returned %d histories, want 1", len(res))
+	}
+	want := types.UsageStatistics{Min: 10, Avg: 25, Max: 40, P95: 40}
+	if res[0].CoreUtilization != want {
+		t.Errorf("core utilization = %+v, want %+v", res[0].CoreUtilization, want)
+	}
+	if res[0].MemoryUsed != want {
+		t.Errorf("memory used = %+v, want %+v", res[0].MemoryUsed, want)
+	}
+	wantPower := types.UsageStatistics{Min: 100, Avg: 100, Max: 100, P95: 100}
+	if res[0].PowerUsage != wantPower {
+		t.Errorf("power usage = %+v, want %+v", res[0].PowerUsage, wantPower)
+	}
+}
+
+func TestAggregateP95(t *testing.T) {
+	sequence := func(n int) []float64 {
+		values := make([]float64, 0, n)
+		// descending so that the values have to be sorted
+		for i := n; i > 0; i-- {
+			values = append(values, float64(i))
+		}
+		return values
+	}
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{name: "one sample", values: []float64{7}, want: 7},
+		{name: "two samples", values: []float64{1, 2}, want: 2},
+		{name: "ten samples", values: sequence(10), want: 10},
+		{name: "twenty samples", values: sequence(20), want: 19},
+		{name: "hundred samples", values: sequence(100), want: 95},
+		{name: "outlier", values: append(sequence(39), 1000), want: 38},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := make([]types.UsageSample, 0, len(tt.values))
+			for _, v := range tt.values {
+				samples = append(samples, types.UsageSample{CoreUtilization: v})
+			}
+			got := aggregate(samples, func(s types.UsageSample) float64 { return s.CoreUtilization })
+			if got.P95 != tt.want {
+				t.Errorf("P95 = %v, want %v", got.P95, tt.want)
+			}
+		})
+	}
+}
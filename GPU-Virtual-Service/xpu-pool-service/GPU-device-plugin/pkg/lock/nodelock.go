@@ -62,6 +62,24 @@ func NewClient() error {
 	return err
 }
 
+// NewClientForContext create a k8s client connection to apiserver with the kubeconfig file and context,
+// the loading rules of kubectl are used for the empty ones
+func NewClientForContext(kubeConfig, contextName string) error {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeConfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: contextName}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	kubeClient = client
+	return nil
+}
+
 func setNodeLock(nodeName string, lockName string) error {
 	ctx := context.Background()
 	node, err := kubeClient.CoreV1().Nodes().Get(ctx, nodeName, v1.GetOptions{})
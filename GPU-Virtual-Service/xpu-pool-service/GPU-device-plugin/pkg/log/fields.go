@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package log
+
+import (
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// the names of the structured fields
+const (
+	FieldComponent  = "component"
+	FieldPodUID     = "podUID"
+	FieldDeviceUUID = "deviceUUID"
+	FieldRequestID  = "requestID"
+)
+
+var component atomic.Value
+
+// SetComponent sets the component field of all the logs, such as xpu-device-plugin or xpu-exporter
+func SetComponent(name string) {
+	component.Store(name)
+}
+
+func getComponent() string {
+	name, _ := component.Load().(string)
+	return name
+}
+
+// Entry is a log entry with structured fields, the empty values are not added
+type Entry struct {
+	entry *logrus.Entry
+}
+
+// WithPodUID returns an entry with the pod uid field
+func WithPodUID(uid string) *Entry {
+	return (&Entry{entry: logrus.NewEntry(logger)}).WithPodUID(uid)
+}
+
+// WithDeviceUUID returns an entry with the device uuid field
+func WithDeviceUUID(uuid string) *Entry {
+	return (&Entry{entry: logrus.NewEntry(logger)}).WithDeviceUUID(uuid)
+}
+
+// WithRequestID returns an entry with the request id field
+func WithRequestID(id string) *Entry {
+	return (&Entry{entry: logrus.NewEntry(logger)}).WithRequestID(id)
+}
+
+// WithPodUID returns a copy of the entry with the pod uid field
+func (e *Entry) WithPodUID(uid string) *Entry {
+	return e.withField(FieldPodUID, uid)
+}
+
+// WithDeviceUUID returns a copy of the entry with the device uuid field
+func (e *Entry) WithDeviceUUID(uuid string) *Entry {
+	return e.withField(FieldDeviceUUID, uuid)
+}
+
+// WithRequestID returns a copy of the entry with the request id field
+func (e *Entry) WithRequestID(id string) *Entry {
+	return e.withField(FieldRequestID, id)
+}
+
+func (e *Entry) withField(key, value string) *Entry {
+	if value == "" {
+		return e
+	}
+	return &Entry{entry: e.entry.WithField(key, value)}
+}
+
+// Debugf ensures output of Debugf logs
+func (e *Entry) Debugf(format string, args ...interface{}) {
+	e.entry.Debugf(format, args...)
+}
+
+// Infof ensures output of Infof logs
+func (e *Entry) Infof(format string, args ...interface{}) {
+	e.entry.Infof(format, args...)
+}
+
+// Infoln ensures output of Infoln logs
+func (e *Entry) Infoln(args ...interface{}) {
+	e.entry.Infoln(args...)
+}
+
+// Warningf ensures output of Warningf logs
+func (e *Entry) Warningf(format string, args ...interface{}) {
+	e.entry.Warningf(format, args...)
+}
+
+// Errorf ensures output of Errorf logs
+func (e *Entry) Errorf(format string, args ...interface{}) {
+	e.entry.Errorf(format, args...)
+}
+
+// Errorln ensures output of Errorln logs
+func (e *Entry) Errorln(args ...interface{}) {
+	e.entry.Errorln(args...)
+}
@@ -0,0 +1,28 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package log
+
+import "testing"
+
+func TestWithFields(t *testing.T) {
+	if data := WithPodUID("").entry.Data; len(data) != 0 {
+		t.Errorf("empty pod uid added fields %v", data)
+	}
+
+	base := WithPodUID("uid-1")
+	e := base.WithDeviceUUID("").WithRequestID("req-1")
+	want := map[string]string{FieldPodUID: "uid-1", FieldRequestID: "req-1"}
+	if len(e.entry.Data) != len(want) {
+		t.Errorf("got fields %v, want %v", e.entry.Data, want)
+	}
+	for key, value := range want {
+		if e.entry.Data[key] != value {
+			t.Errorf("got %s %v, want %v", key, e.entry.Data[key], value)
+		}
+	}
+	if _, ok := base.entry.Data[FieldRequestID]; ok {
+		t.Errorf("the fields of the copy are added to the base entry %v", base.entry.Data)
+	}
+}
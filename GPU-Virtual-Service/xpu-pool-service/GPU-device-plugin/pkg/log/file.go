@@ -17,11 +17,14 @@ import (
 	"sync"
 	"syscall"
 	"time"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 const (
 	backupTimeFormat  = "20060102T150405.000"
 	compressSuffix    = ".gz"
+	zstdSuffix        = ".zst"
 	defaultLogPath    = "/var/log"
 	defaultLogMaxSize = 20
 
@@ -29,6 +32,17 @@ const (
 	fileModeUserRwForWritingLog = 0640
 	dirModeUserRwxForLogDir     = 0750
 	hourPerDay                  = 24
+
+	// RotateDaily rotates the log file at the start of each day
+	RotateDaily = "daily"
+	// RotateHourly rotates the log file at the start of each hour
+	RotateHourly = "hourly"
+	// CompressGzip compresses the backup files by gzip
+	CompressGzip = "gzip"
+	// CompressZstd compresses the backup files by zstd
+	CompressZstd = "zstd"
+	// CompressNone keeps the backup files uncompressed
+	CompressNone = "none"
 )
 
 var _ io.WriteCloser = (*FileLogger)(nil)
@@ -42,6 +56,8 @@ var _ io.WriteCloser = (*FileLogger)(nil)
 // before the timepoint, and the expired files are automatically deleted.
 // If maxBackups and maxAge are both 0, no old log files will be deleted. This may cause the log space to be full.
 // Avoid entering this state.
+// rotateInterval additionally rotates the log file at the first write after each daily or hourly boundary,
+// and maxTotalSize removes the oldest backup files once their total size exceeds it.
 type FileLogger struct {
 	// Filename is the file to write logs to.
 	fileName string
@@ -58,6 +74,28 @@ type FileLogger struct {
 	// localTime whether the log timestamp uses the local time. The default is to use UTC time.
 	localTime bool
 
+	// rotateInterval the time based rotation, RotateDaily or RotateHourly. Empty rotates only on size.
+	rotateInterval string
+
+	// rotateAt the next boundary of the time based rotation
+	rotateAt time.Time
+
+	// maxTotalSize the maximum total size of the backup files, in MB. 0 disables the limit.
+	maxTotalSize int
+
+	// compress the compression of the backup files, CompressGzip, CompressZstd or CompressNone.
+	// Empty uses gzip.
+	compress string
+
+	// compressLevel the level of compression, 0 uses the default level of the compression
+	compressLevel int
+
+	// postRotate is called with the final name of each backup file after it is compressed
+	postRotate func(backup string)
+
+	// backups the backup files rotated and not yet passed to postRotate
+	backups []string
+
 	// Length of the current log file that has been written
 	writtenLen int64
 
@@ -93,7 +131,7 @@ func (l *FileLogger) Write(bytes []byte) (int, error) {
 		}
 	}
 
-	if l.writtenLen+writtenLen >= l.maxLogContextLen() {
+	if l.writtenLen+writtenLen >= l.maxLogContextLen() || l.rotateDue() {
 		if err := l.rotate(); err != nil {
 			return 0, err
 		}
@@ -157,6 +195,7 @@ func (l *FileLogger) openNew() error {
 		if err := os.Rename(name, newName); err != nil {
 			return fmt.Errorf("can't rename log file: %s", err)
 		}
+		l.backups = append(l.backups, newName)
 
 		err := os.Chmod(newName, os.FileMode(fileModeUserRoForArchiveLog))
 		if err != nil {
@@ -176,9 +215,38 @@ func (l *FileLogger) openNew() error {
 	}
 	l.file = f
 	l.writtenLen = 0
+	l.rotateAt = l.nextRotateTime(currentTime())
 	return nil
 }
 
+// periodStart returns the start of the rotation period containing t
+func (l *FileLogger) periodStart(t time.Time) time.Time {
+	if !l.localTime {
+		t = t.UTC()
+	}
+	if l.rotateInterval == RotateHourly {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextRotateTime returns the boundary of the time based rotation after t
+func (l *FileLogger) nextRotateTime(t time.Time) time.Time {
+	switch l.rotateInterval {
+	case RotateDaily:
+		return l.periodStart(t).AddDate(0, 0, 1)
+	case RotateHourly:
+		return l.periodStart(t).Add(time.Hour)
+	default:
+		return time.Time{}
+	}
+}
+
+// rotateDue returns whether the time based rotation boundary has passed
+func (l *FileLogger) rotateDue() bool {
+	return !l.rotateAt.IsZero() && !currentTime().Before(l.rotateAt)
+}
+
 // backupName creates a backup name from the given name, inserting a timestamp
 // between the filename and the extension, using the local time if requested
 // by the localTime yield configuration Whether the UTC time or local time is used is determined
@@ -213,6 +281,10 @@ func (l *FileLogger) openExistingOrNew(writeLen int) error {
 	if info.Size()+int64(writeLen) >= l.maxLogContextLen() {
 		return l.rotate()
 	}
+	// the file written in a previous period is rotated when the process restarts
+	if l.rotateInterval != "" && info.ModTime().Before(l.periodStart(currentTime())) {
+		return l.rotate()
+	}
 
 	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, os.FileMode(fileModeUserRwForWritingLog))
 	if err != nil {
@@ -222,6 +294,7 @@ func (l *FileLogger) openExistingOrNew(writeLen int) error {
 	}
 	l.file = file
 	l.writtenLen = info.Size()
+	l.rotateAt = l.nextRotateTime(currentTime())
 	return nil
 }
 
@@ -235,9 +308,12 @@ func (l *FileLogger) filename() string {
 	return l.fileName
 }
 
-// handleStaleLogRun performs compression and removal of stale log files.
+// handleStaleLogRun performs compression and removal of stale log files, then calls postRotate
+// and removes the oldest files over maxTotalSize.
 func (l *FileLogger) handleStaleLogRunOnce() error {
-	if l.maxBackups == 0 && l.maxAge == 0 {
+	backups := l.takeBackups()
+	if l.maxBackups == 0 && l.maxAge == 0 && l.maxTotalSize == 0 {
+		l.firePostRotate(backups, nil)
 		return nil
 	}
 
@@ -250,11 +326,7 @@ func (l *FileLogger) handleStaleLogRunOnce() error {
 	cutoff := time.Now().Add(-time.Duration(l.maxAge) * hourPerDay * time.Hour)
 	var toCompress, toRemove []logInfo
 	for _, f := range files {
-		baseName := f.Name()
-		isCompressed := strings.HasSuffix(baseName, compressSuffix)
-		if isCompressed {
-			baseName = baseName[:len(baseName)-len(compressSuffix)]
-		}
+		baseName, isCompressed := trimCompressSuffix(f.Name())
 
 		// Check if file should be removed due to max backups or age. if maxBackups==0 or maxAge==0,
 		// it is considered that recycling and clearing function is not enabled
@@ -262,7 +334,7 @@ func (l *FileLogger) handleStaleLogRunOnce() error {
 			toRemove = append(toRemove, f)
 		} else {
 			preserved[baseName] = true
-			if !isCompressed {
+			if !isCompressed && l.compression() != CompressNone {
 				toCompress = append(toCompress, f)
 			}
 		}
@@ -276,20 +348,102 @@ func (l *FileLogger) handleStaleLogRunOnce() error {
 	}
 
 	// Compress files toCompress
+	compressed := make(map[string]string, len(toCompress))
+	suffix := l.compressSuffix()
 	for _, f := range toCompress {
 		fn := filepath.Join(l.dir(), f.Name())
-		if errCompress := compressLogFile(fn, fn+compressSuffix); errCompress != nil {
+		if errCompress := compressLogFile(fn, fn+suffix, l.compression(), l.compressLevel); errCompress != nil {
 			err = errCompress
+			continue
 		}
+		compressed[fn] = fn + suffix
+	}
+
+	l.firePostRotate(backups, compressed)
+	if errRemove := l.removeOverTotalSize(); errRemove != nil {
+		err = errRemove
 	}
 	return err
 }
 
+// takeBackups returns the backup files rotated since the last call
+func (l *FileLogger) takeBackups() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	backups := l.backups
+	l.backups = nil
+	return backups
+}
+
+// firePostRotate calls postRotate with the final names of the backup files which still exist
+func (l *FileLogger) firePostRotate(backups []string, compressed map[string]string) {
+	if l.postRotate == nil {
+		return
+	}
+	for _, backup := range backups {
+		if name, ok := compressed[backup]; ok {
+			backup = name
+		}
+		if _, err := os.Stat(backup); err == nil {
+			l.postRotate(backup)
+		}
+	}
+}
+
+// removeOverTotalSize removes the oldest backup files once the total size of the newer ones exceeds maxTotalSize
+func (l *FileLogger) removeOverTotalSize() error {
+	if l.maxTotalSize == 0 {
+		return nil
+	}
+	files, err := l.oldLogFiles()
+	if err != nil {
+		return err
+	}
+	var total int64
+	maxTotal := int64(l.maxTotalSize) * int64(megabyte)
+	for _, f := range files {
+		total += f.Size()
+		if total <= maxTotal {
+			continue
+		}
+		if errRemove := os.Remove(filepath.Join(l.dir(), f.Name())); errRemove != nil {
+			err = errRemove
+		}
+	}
+	return err
+}
+
+// compression returns the compression of the backup files
+func (l *FileLogger) compression() string {
+	if l.compress == "" {
+		return CompressGzip
+	}
+	return l.compress
+}
+
+// compressSuffix returns the suffix of the compressed backup files
+func (l *FileLogger) compressSuffix() string {
+	if l.compression() == CompressZstd {
+		return zstdSuffix
+	}
+	return compressSuffix
+}
+
+// trimCompressSuffix returns the name without the suffix of compression and whether it is compressed
+func trimCompressSuffix(name string) (string, bool) {
+	for _, suffix := range []string{compressSuffix, zstdSuffix} {
+		if strings.HasSuffix(name, suffix) {
+			return name[:len(name)-len(suffix)], true
+		}
+	}
+	return name, false
+}
+
 // handleStaleLogRun runs in a goroutine to manage post-rotation compression and removal of stale log files.
 func (l *FileLogger) handleStaleLogRun() {
 	for range l.staleLogCh {
 		if err := l.handleStaleLogRunOnce(); err != nil {
-			fmt.Errorf("handle stale log err: %v", err)
+			fmt.Fprintf(os.Stderr, "handle stale log err: %v\n", err)
 		}
 	}
 }
@@ -330,6 +484,10 @@ func (l *FileLogger) oldLogFiles() ([]logInfo, error) {
 			logFiles = append(logFiles, logInfo{t, fileInfo})
 			continue
 		}
+		if t, err := l.extractTimeFromFileName(f.Name(), prefix, ext+zstdSuffix); err == nil {
+			logFiles = append(logFiles, logInfo{t, fileInfo})
+			continue
+		}
 	}
 	sort.Sort(logInfoWithTimestamp(logFiles))
 	return logFiles, nil
@@ -392,8 +550,8 @@ func createAndChown(name string, info os.FileInfo) error {
 	return os.Chown(name, int(stat.Uid), int(stat.Gid))
 }
 
-// compressLogFile compresses the given log file and removing src log file if successful.
-func compressLogFile(src, dst string) (err error) {
+// compressLogFile compresses the given log file by gzip or zstd and removing src log file if successful.
+func compressLogFile(src, dst, compress string, level int) (err error) {
 	srcFile, err := os.Open(src)
 	if err != nil {
 		return fmt.Errorf("open log file failed: %v", err)
@@ -415,7 +573,6 @@ func compressLogFile(src, dst string) (err error) {
 	}
 	defer gzWriter.Close()
 
-	gzFile := gzip.NewWriter(gzWriter)
 	defer func() {
 		if err != nil {
 			if errRemove := os.Remove(dst); errRemove == nil {
@@ -426,6 +583,11 @@ func compressLogFile(src, dst string) (err error) {
 		}
 	}()
 
+	gzFile, err := newCompressWriter(gzWriter, compress, level)
+	if err != nil {
+		return err
+	}
+
 	if _, err := io.Copy(gzFile, srcFile); err != nil {
 		return err
 	}
@@ -444,6 +606,20 @@ func compressLogFile(src, dst string) (err error) {
 	return nil
 }
 
+// newCompressWriter creates the writer of the compression, level 0 uses the default level
+func newCompressWriter(w io.Writer, compress string, level int) (io.WriteCloser, error) {
+	if compress == CompressZstd {
+		if level == 0 {
+			return zstd.NewWriter(w)
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
 // logInfo is a convenience struct to return the filename and its embedded timestamp.
 type logInfo struct {
 	timestamp time.Time
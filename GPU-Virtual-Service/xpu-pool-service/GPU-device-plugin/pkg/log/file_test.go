@@ -0,0 +1,248 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// mockTime sets the time seen by the FileLogger, the returned function moves it
+func mockTime(t *testing.T, now time.Time) func(time.Time) {
+	t.Helper()
+	original := currentTime
+	t.Cleanup(func() { currentTime = original })
+	currentTime = func() time.Time { return now }
+	return func(next time.Time) { now = next }
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("read %s failed: %v", name, err)
+	}
+	return string(data)
+}
+
+func writeFile(t *testing.T, name, data string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(data), fileModeUserRwForWritingLog); err != nil {
+		t.Fatalf("write %s failed: %v", name, err)
+	}
+}
+
+func writeLog(t *testing.T, l *FileLogger, data string) {
+	t.Helper()
+	if _, err := l.Write([]byte(data)); err != nil {
+		t.Fatalf("write log failed: %v", err)
+	}
+}
+
+// listDir returns the sorted names of the files in dir
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestNextRotateTime(t *testing.T) {
+	now := time.Date(2025, 1, 31, 23, 30, 15, 0, time.UTC)
+	tests := []struct {
+		interval string
+		want     time.Time
+	}{
+		{interval: RotateDaily, want: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{interval: RotateHourly, want: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{interval: ""},
+	}
+	for _, tt := range tests {
+		l := &FileLogger{rotateInterval: tt.interval}
+		if got := l.nextRotateTime(now); !got.Equal(tt.want) {
+			t.Errorf("nextRotateTime of %q = %v, want %v", tt.interval, got, tt.want)
+		}
+	}
+	l := &FileLogger{rotateInterval: RotateHourly}
+	if got, want := l.nextRotateTime(now.Add(-time.Hour)), now.Truncate(time.Hour); !got.Equal(want) {
+		t.Errorf("nextRotateTime = %v, want %v", got, want)
+	}
+}
+
+func TestRotateAtBoundary(t *testing.T) {
+	setTime := mockTime(t, time.Date(2025, 1, 2, 23, 59, 59, 0, time.UTC))
+	dir := t.TempDir()
+	l := &FileLogger{fileName: filepath.Join(dir, "app.log"), rotateInterval: RotateDaily}
+	defer l.Close()
+
+	writeLog(t, l, "a")
+	writeLog(t, l, "b")
+	if names := listDir(t, dir); !reflect.DeepEqual(names, []string{"app.log"}) {
+		t.Fatalf("files before the boundary = %v", names)
+	}
+
+	// the first write at the boundary rotates
+	setTime(time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC))
+	writeLog(t, l, "c")
+	backup := "app-20250103T000000.000.log"
+	if names := listDir(t, dir); !reflect.DeepEqual(names, []string{backup, "app.log"}) {
+		t.Fatalf("files after the boundary = %v", names)
+	}
+	if got := readFile(t, filepath.Join(dir, backup)); got != "ab" {
+		t.Errorf("backup = %q, want ab", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "app.log")); got != "c" {
+		t.Errorf("log = %q, want c", got)
+	}
+	if want := time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC); !l.rotateAt.Equal(want) {
+		t.Errorf("rotateAt = %v, want %v", l.rotateAt, want)
+	}
+}
+
+func TestRotateFileOfPreviousPeriod(t *testing.T) {
+	now := time.Date(2025, 1, 3, 10, 30, 0, 0, time.UTC)
+	mockTime(t, now)
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	tests := []struct {
+		name    string
+		modTime time.Time
+		want    []string
+	}{
+		{name: "same hour", modTime: now.Add(-10 * time.Minute), want: []string{"app.log"}},
+		{name: "previous hour", modTime: now.Add(-time.Hour),
+			want: []string{"app-20250103T103000.000.log", "app.log"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, n := range listDir(t, dir) {
+				os.Remove(filepath.Join(dir, n))
+			}
+			writeFile(t, name, "old")
+			if err := os.Chtimes(name, tt.modTime, tt.modTime); err != nil {
+				t.Fatal(err)
+			}
+			// the restarted process opens the existing file
+			l := &FileLogger{fileName: name, rotateInterval: RotateHourly}
+			defer l.Close()
+			writeLog(t, l, "new")
+			if names := listDir(t, dir); !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("files = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveOverTotalSize(t *testing.T) {
+	original := megabyte
+	megabyte = 1
+	defer func() { megabyte = original }()
+	dir := t.TempDir()
+	// the backups from the oldest to the newest, 4 bytes each
+	backups := []string{
+		"app-20250101T000000.000.log.gz",
+		"app-20250102T000000.000.log.zst",
+		"app-20250103T000000.000.log",
+		"app-20250104T000000.000.log.gz",
+	}
+	for _, b := range backups {
+		writeFile(t, filepath.Join(dir, b), "1234")
+	}
+	writeFile(t, filepath.Join(dir, "app.log"), strings.Repeat("x", 100))
+	writeFile(t, filepath.Join(dir, "other-20250101T000000.000.log"), "1234")
+
+	l := &FileLogger{fileName: filepath.Join(dir, "app.log"), maxTotalSize: 9}
+	if err := l.removeOverTotalSize(); err != nil {
+		t.Fatalf("removeOverTotalSize failed: %v", err)
+	}
+	// the newest backups within 9 bytes are kept, the current and unrelated files are not counted
+	want := []string{backups[2], backups[3], "app.log", "other-20250101T000000.000.log"}
+	if names := listDir(t, dir); !reflect.DeepEqual(names, want) {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+
+	l.maxTotalSize = 0
+	if err := l.removeOverTotalSize(); err != nil || len(listDir(t, dir)) != len(want) {
+		t.Errorf("removeOverTotalSize without limit should keep the files, err %v", err)
+	}
+}
+
+func TestCompressLogFile(t *testing.T) {
+	tests := []struct {
+		compress  string
+		level     int
+		newReader func(io.Reader) (io.Reader, error)
+	}{
+		{compress: CompressGzip, newReader: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }},
+		{compress: CompressZstd, newReader: func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) }},
+		{compress: CompressZstd, level: maxZstdLevel,
+			newReader: func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) }},
+	}
+	data := strings.Repeat("I0102 03:04:05 allocate vgpu\n", 100)
+	for _, tt := range tests {
+		t.Run(tt.compress, func(t *testing.T) {
+			src := filepath.Join(t.TempDir(), "app-20250101T000000.000.log")
+			writeFile(t, src, data)
+			dst := src + ".compressed"
+			if err := compressLogFile(src, dst, tt.compress, tt.level); err != nil {
+				t.Fatalf("compressLogFile failed: %v", err)
+			}
+			if _, err := os.Stat(src); !os.IsNotExist(err) {
+				t.Errorf("the source file should be removed, stat err %v", err)
+			}
+			f, err := os.Open(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			r, err := tt.newReader(f)
+			if err != nil {
+				t.Fatalf("open the compressed file failed: %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil || string(got) != data {
+				t.Errorf("decompressed %d bytes, err %v, want %d bytes", len(got), err, len(data))
+			}
+		})
+	}
+}
+
+func TestHandleStaleLogZstd(t *testing.T) {
+	dir := t.TempDir()
+	backup := filepath.Join(dir, "app-20250102T000000.000.log")
+	old := filepath.Join(dir, "app-20250101T000000.000.log.zst")
+	writeFile(t, backup, "backup")
+	writeFile(t, old, "old")
+	var rotated []string
+	l := &FileLogger{fileName: filepath.Join(dir, "app.log"), maxBackups: 1, compress: CompressZstd,
+		backups: []string{backup}, postRotate: func(name string) { rotated = append(rotated, name) }}
+
+	if err := l.handleStaleLogRunOnce(); err != nil {
+		t.Fatalf("handleStaleLogRunOnce failed: %v", err)
+	}
+	// the new backup is compressed by zstd and the older one over maxBackups is removed
+	if names := listDir(t, dir); !reflect.DeepEqual(names, []string{filepath.Base(backup) + zstdSuffix}) {
+		t.Errorf("files = %v", names)
+	}
+	if want := []string{backup + zstdSuffix}; !reflect.DeepEqual(rotated, want) {
+		t.Errorf("postRotate called with %v, want %v", rotated, want)
+	}
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// journalSocket the socket of the native protocol of systemd-journald
+	journalSocket = "/run/systemd/journal/socket"
+	// maxJournalFieldLen the max length of the field names accepted by journald
+	maxJournalFieldLen = 64
+	// journalFieldPrefix prefixes the fields of entry which collide with the fields written by the hook
+	// or with the other fields interpreted by journald
+	journalFieldPrefix = "FIELD_"
+	// journalWriteTimeout bounds the time a log call waits for journald which does not read its socket
+	journalWriteTimeout = time.Second
+)
+
+// journalReservedFields the fields written by the hook and the other user fields with a meaning to journald
+var journalReservedFields = map[string]bool{
+	"MESSAGE": true, "MESSAGE_ID": true, "PRIORITY": true, "CODE_FILE": true, "CODE_LINE": true,
+	"CODE_FUNC": true, "ERRNO": true, "INVOCATION_ID": true, "USER_INVOCATION_ID": true,
+	"SYSLOG_FACILITY": true, "SYSLOG_IDENTIFIER": true, "SYSLOG_PID": true, "SYSLOG_TIMESTAMP": true,
+	"SYSLOG_RAW": true, "DOCUMENTATION": true, "TID": true, "UNIT": true, "USER_UNIT": true,
+}
+
+// JournaldHook sends logs to the systemd journal by the native protocol over the local datagram socket.
+// The fields of entry are sent as journal fields named in upper snake case, such as podUID as POD_UID,
+// the ones colliding with the reserved fields are prefixed, such as message as FIELD_MESSAGE.
+type JournaldHook struct {
+	socket string
+	levels []logrus.Level
+	pid    string
+	mutex  sync.Mutex
+	conn   net.Conn
+	redial redialBackoff
+}
+
+// newJournaldHook creates a hook for the journal socket, the logs above level are sent
+func newJournaldHook(socket string, level logrus.Level) (*JournaldHook, error) {
+	hook := &JournaldHook{socket: socket, levels: levelsFrom(level), pid: strconv.Itoa(os.Getpid())}
+	if err := hook.dial(); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// Levels returns the levels above the level of the hook.
+func (hook *JournaldHook) Levels() []logrus.Level {
+	return hook.levels
+}
+
+// Fire sends the entry, the connection is redialed once when journald restarts.
+// The entries are dropped without dialing until the redial backoff of a failed dial elapses.
+func (hook *JournaldHook) Fire(entry *logrus.Entry) error {
+	msg := hook.format(entry)
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+	if hook.conn != nil {
+		if err := hook.write(msg); err == nil {
+			return nil
+		}
+		hook.conn.Close()
+		hook.conn = nil
+	}
+	if err := hook.redial.wait(); err != nil {
+		return fmt.Errorf("journald %s unreachable: %v", hook.socket, err)
+	}
+	if err := hook.dial(); err != nil {
+		hook.redial.failed()
+		return err
+	}
+	hook.redial.reset()
+	return hook.write(msg)
+}
+
+func (hook *JournaldHook) write(msg []byte) error {
+	if err := hook.conn.SetWriteDeadline(time.Now().Add(journalWriteTimeout)); err != nil {
+		return err
+	}
+	_, err := hook.conn.Write(msg)
+	return err
+}
+
+func (hook *JournaldHook) dial() error {
+	conn, err := net.Dial("unixgram", hook.socket)
+	if err != nil {
+		return fmt.Errorf("dial journald %s failed: %v", hook.socket, err)
+	}
+	hook.conn = conn
+	return nil
+}
+
+// format formats the entry as the fields of the native protocol
+func (hook *JournaldHook) format(entry *logrus.Entry) []byte {
+	b := &bytes.Buffer{}
+	writeJournalField(b, "MESSAGE", entry.Message)
+	writeJournalField(b, "PRIORITY", strconv.Itoa(syslogSeverity(entry.Level)))
+	writeJournalField(b, "SYSLOG_FACILITY", strconv.Itoa(facilityDaemon))
+	writeJournalField(b, "SYSLOG_IDENTIFIER", appName())
+	writeJournalField(b, "SYSLOG_PID", hook.pid)
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := journalFieldName(key)
+		if name == "" {
+			continue
+		}
+		value := entry.Data[key]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		writeJournalField(b, name, fmt.Sprint(value))
+	}
+	return b.Bytes()
+}
+
+// writeJournalField writes NAME=value, the value with newline is written as NAME, its length and the value
+func writeJournalField(b *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		b.WriteString(name + "=" + value + "\n")
+		return
+	}
+	b.WriteString(name + "\n")
+	size := make([]byte, 8)
+	binary.LittleEndian.PutUint64(size, uint64(len(value)))
+	b.Write(size)
+	b.WriteString(value + "\n")
+}
+
+// journalFieldName converts the key to the upper snake case with the characters allowed by journald,
+// the keys which start with underscore are trusted fields of journald and are removed, the reserved
+// fields are prefixed
+func journalFieldName(key string) string {
+	var b strings.Builder
+	var prev rune
+	for _, r := range key {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		case r >= 'a' && r <= 'z':
+			b.WriteRune(unicode.ToUpper(r))
+		case r >= '0' && r <= '9' || r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+		prev = r
+	}
+	name := b.String()
+	if name == "" || name[0] == '_' || unicode.IsDigit(rune(name[0])) {
+		return ""
+	}
+	if journalReservedFields[name] {
+		name = journalFieldPrefix + name
+	}
+	return truncate(name, maxJournalFieldLen)
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package log
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// parseJournalFields decodes the fields of the native protocol of journald
+func parseJournalFields(t *testing.T, msg string) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for msg != "" {
+		i := strings.IndexByte(msg, '\n')
+		if i < 0 {
+			t.Fatalf("field without newline: %q", msg)
+		}
+		line := msg[:i]
+		msg = msg[i+1:]
+		if name, value, ok := strings.Cut(line, "="); ok {
+			fields[name] = value
+			continue
+		}
+		if len(msg) < 8 {
+			t.Fatalf("binary field %s without length", line)
+		}
+		size := int(binary.LittleEndian.Uint64([]byte(msg[:8])))
+		if len(msg) < 8+size+1 || msg[8+size] != '\n' {
+			t.Fatalf("binary field %s with invalid length %d", line, size)
+		}
+		fields[line] = msg[8 : 8+size]
+		msg = msg[8+size+1:]
+	}
+	return fields
+}
+
+func TestJournaldHook(t *testing.T) {
+	path := testSocketPath(t)
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	hook, err := newJournaldHook(path, logrus.InfoLevel)
+	if err != nil {
+		t.Fatalf("newJournaldHook failed: %v", err)
+	}
+	defer hook.conn.Close()
+
+	fireTestEntry(t, hook, newTestEntry("allocate\nfailed", logrus.Fields{
+		"podUID":   "uid-1",
+		"error":    errors.New("no device"),
+		"message":  "user message",
+		"priority": 0,
+		"_PID":     1,
+	}))
+	got := parseJournalFields(t, readDatagram(t, conn))
+	want := map[string]string{
+		"MESSAGE":           "allocate\nfailed",
+		"PRIORITY":          "4",
+		"SYSLOG_FACILITY":   "3",
+		"SYSLOG_IDENTIFIER": appName(),
+		"SYSLOG_PID":        hook.pid,
+		"POD_UID":           "uid-1",
+		"ERROR":             "no device",
+		"FIELD_MESSAGE":     "user message",
+		"FIELD_PRIORITY":    "0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
+
+func TestJournaldHookRedialBackoff(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	setTime := mockTime(t, now)
+	path := testSocketPath(t)
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook, err := newJournaldHook(path, logrus.InfoLevel)
+	if err != nil {
+		t.Fatalf("newJournaldHook failed: %v", err)
+	}
+	listen := restartTestServer(t, conn, path)
+
+	if err := hook.Fire(newTestEntry("journald down", nil)); err == nil {
+		t.Fatal("Fire should fail when journald is down")
+	}
+	conn = listen()
+	if err := hook.Fire(newTestEntry("in backoff", nil)); err == nil || hook.conn != nil {
+		t.Fatalf("Fire should not dial in the backoff, err %v", err)
+	}
+	setTime(now.Add(minRedialInterval))
+	fireTestEntry(t, hook, newTestEntry("after backoff", nil))
+	defer hook.conn.Close()
+	if got := parseJournalFields(t, readDatagram(t, conn))["MESSAGE"]; got != "after backoff" {
+		t.Errorf("MESSAGE = %q, want %q", got, "after backoff")
+	}
+}
+
+func TestJournalFieldName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "podUID", want: "POD_UID"},
+		{key: "pod-name", want: "POD_NAME"},
+		{key: "gpu0Used", want: "GPU0_USED"},
+		{key: "message", want: "FIELD_MESSAGE"},
+		{key: "SYSLOG_IDENTIFIER", want: "FIELD_SYSLOG_IDENTIFIER"},
+		{key: "codeFile", want: "FIELD_CODE_FILE"},
+		{key: "_PID"},
+		{key: "0gpu"},
+		{key: ""},
+		{key: strings.Repeat("a", 70), want: strings.Repeat("A", maxJournalFieldLen)},
+	}
+	for _, tt := range tests {
+		if got := journalFieldName(tt.key); got != tt.want {
+			t.Errorf("journalFieldName(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
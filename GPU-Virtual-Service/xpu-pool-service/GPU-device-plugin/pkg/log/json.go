@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	jsonKeyTime  = "time"
+	jsonKeyLevel = "level"
+	jsonKeyPid   = "pid"
+	jsonKeyMsg   = "msg"
+	// fieldsPrefix prefixes the fields of entry which conflict with the keys above
+	fieldsPrefix = "fields."
+)
+
+// JSONFormatter formats the entry as one json object per line, the keys are sorted
+type JSONFormatter struct {
+	TimestampFormat string
+	pid             int
+}
+
+var _ logrus.Formatter = &JSONFormatter{}
+
+// Format ensures formatted logging output
+func (f *JSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(entry.Data)+5)
+	for key, value := range entry.Data {
+		switch key {
+		case jsonKeyTime, jsonKeyLevel, jsonKeyPid, jsonKeyMsg, FieldComponent:
+			key = fieldsPrefix + key
+		}
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		data[key] = value
+	}
+	data[jsonKeyTime] = entry.Time.Format(f.TimestampFormat)
+	data[jsonKeyLevel] = levelName(entry.Level)
+	data[jsonKeyPid] = f.pid
+	data[jsonKeyMsg] = entry.Message
+	if name := getComponent(); name != "" {
+		data[FieldComponent] = name
+	}
+
+	b := entry.Buffer
+	if entry.Buffer == nil {
+		b = &bytes.Buffer{}
+	}
+	if err := json.NewEncoder(b).Encode(data); err != nil {
+		return nil, fmt.Errorf("failed to marshal log entry to json: %v", err)
+	}
+	return b.Bytes(), nil
+}
+
+// levelName get the name of level, the same as the names accepted by log-level
+func levelName(level logrus.Level) string {
+	switch level {
+	case logrus.DebugLevel:
+		return "debug"
+	case logrus.InfoLevel:
+		return "info"
+	case logrus.WarnLevel:
+		return "warning"
+	case logrus.ErrorLevel:
+		return "error"
+	case logrus.FatalLevel:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
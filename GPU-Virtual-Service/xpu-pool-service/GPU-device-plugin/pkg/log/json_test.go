@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// formatTestEntry formats entry with a JSONFormatter and decodes the output
+func formatTestEntry(t *testing.T, entry *logrus.Entry) map[string]interface{} {
+	t.Helper()
+	f := &JSONFormatter{TimestampFormat: timestampFormat, pid: 42}
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("format failed: %v", err)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		t.Fatalf("decode %q failed: %v", b, err)
+	}
+	return data
+}
+
+func TestJSONFormatterKeys(t *testing.T) {
+	SetComponent("xpu-device-plugin")
+	t.Cleanup(func() { SetComponent("") })
+	data := formatTestEntry(t, newTestEntry("allocate done", logrus.Fields{FieldPodUID: "uid-1"}))
+	want := map[string]interface{}{
+		jsonKeyTime:    "2025-01-02 03:04:05.000",
+		jsonKeyLevel:   "warning",
+		jsonKeyPid:     float64(42),
+		jsonKeyMsg:     "allocate done",
+		FieldComponent: "xpu-device-plugin",
+		FieldPodUID:    "uid-1",
+	}
+	if len(data) != len(want) {
+		t.Errorf("got keys %v, want %v", data, want)
+	}
+	for key, value := range want {
+		if data[key] != value {
+			t.Errorf("got %s %v, want %v", key, data[key], value)
+		}
+	}
+}
+
+func TestJSONFormatterReservedKeys(t *testing.T) {
+	SetComponent("xpu-device-plugin")
+	t.Cleanup(func() { SetComponent("") })
+	fields := logrus.Fields{
+		jsonKeyTime:    "t",
+		jsonKeyLevel:   "l",
+		jsonKeyMsg:     "m",
+		jsonKeyPid:     "p",
+		FieldComponent: "c",
+	}
+	data := formatTestEntry(t, newTestEntry("reserved", fields))
+	for key, value := range fields {
+		if data[fieldsPrefix+key] != value {
+			t.Errorf("got %s %v, want %v", fieldsPrefix+key, data[fieldsPrefix+key], value)
+		}
+	}
+	if data[jsonKeyMsg] != "reserved" || data[jsonKeyPid] != float64(42) ||
+		data[FieldComponent] != "xpu-device-plugin" {
+		t.Errorf("the reserved keys are overwritten by the fields: %v", data)
+	}
+}
+
+func TestJSONFormatterError(t *testing.T) {
+	data := formatTestEntry(t, newTestEntry("failed", logrus.Fields{logrus.ErrorKey: errors.New("device busy")}))
+	if data[logrus.ErrorKey] != "device busy" {
+		t.Errorf("got error %v, want the message of the error", data[logrus.ErrorKey])
+	}
+	if _, ok := data[FieldComponent]; ok {
+		t.Errorf("the component is added without SetComponent: %v", data)
+	}
+}
@@ -7,10 +7,12 @@ package log
 
 import (
 	"bytes"
+	"compress/gzip"
 	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 )
@@ -19,9 +21,28 @@ var (
 	logger         = logrus.New()
 	loggingConsole = flag.Bool("logging-console", false, "enable log output, default false")
 	logLevel       = flag.String("log-level", "info", "Set logging level (debug, info, warning, fatal)")
+	logFormat      = flag.String("log-format", formatText, "Set logging format (text, json)")
+	syslogAddress  = flag.String("log-syslog", "", "send RFC 5424 logs to the syslog address, "+
+		"such as unix:///dev/log, udp://host:514 or tcp://host:514, empty disables it")
+	syslogLevel = flag.String("log-syslog-level", "info",
+		"logging level of the syslog sink, the logs below log-level are not sent")
+	journald      = flag.Bool("log-journald", false, "send logs to the systemd journal, default false")
+	journaldLevel = flag.String("log-journald-level", "info",
+		"logging level of the journald sink, the logs below log-level are not sent")
 	maxSize        = flag.Int("max-size", defaultMaxSize, "maximum length of a log (MB)")
 	maxBackups     = flag.Int("max-backups", defaultMaxBackups, "maximum number of log files")
 	maxAge         = flag.Int("max-age", defaultMaxAge, "maximum storage duration, in days")
+	rotateInterval = flag.String("log-rotate-interval", "",
+		"rotate the log file at each daily or hourly boundary besides the size (daily, hourly), empty disables it")
+	maxTotalSize = flag.Int("log-max-total-size", 0,
+		"maximum total size of the backup log files (MB), 0 disables it")
+	compress = flag.String("log-compress", CompressGzip,
+		"compression of the backup log files (gzip, zstd, none)")
+	compressLevel = flag.Int("log-compress-level", 0,
+		"compression level, gzip range[1, 9], zstd range[1, 22], 0 uses the default level")
+
+	// postRotateHook is called with the name of each backup log file after it is compressed
+	postRotateHook atomic.Pointer[func(backup string)]
 )
 
 const (
@@ -29,8 +50,14 @@ const (
 	defaultMaxBackups = 10
 	defaultMaxAge     = 30
 	timestampFormat   = "2006-01-02 15:04:05.000"
+	formatText        = "text"
+	formatJSON        = "json"
+	maxZstdLevel      = 22
 )
 
+// baseLevel the level set by log-level or SetLevel, restored after the level is raised by signal
+var baseLevel atomic.Uint32
+
 func checkLoggerParamValid() error {
 	if *maxAge <= 0 {
 		return errors.New("the max-ages is invalid")
@@ -38,9 +65,44 @@ func checkLoggerParamValid() error {
 	if *maxBackups <= 0 {
 		return errors.New("the max-backups is invalid")
 	}
+	if *rotateInterval != "" && *rotateInterval != RotateDaily && *rotateInterval != RotateHourly {
+		return errors.New("the log-rotate-interval is invalid")
+	}
+	if *maxTotalSize < 0 {
+		return errors.New("the log-max-total-size is invalid")
+	}
+	return checkCompressParamValid()
+}
+
+func checkCompressParamValid() error {
+	switch *compress {
+	case CompressGzip:
+		if *compressLevel < 0 || *compressLevel > gzip.BestCompression {
+			return errors.New("the log-compress-level of gzip is invalid")
+		}
+	case CompressZstd:
+		if *compressLevel < 0 || *compressLevel > maxZstdLevel {
+			return errors.New("the log-compress-level of zstd is invalid")
+		}
+	case CompressNone:
+	default:
+		return errors.New("the log-compress is invalid")
+	}
 	return nil
 }
 
+// SetPostRotateHook sets the function called with the name of each backup log file after rotation,
+// the backup file is already compressed when it is called
+func SetPostRotateHook(fn func(backup string)) {
+	postRotateHook.Store(&fn)
+}
+
+func firePostRotateHook(backup string) {
+	if fn := postRotateHook.Load(); fn != nil && *fn != nil {
+		(*fn)(backup)
+	}
+}
+
 // InitLogging configures logging. Logs are written to a log file or stdout/stderr.
 // Since logrus doesn't support multiple writers, each log stream is implemented as a hook.
 func InitLogging(logName string) error {
@@ -49,10 +111,15 @@ func InitLogging(logName string) error {
 	}
 
 	logFileOutput := FileLogger{
-		fileName:   logName,
-		maxSize:    *maxSize,
-		maxBackups: *maxBackups,
-		maxAge:     *maxAge,
+		fileName:       logName,
+		maxSize:        *maxSize,
+		maxBackups:     *maxBackups,
+		maxAge:         *maxAge,
+		rotateInterval: *rotateInterval,
+		maxTotalSize:   *maxTotalSize,
+		compress:       *compress,
+		compressLevel:  *compressLevel,
+		postRotate:     firePostRotateHook,
 	}
 	logger.SetOutput(&logFileOutput)
 
@@ -61,9 +128,12 @@ func InitLogging(logName string) error {
 	if err != nil {
 		return err
 	}
-	logger.SetLevel(level)
+	setBaseLevel(level)
 
-	formatter := &PlainTextFormatter{TimestampFormat: timestampFormat, pid: os.Getpid()}
+	formatter, err := newFormatter(*logFormat)
+	if err != nil {
+		return err
+	}
 	logger.SetFormatter(formatter)
 
 	if *loggingConsole {
@@ -73,12 +143,56 @@ func InitLogging(logName string) error {
 		}
 		logger.AddHook(logConsoleHook)
 	}
+	return addSinkHooks()
+}
+
+// addSinkHooks adds the hooks of syslog and journald enabled by flags, each sink filters the logs by its level
+func addSinkHooks() error {
+	if *syslogAddress != "" {
+		level, err := parseLevel(*syslogLevel)
+		if err != nil {
+			return fmt.Errorf("the log-syslog-level is invalid: %v", err)
+		}
+		hook, err := newSyslogHook(*syslogAddress, level)
+		if err != nil {
+			return fmt.Errorf("could not initialize logging to syslog: %v", err)
+		}
+		logger.AddHook(hook)
+	}
+	if *journald {
+		level, err := parseLevel(*journaldLevel)
+		if err != nil {
+			return fmt.Errorf("the log-journald-level is invalid: %v", err)
+		}
+		hook, err := newJournaldHook(journalSocket, level)
+		if err != nil {
+			return fmt.Errorf("could not initialize logging to journald: %v", err)
+		}
+		logger.AddHook(hook)
+	}
 	return nil
 }
 
+// newFormatter create the formatter of the format, range[text, json]
+func newFormatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case formatText:
+		return &PlainTextFormatter{TimestampFormat: timestampFormat, pid: os.Getpid()}, nil
+	case formatJSON:
+		return &JSONFormatter{TimestampFormat: timestampFormat, pid: os.Getpid()}, nil
+	default:
+		return nil, fmt.Errorf("invalid logging format [%v]", format)
+	}
+}
+
 // parseLogLevel parse the level of log
 func parseLogLevel() (logrus.Level, error) {
-	switch *logLevel {
+	return parseLevel(*logLevel)
+}
+
+// parseLevel parse the level name, range[debug, info, warning, error, fatal]
+func parseLevel(level string) (logrus.Level, error) {
+	switch level {
 	case "debug":
 		return logrus.DebugLevel, nil
 	case "info":
@@ -90,8 +204,28 @@ func parseLogLevel() (logrus.Level, error) {
 	case "fatal":
 		return logrus.FatalLevel, nil
 	default:
-		return logrus.FatalLevel, fmt.Errorf("invalid logging level [%v]", logLevel)
+		return logrus.FatalLevel, fmt.Errorf("invalid logging level [%v]", level)
+	}
+}
+
+// SetLevel changes the logging level at runtime, range[debug, info, warning, error, fatal]
+func SetLevel(level string) error {
+	l, err := parseLevel(level)
+	if err != nil {
+		return err
 	}
+	setBaseLevel(l)
+	return nil
+}
+
+func setBaseLevel(level logrus.Level) {
+	baseLevel.Store(uint32(level))
+	logger.SetLevel(level)
+}
+
+// GetLevel returns the current logging level
+func GetLevel() string {
+	return levelName(logger.GetLevel())
 }
 
 // PlainTextFormatter is a formatter to ensure formatted logging output
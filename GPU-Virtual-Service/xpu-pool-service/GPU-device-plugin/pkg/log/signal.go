@@ -0,0 +1,40 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package log
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WatchLevelSignals changes the logging level by signal until ctx is cancelled.
+// SIGUSR1 switches to debug level, SIGUSR2 restores the level set by log-level or SetLevel.
+func WatchLevelSignals(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(signals)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s := <-signals:
+			handleLevelSignal(s)
+		}
+	}
+}
+
+func handleLevelSignal(s os.Signal) {
+	level := logrus.Level(baseLevel.Load())
+	if s == syscall.SIGUSR1 {
+		level = logrus.DebugLevel
+	}
+	logger.SetLevel(level)
+	// logged at warning so that the change is visible at the most levels
+	logger.Warningf("received signal %v, the logging level is %s", s, levelName(level))
+}
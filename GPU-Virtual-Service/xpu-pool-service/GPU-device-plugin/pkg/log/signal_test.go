@@ -0,0 +1,44 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package log
+
+import (
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestHandleLevelSignal(t *testing.T) {
+	out, level, base := logger.Out, logger.GetLevel(), baseLevel.Load()
+	logger.SetOutput(io.Discard)
+	t.Cleanup(func() {
+		logger.SetOutput(out)
+		logger.SetLevel(level)
+		baseLevel.Store(base)
+	})
+	if err := SetLevel("warning"); err != nil {
+		t.Fatal(err)
+	}
+
+	handleLevelSignal(syscall.SIGUSR1)
+	if got := GetLevel(); got != "debug" {
+		t.Errorf("got level %s after SIGUSR1, want debug", got)
+	}
+	handleLevelSignal(syscall.SIGUSR2)
+	if got := GetLevel(); got != "warning" {
+		t.Errorf("got level %s after SIGUSR2, want warning", got)
+	}
+
+	handleLevelSignal(syscall.SIGUSR1)
+	if err := SetLevel("error"); err != nil {
+		t.Fatal(err)
+	}
+	handleLevelSignal(syscall.SIGUSR2)
+	if got := logger.GetLevel(); got != logrus.ErrorLevel {
+		t.Errorf("got level %s after SIGUSR2, want the level of SetLevel", got)
+	}
+}
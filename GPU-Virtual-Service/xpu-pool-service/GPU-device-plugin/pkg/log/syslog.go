@@ -0,0 +1,268 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	facilityDaemon  = 3
+	rfc5424Time     = "2006-01-02T15:04:05.000000Z07:00"
+	maxHostnameLen  = 255
+	maxAppNameLen   = 48
+	maxParamNameLen = 32
+	// sdID the id of the structured data element carrying the fields of entry
+	sdID = "fields@32473"
+	// syslogDialTimeout and syslogWriteTimeout bound the time a log call waits for an unreachable server
+	syslogDialTimeout  = 3 * time.Second
+	syslogWriteTimeout = time.Second
+	// minRedialInterval and maxRedialInterval bound the time a hook waits before dialing again after a
+	// failed dial, the interval is doubled by each failure
+	minRedialInterval = time.Second
+	maxRedialInterval = time.Minute
+)
+
+// SyslogHook sends RFC 5424 logs to the syslog server over unix, udp or tcp.
+// The tcp messages are framed by octet counting of RFC 6587.
+type SyslogHook struct {
+	network  string
+	address  string
+	levels   []logrus.Level
+	hostname string
+	pid      int
+	mutex    sync.Mutex
+	conn     net.Conn
+	// stream whether conn is a unix stream socket, whose messages are terminated by newline
+	stream bool
+	redial redialBackoff
+}
+
+// newSyslogHook creates a hook for the address like unix:///dev/log, udp://host:514 or tcp://host:514,
+// the logs above level are sent
+func newSyslogHook(address string, level logrus.Level) (*SyslogHook, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid syslog address %s: %v", address, err)
+	}
+	hook := &SyslogHook{network: u.Scheme, levels: levelsFrom(level), hostname: "-", pid: os.Getpid()}
+	switch u.Scheme {
+	case "unix":
+		hook.address = u.Path
+	case "udp", "tcp":
+		hook.address = u.Host
+	default:
+		return nil, fmt.Errorf("invalid syslog address %s: the scheme must be unix, udp or tcp", address)
+	}
+	if hook.address == "" {
+		return nil, fmt.Errorf("invalid syslog address %s: empty path or host", address)
+	}
+	if hostname, err := os.Hostname(); err == nil && printableASCII(hostname) != "" {
+		hook.hostname = truncate(printableASCII(hostname), maxHostnameLen)
+	}
+	if err := hook.dial(); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// Levels returns the levels above the level of the hook.
+func (hook *SyslogHook) Levels() []logrus.Level {
+	return hook.levels
+}
+
+// Fire sends the entry, the connection is redialed once when the server restarts.
+// The entries are dropped without dialing until the redial backoff of a failed dial elapses.
+func (hook *SyslogHook) Fire(entry *logrus.Entry) error {
+	msg := hook.format(entry)
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+	if hook.conn != nil {
+		if err := hook.write(msg); err == nil {
+			return nil
+		}
+		hook.conn.Close()
+		hook.conn = nil
+	}
+	if err := hook.redial.wait(); err != nil {
+		return fmt.Errorf("syslog %s://%s unreachable: %v", hook.network, hook.address, err)
+	}
+	if err := hook.dial(); err != nil {
+		hook.redial.failed()
+		return err
+	}
+	hook.redial.reset()
+	return hook.write(msg)
+}
+
+func (hook *SyslogHook) dial() error {
+	var err error
+	hook.stream = false
+	if hook.network != "unix" {
+		hook.conn, err = net.DialTimeout(hook.network, hook.address, syslogDialTimeout)
+	} else if hook.conn, err = net.DialTimeout("unixgram", hook.address, syslogDialTimeout); err != nil {
+		// the local syslog server may listen on a stream socket
+		hook.conn, err = net.DialTimeout("unix", hook.address, syslogDialTimeout)
+		hook.stream = true
+	}
+	if err != nil {
+		return fmt.Errorf("dial syslog %s://%s failed: %v", hook.network, hook.address, err)
+	}
+	return nil
+}
+
+func (hook *SyslogHook) write(msg []byte) error {
+	if hook.network == "tcp" {
+		msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+	} else if hook.stream {
+		msg = append(msg, '\n')
+	}
+	if err := hook.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout)); err != nil {
+		return err
+	}
+	_, err := hook.conn.Write(msg)
+	return err
+}
+
+// redialBackoff delays the dial of a hook after a failed one, so that the log calls do not wait for an
+// unreachable server one after another
+type redialBackoff struct {
+	interval time.Duration
+	next     time.Time
+}
+
+// wait returns an error until the backoff of the last failed dial elapses
+func (b *redialBackoff) wait() error {
+	if now := currentTime(); now.Before(b.next) {
+		return fmt.Errorf("redial in %v", b.next.Sub(now).Round(time.Millisecond))
+	}
+	return nil
+}
+
+// failed doubles the backoff
+func (b *redialBackoff) failed() {
+	b.interval = min(max(2*b.interval, minRedialInterval), maxRedialInterval)
+	b.next = currentTime().Add(b.interval)
+}
+
+// reset clears the backoff after a successful dial
+func (b *redialBackoff) reset() {
+	b.interval = 0
+	b.next = time.Time{}
+}
+
+// format formats the entry as HEADER SP STRUCTURED-DATA SP MSG of RFC 5424
+func (hook *SyslogHook) format(entry *logrus.Entry) []byte {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "<%d>1 %s %s %s %d - ", facilityDaemon*8+syslogSeverity(entry.Level),
+		entry.Time.Format(rfc5424Time), hook.hostname, appName(), hook.pid)
+	writeStructuredData(b, entry.Data)
+	b.WriteByte(' ')
+	b.WriteString(entry.Message)
+	return b.Bytes()
+}
+
+func writeStructuredData(b *bytes.Buffer, data logrus.Fields) {
+	if len(data) == 0 {
+		b.WriteByte('-')
+		return
+	}
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	b.WriteString("[" + sdID)
+	for _, key := range keys {
+		name := paramName(key)
+		if name == "" {
+			continue
+		}
+		fmt.Fprintf(b, ` %s="%s"`, name, sdEscaper.Replace(fmt.Sprint(data[key])))
+	}
+	b.WriteByte(']')
+}
+
+var sdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// paramName keeps the printable ascii characters allowed in the PARAM-NAME of RFC 5424
+func paramName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '=' || r == ']' || r == '"' {
+			return -1
+		}
+		return r
+	}, printableASCII(key))
+	return truncate(name, maxParamNameLen)
+}
+
+// printableASCII removes the characters not allowed in the header fields of RFC 5424
+func printableASCII(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// syslogSeverity get the severity of level defined in RFC 5424
+func syslogSeverity(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel:
+		return 0
+	case logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// appName returns the component, or the name of the binary when it is not set
+func appName() string {
+	name := getComponent()
+	if name == "" {
+		name = filepath.Base(os.Args[0])
+	}
+	name = printableASCII(name)
+	if name == "" {
+		return "-"
+	}
+	return truncate(name, maxAppNameLen)
+}
+
+// levelsFrom returns the levels at or above the severity of level
+func levelsFrom(level logrus.Level) []logrus.Level {
+	for i, l := range logrus.AllLevels {
+		if l == level {
+			return logrus.AllLevels[:i+1]
+		}
+	}
+	return logrus.AllLevels
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,302 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testTimeout = 5 * time.Second
+
+func newTestEntry(msg string, data logrus.Fields) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.New())
+	entry.Time = time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC)
+	entry.Level = logrus.WarnLevel
+	entry.Message = msg
+	entry.Data = data
+	return entry
+}
+
+// wantSyslogMessage returns the RFC 5424 message of newTestEntry for hook
+func wantSyslogMessage(hook *SyslogHook, sd, msg string) string {
+	return fmt.Sprintf("<28>1 2025-01-02T03:04:05.000006Z %s %s %d - %s %s", hook.hostname, appName(), hook.pid,
+		sd, msg)
+}
+
+// readDatagram reads one datagram from conn
+func readDatagram(t *testing.T, conn net.PacketConn) string {
+	t.Helper()
+	buf := make([]byte, 64*1024)
+	if err := conn.SetReadDeadline(time.Now().Add(testTimeout)); err != nil {
+		t.Fatal(err)
+	}
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read datagram failed: %v", err)
+	}
+	return string(buf[:n])
+}
+
+// acceptStream accepts one connection of l and returns its reader
+func acceptStream(t *testing.T, l net.Listener) *bufio.Reader {
+	t.Helper()
+	conns := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			close(conns)
+			return
+		}
+		conns <- conn
+	}()
+	select {
+	case conn, ok := <-conns:
+		if !ok {
+			t.Fatal("accept failed")
+		}
+		t.Cleanup(func() { conn.Close() })
+		if err := conn.SetReadDeadline(time.Now().Add(testTimeout)); err != nil {
+			t.Fatal(err)
+		}
+		return bufio.NewReader(conn)
+	case <-time.After(testTimeout):
+		t.Fatal("accept timeout")
+	}
+	return nil
+}
+
+// readOctetCounted reads one message framed by octet counting of RFC 6587
+func readOctetCounted(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	prefix, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatalf("read the message length failed: %v", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+	if err != nil {
+		t.Fatalf("invalid message length %q", prefix)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		t.Fatalf("read the message failed: %v", err)
+	}
+	return string(msg)
+}
+
+func newTestSyslogHook(t *testing.T, address string) *SyslogHook {
+	t.Helper()
+	hook, err := newSyslogHook(address, logrus.InfoLevel)
+	if err != nil {
+		t.Fatalf("newSyslogHook(%s) failed: %v", address, err)
+	}
+	t.Cleanup(func() {
+		if hook.conn != nil {
+			hook.conn.Close()
+		}
+	})
+	return hook
+}
+
+func fireTestEntry(t *testing.T, hook logrus.Hook, entry *logrus.Entry) {
+	t.Helper()
+	if err := hook.Fire(entry); err != nil {
+		t.Fatalf("Fire failed: %v", err)
+	}
+}
+
+// testSocketPath returns a short socket path, the path of unix socket is limited to 108 bytes
+func testSocketPath(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "sock")
+}
+
+func TestSyslogHookUnixgram(t *testing.T) {
+	path := testSocketPath(t)
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	hook := newTestSyslogHook(t, "unix://"+path)
+	if hook.stream {
+		t.Fatal("the datagram socket should be dialed")
+	}
+
+	fireTestEntry(t, hook, newTestEntry("hello", logrus.Fields{"pod": "default/a", "err": `a "b" [c]`}))
+	got := readDatagram(t, conn)
+	want := wantSyslogMessage(hook, `[fields@32473 err="a \"b\" [c\]" pod="default/a"]`, "hello")
+	if got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
+
+func TestSyslogHookUnixStream(t *testing.T) {
+	path := testSocketPath(t)
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	hook := newTestSyslogHook(t, "unix://"+path)
+	r := acceptStream(t, l)
+	if !hook.stream {
+		t.Fatal("the stream socket should be dialed when the datagram socket is missing")
+	}
+
+	fireTestEntry(t, hook, newTestEntry("first", nil))
+	fireTestEntry(t, hook, newTestEntry("second", nil))
+	for _, msg := range []string{"first", "second"} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if want := wantSyslogMessage(hook, "-", msg) + "\n"; line != want {
+			t.Errorf("message = %q, want %q", line, want)
+		}
+	}
+}
+
+func TestSyslogHookUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	hook := newTestSyslogHook(t, "udp://"+conn.LocalAddr().String())
+
+	fireTestEntry(t, hook, newTestEntry("multi\nline", logrus.Fields{"gpu": 0}))
+	got := readDatagram(t, conn)
+	if want := wantSyslogMessage(hook, `[fields@32473 gpu="0"]`, "multi\nline"); got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
+
+func TestSyslogHookTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	hook := newTestSyslogHook(t, "tcp://"+l.Addr().String())
+	r := acceptStream(t, l)
+
+	// the messages with newline are kept whole by the octet counting
+	msgs := []string{"first\nline", "second"}
+	for _, msg := range msgs {
+		fireTestEntry(t, hook, newTestEntry(msg, nil))
+	}
+	for _, msg := range msgs {
+		if got, want := readOctetCounted(t, r), wantSyslogMessage(hook, "-", msg); got != want {
+			t.Errorf("message = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSyslogHookRedial(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	hook := newTestSyslogHook(t, "tcp://"+l.Addr().String())
+	acceptStream(t, l)
+	// the server closed the connection, the next write fails and the hook redials
+	hook.conn.Close()
+
+	fireTestEntry(t, hook, newTestEntry("after restart", nil))
+	r := acceptStream(t, l)
+	if got, want := readOctetCounted(t, r), wantSyslogMessage(hook, "-", "after restart"); got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
+
+// restartTestServer closes the datagram server at path and listens again, the hook dialed before fails
+// to write and the dial fails until listen is called
+func restartTestServer(t *testing.T, conn net.PacketConn, path string) (listen func() net.PacketConn) {
+	t.Helper()
+	conn.Close()
+	os.Remove(path)
+	return func() net.PacketConn {
+		conn, err := net.ListenPacket("unixgram", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+}
+
+func TestSyslogHookRedialBackoff(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	setTime := mockTime(t, now)
+	path := testSocketPath(t)
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook := newTestSyslogHook(t, "unix://"+path)
+	listen := restartTestServer(t, conn, path)
+
+	if err := hook.Fire(newTestEntry("server down", nil)); err == nil {
+		t.Fatal("Fire should fail when the server is down")
+	}
+	conn = listen()
+	// the server is back, but the hook does not dial until the backoff elapses
+	if err := hook.Fire(newTestEntry("in backoff", nil)); err == nil || hook.conn != nil {
+		t.Fatalf("Fire should not dial in the backoff, err %v", err)
+	}
+	setTime(now.Add(minRedialInterval))
+	fireTestEntry(t, hook, newTestEntry("after backoff", nil))
+	if got, want := readDatagram(t, conn), wantSyslogMessage(hook, "-", "after backoff"); got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
+
+func TestRedialBackoff(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockTime(t, now)
+	b := &redialBackoff{}
+	if err := b.wait(); err != nil {
+		t.Errorf("wait without failure: %v", err)
+	}
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		b.failed()
+		if b.interval != want || !b.next.Equal(now.Add(want)) || b.wait() == nil {
+			t.Errorf("interval = %v, next = %v, want %v", b.interval, b.next, want)
+		}
+	}
+	b.interval = maxRedialInterval
+	b.failed()
+	if b.interval != maxRedialInterval {
+		t.Errorf("interval = %v, want %v", b.interval, maxRedialInterval)
+	}
+	b.reset()
+	if err := b.wait(); err != nil || b.interval != 0 {
+		t.Errorf("wait after reset: %v, interval %v", err, b.interval)
+	}
+}
+
+func TestNewSyslogHookInvalidAddress(t *testing.T) {
+	for _, address := range []string{"", "file:///dev/log", "udp://", "unix://", "tcp://%zz"} {
+		if _, err := newSyslogHook(address, logrus.InfoLevel); err == nil {
+			t.Errorf("newSyslogHook(%q) should fail", address)
+		}
+	}
+}
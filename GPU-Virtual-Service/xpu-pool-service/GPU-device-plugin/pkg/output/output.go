@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package output prints the results of the command line tools as aligned tables or json
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// Table prints the results as columns aligned under a header
+	Table = "table"
+	// JSON prints the results as indented json
+	JSON = "json"
+
+	tabPadding = 2
+)
+
+// CheckFormat checks the output format is table or json
+func CheckFormat(format string) error {
+	if format != Table && format != JSON {
+		return fmt.Errorf("unsupported output format %q, use %s or %s", format, Table, JSON)
+	}
+	return nil
+}
+
+// PrintJSON prints v as indented json
+func PrintJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// PrintTable prints the rows aligned in columns under the header
+func PrintTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, tabPadding, ' ', 0)
+	if _, err := fmt.Fprintln(tw, strings.Join(header, "\t")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
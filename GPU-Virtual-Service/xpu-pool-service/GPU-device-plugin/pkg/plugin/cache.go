@@ -6,6 +6,7 @@
 package plugin
 
 import (
+	"context"
 	"sync"
 
 	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
@@ -58,6 +59,13 @@ func (d *DeviceCache) Stop() {
 	close(d.stopCh)
 }
 
+// Run waits until ctx is cancelled and stops the started health check and notify loop
+func (d *DeviceCache) Run(ctx context.Context) error {
+	<-ctx.Done()
+	d.Stop()
+	return nil
+}
+
 // GetCache get xpu devices cache
 func (d *DeviceCache) GetCache() []*xpu.Device {
 	return d.cache
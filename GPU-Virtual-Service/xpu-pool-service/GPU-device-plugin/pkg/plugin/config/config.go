@@ -5,6 +5,8 @@
 // Package config defines configure for vxpu device plugin
 package config
 
+import "time"
+
 var (
 	// DeviceSplitCount count of vxpu split from a physical xpu
 	DeviceSplitCount uint
@@ -16,4 +18,16 @@ var (
 	GPUTypeConfig string
 	// GPUTypeMap mapping between gpu types and abbreviations
 	GPUTypeMap map[string]string
+	// HistoryRetention how long the usage history is kept in memory, 0 disables the history
+	HistoryRetention time.Duration
+	// SampleInterval interval of sampling the usage of xpus and vxpus
+	SampleInterval time.Duration
+	// EnableReflection whether register the grpc reflection service on the plugin and pids sockets
+	EnableReflection bool
+	// ViolationActions comma separated actions taken when a vxpu exceeds its limit, empty disables the check
+	ViolationActions string
+	// ViolationWindow how long a vxpu exceeds its limit before the actions are taken
+	ViolationWindow time.Duration
+	// ViolationCoreTolerance percentage of core utilization allowed above the core limit
+	ViolationCoreTolerance int64
 )
@@ -17,6 +17,9 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 
 	"huawei.com/vxpu-device-plugin/pkg/lock"
@@ -25,6 +28,7 @@ import (
 	"huawei.com/vxpu-device-plugin/pkg/plugin/types"
 	"huawei.com/vxpu-device-plugin/pkg/plugin/util"
 	"huawei.com/vxpu-device-plugin/pkg/plugin/xpu"
+	"huawei.com/vxpu-device-plugin/pkg/supervisor"
 )
 
 const (
@@ -33,6 +37,8 @@ const (
 	dialTimeout       = 5
 	pluginNotify      = "plugin"
 	pluginSockPerm    = 0600
+	// devicePluginService full name of the device plugin grpc service
+	devicePluginService = "v1beta1.DevicePlugin"
 )
 
 // DevicePlugin implements the Kubernetes device plugin API
@@ -41,9 +47,11 @@ type DevicePlugin struct {
 	resourceName string
 	socket       string
 
-	server *grpc.Server
-	health chan *xpu.Device
-	stop   chan interface{}
+	server       *grpc.Server
+	healthServer *health.Server
+	health       chan *xpu.Device
+	stop         chan interface{}
+	crashed      chan error
 }
 
 // NewDevicePlugin returns an initialized DevicePlugin
@@ -53,17 +61,29 @@ func NewDevicePlugin(resourceName string, deviceCache *DeviceCache, socket strin
 		resourceName: resourceName,
 		socket:       socket,
 
+		// healthServer is shared by the restarts, it is internally synchronized so that
+		// ListAndWatch may update the serving status while the plugin is stopping
+		healthServer: health.NewServer(),
+
 		// These will be reinitialized every time the plugin server is restarted.
-		server: nil,
-		health: nil,
-		stop:   nil,
+		server:  nil,
+		health:  nil,
+		stop:    nil,
+		crashed: nil,
 	}
 }
 
 func (m *DevicePlugin) initialize() {
 	m.server = grpc.NewServer([]grpc.ServerOption{}...)
+	m.healthServer.Resume()
+	m.setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(m.server, m.healthServer)
+	if config.EnableReflection {
+		reflection.Register(m.server)
+	}
 	m.health = make(chan *xpu.Device)
 	m.stop = make(chan interface{})
+	m.crashed = make(chan error, 1)
 }
 
 func (m *DevicePlugin) cleanup() {
@@ -71,6 +91,7 @@ func (m *DevicePlugin) cleanup() {
 	m.server = nil
 	m.health = nil
 	m.stop = nil
+	m.crashed = nil
 }
 
 // Start starts the gRPC server, registers the device plugin with the Kubelet,
@@ -95,9 +116,27 @@ func (m *DevicePlugin) Start() error {
 	log.Infof("Registered device plugin for '%s' with Kubelet", m.resourceName)
 
 	m.deviceCache.AddNotifyChannel(pluginNotify, m.health)
+	m.updateServingStatus()
 	return nil
 }
 
+func (m *DevicePlugin) setServingStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	// the empty service name stands for the overall health of the server
+	m.healthServer.SetServingStatus("", status)
+	m.healthServer.SetServingStatus(devicePluginService, status)
+}
+
+// updateServingStatus the device plugin is serving as long as one of the devices is healthy
+func (m *DevicePlugin) updateServingStatus() {
+	for _, dev := range m.Devices() {
+		if dev.Health == v1beta1.Healthy {
+			m.setServingStatus(healthpb.HealthCheckResponse_SERVING)
+			return
+		}
+	}
+	m.setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
 // Stop stops the gRPC server.
 func (m *DevicePlugin) Stop() {
 	if m == nil || m.server == nil {
@@ -105,6 +144,7 @@ func (m *DevicePlugin) Stop() {
 	}
 	log.Infof("Stopping to serve '%s' on %s", m.resourceName, m.socket)
 	m.deviceCache.RemoveNotifyChannel(pluginNotify)
+	m.healthServer.Shutdown()
 	m.server.Stop()
 	if err := os.Remove(m.socket); err != nil && !os.IsNotExist(err) {
 		log.Errorf("remove sock error: %v, path: %s", err, m.socket)
@@ -112,6 +152,29 @@ func (m *DevicePlugin) Stop() {
 	m.cleanup()
 }
 
+// Run serves the device plugin until ctx is cancelled, the plugin is restarted
+// every time restart is notified
+func (m *DevicePlugin) Run(ctx context.Context, restart <-chan struct{}) error {
+	for {
+		if err := m.Start(); err != nil {
+			return err
+		}
+		supervisor.Ready(ctx)
+		crashed := m.crashed
+		select {
+		case <-ctx.Done():
+			m.Stop()
+			return nil
+		case <-restart:
+			log.Infof("Restarting device plugin for '%s'", m.resourceName)
+			m.Stop()
+		case err := <-crashed:
+			m.Stop()
+			return err
+		}
+	}
+}
+
 // serve starts the gRPC server of the device plugin.
 func (m *DevicePlugin) serve() error {
 	err := os.Remove(m.socket)
@@ -130,12 +193,13 @@ func (m *DevicePlugin) serve() error {
 
 	v1beta1.RegisterDevicePluginServer(m.server, m)
 
+	server, crashed := m.server, m.crashed
 	go func() {
 		lastCrashTime := time.Now()
 		restartCount := 0
 		for {
 			log.Infof("Starting GRPC server for '%s'", m.resourceName)
-			err := m.server.Serve(sock)
+			err := server.Serve(sock)
 			if err == nil {
 				break
 			}
@@ -145,7 +209,9 @@ func (m *DevicePlugin) serve() error {
 			// restart if it has not been too often
 			// i.e. if server has crashed more than 5 times and it didn't last more than one hour each time
 			if restartCount > grpcServeTryCount {
-				log.Fatalf("GRPC server for '%s' has repeatedly crashed recently. Quitting", m.resourceName)
+				log.Errorf("GRPC server for '%s' has repeatedly crashed recently. Quitting", m.resourceName)
+				crashed <- fmt.Errorf("grpc server for '%s' has repeatedly crashed: %v", m.resourceName, err)
+				return
 			}
 			timeSinceLastCrash := time.Since(lastCrashTime).Seconds()
 			lastCrashTime = time.Now()
@@ -217,6 +283,7 @@ func (m *DevicePlugin) ListAndWatch(e *v1beta1.Empty, s v1beta1.DevicePlugin_Lis
 			// Caution: there is no way to recover from the Unhealthy state.
 			// d.Health -> v1beta1.Unhealthy, no need do it, since notifyLoop() in cache.go has done it
 			log.Warningf("'%s' device marked unhealthy: %s", m.resourceName, d.ID)
+			m.updateServingStatus()
 			_ = s.Send(&v1beta1.ListAndWatchResponse{Devices: m.apiDevices()})
 		}
 	}
@@ -339,39 +406,40 @@ func (m *DevicePlugin) Allocate(ctx context.Context, reqs *v1beta1.AllocateReque
 		log.Errorln("user pod doesn't specify volcano scheduler")
 		return &v1beta1.AllocateResponse{}, errors.New("user pod doesn't specify volcano scheduler")
 	}
-	log.Infoln("Allocate pod", current.Name)
+	podLog := log.WithPodUID(string(current.UID))
+	podLog.Infoln("Allocate pod", current.Name)
 
 	for idx := range reqs.ContainerRequests {
 		curContainer, devReq, err := util.GetNextDeviceRequest(xpu.DeviceType, *current)
 		if err != nil {
-			log.Errorln("get device from annotation failed", err.Error())
+			podLog.Errorln("get device from annotation failed", err.Error())
 			util.PodAllocationFailed(nodename, current)
 			return &v1beta1.AllocateResponse{}, err
 		}
-		log.Infoln("deviceAllocateFromAnnotation=", devReq)
+		podLog.Infoln("deviceAllocateFromAnnotation=", devReq)
 		if len(devReq) != len(reqs.ContainerRequests[idx].DevicesIDs) {
-			log.Errorln("device number not matched", devReq, reqs.ContainerRequests[idx].DevicesIDs)
+			podLog.Errorln("device number not matched", devReq, reqs.ContainerRequests[idx].DevicesIDs)
 			util.PodAllocationFailed(nodename, current)
 			return &v1beta1.AllocateResponse{}, errors.New("device number not matched")
 		}
 
 		err = util.EraseNextDeviceTypeFromAnnotation(xpu.DeviceType, *current)
 		if err != nil {
-			log.Errorln("Erase annotation failed", err.Error())
+			podLog.Errorln("Erase annotation failed", err.Error())
 			util.PodAllocationFailed(nodename, current)
 			return &v1beta1.AllocateResponse{}, err
 		}
 
 		err = createDirAndWriteFile(string(current.UID), curContainer.Name, devReq)
 		if err != nil {
-			log.Errorf("create dir and write file error: %v, podId: %s, containerName: %s",
+			podLog.Errorf("create dir and write file error: %v, podId: %s, containerName: %s",
 				err, string(current.UID), curContainer.Name)
 			return &v1beta1.AllocateResponse{}, err
 		}
 		response := createContainerAllocateResponse(string(current.UID), curContainer.Name, devReq)
 		responses.ContainerResponses = append(responses.ContainerResponses, response)
 	}
-	log.Infoln("Allocate Response", responses.ContainerResponses)
+	podLog.Infoln("Allocate Response", responses.ContainerResponses)
 	util.PodAllocationTrySuccess(nodename, current)
 	return &responses, nil
 }
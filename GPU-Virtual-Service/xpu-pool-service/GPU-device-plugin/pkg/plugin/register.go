@@ -6,6 +6,8 @@
 package plugin
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -40,11 +42,6 @@ func NewDeviceRegister(deviceCache *DeviceCache) *DeviceRegister {
 	}
 }
 
-// Start register and patch periodically
-func (r *DeviceRegister) Start() {
-	go r.watchAndRegister()
-}
-
 func (r *DeviceRegister) apiDevices() []*types.DeviceInfo {
 	devs := r.deviceCache.GetCache()
 	return xpu.GetDeviceInfo(devs)
@@ -97,7 +94,8 @@ func (r *DeviceRegister) initPatchNodeVxpuUsed() {
 	}
 }
 
-func (r *DeviceRegister) watchAndRegister() {
+// Run register and patch periodically until ctx is cancelled
+func (r *DeviceRegister) Run(ctx context.Context) error {
 	log.Infof("into watchAndRegister")
 	r.initPatchNodeVxpuUsed()
 	if len(config.GPUTypeConfig) != 0 {
@@ -105,20 +103,24 @@ func (r *DeviceRegister) watchAndRegister() {
 	}
 	lastSucceed := true
 	for {
+		interval := registerInterval
 		err := r.registerInAnnotation()
 		if err != nil {
 			if lastSucceed == false {
-				break
+				return fmt.Errorf("register vxpu failed twice: %v", err)
 			}
 			lastSucceed = false
 			log.Errorln("register vxpu failed once, try again.")
-			time.Sleep(time.Second * failRetryInterval)
+			interval = failRetryInterval
 		} else {
 			lastSucceed = true
-			time.Sleep(time.Second * registerInterval)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Second * time.Duration(interval)):
 		}
 	}
-	log.Fatalln("register vxpu failed twice, exit program!")
 }
 
 func loadGPUTypeConf() {
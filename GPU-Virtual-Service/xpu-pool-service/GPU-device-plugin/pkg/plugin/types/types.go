@@ -49,6 +49,15 @@ type DeviceInfo struct {
 	Numa   int32  `protobuf:"varint,7,opt,name=numa,proto3" json:"numa,omitempty"`
 }
 
+// DeviceUsed description of the resources of one xpu used by vxpus
+type DeviceUsed struct {
+	Index     int32
+	Id        string
+	Count     int32
+	Usedmem   int32
+	Usedcores int32
+}
+
 // XPUDevice description of xpu
 type XPUDevice struct {
 	Index             int32
@@ -66,7 +75,10 @@ type XPUDevice struct {
 	FrameworkVersion  int
 	PowerUsage        uint32
 	Temperature       uint32
-	VxpuDeviceList    VxpuDevices
+	// CoreUtilizationUnsupported the core utilization of the xpu and its vxpus is not reported by the driver,
+	// such as the MIG device, and XpuUtilization and VxpuCoreUtilization are always 0
+	CoreUtilizationUnsupported bool
+	VxpuDeviceList             VxpuDevices
 }
 
 // VxpuDevices description of all vxpus in the pod
@@ -77,12 +89,16 @@ type VxpuDevice struct {
 	Id                    string
 	GpuId                 string
 	PodUID                string
+	PodName               string
+	PodNamespace          string
 	ContainerName         string
 	VxpuMemoryUsed        uint64
 	VxpuMemoryUtilization float64
 	VxpuCoreUtilization   float64
 	VxpuMemoryLimit       int64
 	VxpuCoreLimit         int64
+	MemoryLimitExceeded   bool
+	CoreLimitExceeded     bool
 }
 
 // ProcessUsage description of process usage on xpu
@@ -91,10 +107,58 @@ type ProcessUsage struct {
 	ProcessCoreUtilization uint64
 }
 
+// ContainerProcess description of a process running on xpu in the container
+type ContainerProcess struct {
+	HostPid                uint32
+	ContainerPid           uint32
+	Command                string
+	GpuId                  string
+	ProcessMemoryUsed      uint64
+	ProcessCoreUtilization uint64
+}
+
+// ContainerProcesses description of all xpu processes in the container
+type ContainerProcesses []ContainerProcess
+
+// UsageSample description of one usage sample of xpu or vxpu
+type UsageSample struct {
+	Timestamp         int64
+	CoreUtilization   float64
+	MemoryUsed        uint64
+	MemoryUtilization float64
+	PowerUsage        uint32
+	Temperature       uint32
+}
+
+// UsageStatistics description of the aggregation of a usage metric in a time range
+type UsageStatistics struct {
+	Min float64
+	Avg float64
+	Max float64
+	P95 float64
+}
+
+// UsageHistory description of the usage history of xpu or vxpu in a time range
+type UsageHistory struct {
+	Id                string
+	GpuId             string
+	Type              string
+	PodUID            string
+	ContainerName     string
+	Samples           []UsageSample
+	CoreUtilization   UsageStatistics
+	MemoryUsed        UsageStatistics
+	MemoryUtilization UsageStatistics
+	PowerUsage        UsageStatistics
+	Temperature       UsageStatistics
+}
+
 // DeviceUsageInfo description of device usage
 type DeviceUsageInfo struct {
-	CoreUtil    uint32
-	MemUtil     uint32
-	PowerUsage  uint32
-	Temperature uint32
+	CoreUtil uint32
+	// CoreUtilUnsupported CoreUtil and the core utilization of the processes are not reported by the driver
+	CoreUtilUnsupported bool
+	MemUtil             uint32
+	PowerUsage          uint32
+	Temperature         uint32
 }
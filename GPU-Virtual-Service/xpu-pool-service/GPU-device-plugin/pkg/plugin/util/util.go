@@ -28,7 +28,8 @@ import (
 )
 
 const (
-	deviceLength = 7
+	deviceLength     = 7
+	deviceUsedLength = 5
 	// PodAnnotationMaxLength pod annotation max data length 2MB
 	PodAnnotationMaxLength = 1024 * 1024
 	// BaseDec base size
@@ -46,11 +47,26 @@ func GetNode(nodename string) (*v1.Node, error) {
 	return lock.GetClient().CoreV1().Nodes().Get(context.Background(), nodename, metav1.GetOptions{})
 }
 
+// ListNodes list k8s nodes according to list options
+func ListNodes(opts metav1.ListOptions) (*v1.NodeList, error) {
+	return lock.GetClient().CoreV1().Nodes().List(context.Background(), opts)
+}
+
 // ListPods list k8s pods according to list options
 func ListPods(opts metav1.ListOptions) (*v1.PodList, error) {
 	return lock.GetClient().CoreV1().Pods("").List(context.Background(), opts)
 }
 
+// ListNamespacedPods list k8s pods in the namespace according to list options, all namespaces if it is empty
+func ListNamespacedPods(namespace string, opts metav1.ListOptions) (*v1.PodList, error) {
+	return lock.GetClient().CoreV1().Pods(namespace).List(context.Background(), opts)
+}
+
+// GetPod get k8s pod object according to namespace and pod name
+func GetPod(namespace, name string) (*v1.Pod, error) {
+	return lock.GetClient().CoreV1().Pods(namespace).Get(context.Background(), name, metav1.GetOptions{})
+}
+
 // GetPendingPod get k8s pod object according to node name and types.DeviceBindAllocating status
 func GetPendingPod(nodename string) (*v1.Pod, error) {
 	podlist, err := ListPods(metav1.ListOptions{})
@@ -62,7 +78,7 @@ func GetPendingPod(nodename string) (*v1.Pod, error) {
 		oldestBindTime = uint64(math.MaxUint64)
 	)
 	for _, p := range podlist.Items {
-		bindTime, ok := getBindTime(p)
+		bindTime, ok := GetBindTime(p)
 		if !ok {
 			continue
 		}
@@ -83,7 +99,8 @@ func GetPendingPod(nodename string) (*v1.Pod, error) {
 	return &oldestPod, nil
 }
 
-func getBindTime(pod v1.Pod) (uint64, bool) {
+// GetBindTime get the bind time of a pod from its annotation in unix seconds
+func GetBindTime(pod v1.Pod) (uint64, bool) {
 	assumeTimeStr, ok := pod.Annotations[types.DeviceBindTime]
 	if !ok {
 		return math.MaxUint64, false
@@ -218,6 +235,41 @@ func DecodeNodeDevices(str string) map[string]*types.XPUDevice {
 	return deviceMap
 }
 
+// DecodeNodeDevicesUsed decode the used resources of a node's xpus from string
+func DecodeNodeDevicesUsed(str string) map[string]*types.DeviceUsed {
+	usedMap := make(map[string]*types.DeviceUsed)
+	for _, val := range strings.Split(str, ":") {
+		if !strings.Contains(val, ",") {
+			continue
+		}
+		items := strings.Split(val, ",")
+		if len(items) != deviceUsedLength {
+			log.Warningf("device used string is wrong, device: %s", items)
+			continue
+		}
+		values := make([]int32, 0, len(items))
+		for _, item := range append([]string{items[0]}, items[2:]...) {
+			v, err := strconv.Atoi(item)
+			if err != nil {
+				break
+			}
+			values = append(values, int32(v))
+		}
+		if len(values) != deviceUsedLength-1 {
+			log.Warningf("device used string is wrong, device: %s", items)
+			continue
+		}
+		usedMap[items[1]] = &types.DeviceUsed{
+			Index:     values[0],
+			Id:        items[1],
+			Count:     values[1],
+			Usedmem:   values[2],
+			Usedcores: values[3],
+		}
+	}
+	return usedMap
+}
+
 // DecodeContainerDevices decode xpu resource request of a container from string
 func DecodeContainerDevices(str string) types.ContainerDevices {
 	if len(str) == 0 {
@@ -232,12 +284,12 @@ func DecodeContainerDevices(str string) types.ContainerDevices {
 		fields := strings.Split(val, ",")
 		tmpdev := types.ContainerDevice{}
 		if len(fields) != reflect.TypeOf(tmpdev).NumField() {
-			log.Fatalln("DecodeContainerDevices invalid parameter:", str)
+			log.Errorln("DecodeContainerDevices invalid parameter:", str)
 			return types.ContainerDevices{}
 		}
 		index, err := strconv.Atoi(fields[0])
 		if err != nil {
-			log.Fatalln("DecodeContainerDevices invalid parameter:", str)
+			log.Errorln("DecodeContainerDevices invalid parameter:", str)
 			return types.ContainerDevices{}
 		}
 		tmpdev.Index = int32(index)
@@ -245,19 +297,19 @@ func DecodeContainerDevices(str string) types.ContainerDevices {
 		tmpdev.Type = fields[2]
 		devmem, err := strconv.Atoi(fields[3])
 		if err != nil {
-			log.Fatalln("DecodeContainerDevices invalid parameter:", str)
+			log.Errorln("DecodeContainerDevices invalid parameter:", str)
 			return types.ContainerDevices{}
 		}
 		tmpdev.Usedmem = int32(devmem)
 		devcores, err := strconv.Atoi(fields[4])
 		if err != nil {
-			log.Fatalln("DecodeContainerDevices invalid parameter:", str)
+			log.Errorln("DecodeContainerDevices invalid parameter:", str)
 			return types.ContainerDevices{}
 		}
 		tmpdev.Usedcores = int32(devcores)
 		vid, err := strconv.Atoi(fields[5])
 		if err != nil {
-			log.Fatalln("DecodeContainerDevices invalid parameter:", str)
+			log.Errorln("DecodeContainerDevices invalid parameter:", str)
 			return types.ContainerDevices{}
 		}
 		tmpdev.Vid = int32(vid)
@@ -279,7 +331,8 @@ func DecodePodDevices(str string) types.PodDevices {
 	return pd
 }
 
-func getContainerIdxByVxpuIdx(p *v1.Pod, vxpuIdx int) int {
+// GetContainerIdxByVxpuIdx get the index in pod spec of the container that requests the vxpuIdx-th vxpus
+func GetContainerIdxByVxpuIdx(p *v1.Pod, vxpuIdx int) int {
 	foundVxpuIdx := -1
 	for i, container := range p.Spec.Containers {
 		_, ok := container.Resources.Limits[xpu.VxpuNumber]
@@ -334,7 +387,7 @@ func GetNextDeviceRequest(dtype string, p v1.Pod) (v1.Container, types.Container
 			}
 		}
 		if found {
-			idx := getContainerIdxByVxpuIdx(&p, vxpuIdx)
+			idx := GetContainerIdxByVxpuIdx(&p, vxpuIdx)
 			if idx != -1 {
 				return p.Spec.Containers[idx], res, nil
 			} else {
@@ -397,7 +450,7 @@ func PodAllocationSuccess(nodeName string, pod *v1.Pod) {
 	newannos[types.DeviceBindPhase] = types.DeviceBindSuccess
 	err := PatchPodAnnotations(pod, newannos)
 	if err != nil {
-		log.Errorln("patchPodAnnotations failed:%v", err.Error())
+		log.Errorf("patchPodAnnotations failed:%v", err.Error())
 	}
 	err = lock.ReleaseNodeLock(nodeName, types.VXPULockName)
 	if err != nil {
@@ -411,7 +464,7 @@ func PodAllocationFailed(nodeName string, pod *v1.Pod) {
 	newannos[types.DeviceBindPhase] = types.DeviceBindFailed
 	err := PatchPodAnnotations(pod, newannos)
 	if err != nil {
-		log.Errorln("patchPodAnnotations failed:%v", err.Error())
+		log.Errorf("patchPodAnnotations failed:%v", err.Error())
 	}
 	err = lock.ReleaseNodeLock(nodeName, types.VXPULockName)
 	if err != nil {
@@ -481,7 +534,7 @@ func GetXPUs() (map[string]*types.XPUDevice, error) {
 	if !ok {
 		errMsg := fmt.Sprintf("node %s annotation %s is not exists",
 			config.NodeName, xpu.NodeVXPURegister)
-		log.Errorf(errMsg)
+		log.Errorln(errMsg)
 		return nil, errors.New(errMsg)
 	}
 	ip := getNodeIp(node)
@@ -518,7 +571,7 @@ func GetVxpus() (types.VxpuDevices, map[string][]uint32, error) {
 			errMsg := fmt.Sprintf(
 				"pod status error: %v, container status len: %d",
 				pod.Status.Phase, len(pod.Status.ContainerStatuses))
-			log.Errorf(errMsg)
+			log.Errorln(errMsg)
 			continue
 		}
 		pdevices := DecodePodDevices(pod.Annotations[xpu.AssignedIDs])
@@ -545,6 +598,8 @@ func GetVxpus() (types.VxpuDevices, map[string][]uint32, error) {
 					Id:              fmt.Sprintf("%s-%d", pdevices[pi][i].UUID, pdevices[pi][i].Vid),
 					GpuId:           pdevices[pi][i].UUID,
 					PodUID:          string(pod.UID),
+					PodName:         pod.Name,
+					PodNamespace:    pod.Namespace,
 					ContainerName:   cs.Name,
 					VxpuMemoryLimit: mem * 1024,
 					VxpuCoreLimit:   core,
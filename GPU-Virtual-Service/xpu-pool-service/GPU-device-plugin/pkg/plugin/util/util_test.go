@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"huawei.com/vxpu-device-plugin/pkg/plugin/types"
+)
+
+func TestDecodeNodeDevicesUsed(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		want map[string]*types.DeviceUsed
+	}{
+		{name: "empty", str: "", want: map[string]*types.DeviceUsed{}},
+		{
+			name: "two devices",
+			str:  "0,GPU-0,2,4096,60:1,GPU-1,0,0,0:",
+			want: map[string]*types.DeviceUsed{
+				"GPU-0": {Index: 0, Id: "GPU-0", Count: 2, Usedmem: 4096, Usedcores: 60},
+				"GPU-1": {Index: 1, Id: "GPU-1"},
+			},
+		},
+		{
+			name: "without the trailing separator",
+			str:  "3,GPU-3,1,1024,30",
+			want: map[string]*types.DeviceUsed{
+				"GPU-3": {Index: 3, Id: "GPU-3", Count: 1, Usedmem: 1024, Usedcores: 30},
+			},
+		},
+		{
+			name: "skips the malformed devices",
+			str:  "0,GPU-0,1,1024:1,GPU-1,x,1024,30:2,GPU-2,1,1024,30,9:3,GPU-3,1,512,10:",
+			want: map[string]*types.DeviceUsed{
+				"GPU-3": {Index: 3, Id: "GPU-3", Count: 1, Usedmem: 512, Usedcores: 10},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DecodeNodeDevicesUsed(tt.str)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeNodeDevicesUsed(%q) = %v, want %v", tt.str, got, tt.want)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
@@ -41,6 +42,10 @@ const (
 	nvidiaXidErrorPageFault         = 31
 	nvidiaXidErrorStoppedProcessing = 43
 	nvidiaXidErrorPreemptiveCleanup = 45
+	// allInstances the instance id of the event which affects all the instances of the GPU
+	allInstances = 0xFFFFFFFF
+	// migNameSeparator separates the GPU name and the MIG profile in the name of MIG device
+	migNameSeparator = " MIG "
 	// VisibleDevices visible nvidia devices env
 	VisibleDevices = "NVIDIA_VISIBLE_DEVICES"
 	// VxpuConfigFileName vxpu config file name
@@ -63,6 +68,14 @@ const (
 var (
 	// DevShmMount /dev/shm/ mount instance
 	DevShmMount *v1beta1.Mount = nil
+
+	// handleCache the devices exposed by the plugin, loaded by Devices and reloaded once the MIG devices
+	// are reconfigured, the usage of each device is queried without walking all the GPUs
+	handleCache struct {
+		sync.Mutex
+		handles []deviceHandle
+		loaded  bool
+	}
 )
 
 // Init initialize gpu nvml
@@ -93,16 +106,137 @@ func check(ret gonvml.NvmlRetType) {
 	}
 }
 
+// deviceHandle is a device exposed by the plugin, the whole GPU or a MIG device of the GPU
+type deviceHandle struct {
+	gonvml.Device
+	// parent the GPU of the MIG device, nil for the whole GPU
+	parent gonvml.Device
+	// gpuIndex the index of the GPU
+	gpuIndex int
+}
+
+// board returns the GPU of the device, whose power and temperature are shared by its MIG devices
+func (d deviceHandle) board() gonvml.Device {
+	if d.parent != nil {
+		return d.parent
+	}
+	return d.Device
+}
+
+// getDeviceHandles returns the devices exposed by the plugin, the index of device is its logic id.
+// The MIG devices take the place of the GPU whose MIG mode is enabled.
+func getDeviceHandles() ([]deviceHandle, gonvml.NvmlRetType) {
+	cnt, ret := gonvml.DeviceGetCount()
+	if ret != gonvml.Success {
+		return nil, ret
+	}
+	var handles []deviceHandle
+	for i := 0; i < cnt; i++ {
+		dev, ret := gonvml.DeviceGetHandleByIndex(i)
+		if ret != gonvml.Success {
+			return nil, ret
+		}
+		migEnabled, migDevices, ret := getMigDevices(dev)
+		if ret != gonvml.Success {
+			return nil, ret
+		}
+		if !migEnabled {
+			handles = append(handles, deviceHandle{Device: dev, gpuIndex: i})
+			continue
+		}
+		if len(migDevices) == 0 {
+			log.Warningf("MIG mode of GPU %d is enabled but no MIG device is created, the GPU is not exposed", i)
+		}
+		for _, migDevice := range migDevices {
+			handles = append(handles, deviceHandle{Device: migDevice, parent: dev, gpuIndex: i})
+		}
+	}
+	return handles, gonvml.Success
+}
+
+// refreshDeviceHandles reloads the devices exposed by the plugin into the cache
+func refreshDeviceHandles() ([]deviceHandle, gonvml.NvmlRetType) {
+	handleCache.Lock()
+	defer handleCache.Unlock()
+	return refreshDeviceHandlesLocked()
+}
+
+func refreshDeviceHandlesLocked() ([]deviceHandle, gonvml.NvmlRetType) {
+	handles, ret := getDeviceHandles()
+	if ret != gonvml.Success {
+		return nil, ret
+	}
+	handleCache.handles, handleCache.loaded = handles, true
+	return handles, gonvml.Success
+}
+
+// getDeviceHandle returns the device of the logic id from the cache, the cache is reloaded when the device
+// is not found or the MIG device has been destroyed by the reconfiguration of MIG
+func getDeviceHandle(index int32) (deviceHandle, error) {
+	handleCache.Lock()
+	defer handleCache.Unlock()
+	handles := handleCache.handles
+	if handleCache.loaded && index >= 0 && int(index) < len(handles) && handles[index].valid() {
+		return handles[index], nil
+	}
+	handles, ret := refreshDeviceHandlesLocked()
+	if ret != gonvml.Success {
+		log.Errorf("get device handles error: %v", ret)
+		return deviceHandle{}, fmt.Errorf("getDeviceHandles failed: %v", ret)
+	}
+	if index < 0 || int(index) >= len(handles) {
+		return deviceHandle{}, fmt.Errorf("device of index %d not found", index)
+	}
+	return handles[index], nil
+}
+
+// valid checks whether the handle still refers to a device, the handle of the MIG device is invalidated
+// when the MIG device is destroyed, while the handle of the whole GPU is kept until NVML shuts down
+func (d deviceHandle) valid() bool {
+	if d.parent == nil {
+		return true
+	}
+	_, ret := d.GetUUID()
+	return ret == gonvml.Success
+}
+
+// getMigDevices returns whether the MIG mode of GPU is enabled and the MIG devices of the GPU,
+// the GPU which does not support MIG is regarded as disabled
+func getMigDevices(dev gonvml.Device) (bool, []gonvml.Device, gonvml.NvmlRetType) {
+	mode, _, ret := dev.GetMigMode()
+	if ret == gonvml.ErrorNotSupported || ret == gonvml.ErrorFunctionNotFound {
+		return false, nil, gonvml.Success
+	}
+	if ret != gonvml.Success || mode != gonvml.DeviceMigEnable {
+		return false, nil, ret
+	}
+	count, ret := dev.GetMaxMigDeviceCount()
+	if ret != gonvml.Success {
+		return true, nil, ret
+	}
+	var migDevices []gonvml.Device
+	for i := 0; i < count; i++ {
+		migDevice, ret := dev.GetMigDeviceHandleByIndex(i)
+		// the MIG device of the index is not created
+		if ret == gonvml.ErrorNotFound {
+			continue
+		}
+		if ret != gonvml.Success {
+			return true, nil, ret
+		}
+		migDevices = append(migDevices, migDevice)
+	}
+	return true, migDevices, gonvml.Success
+}
+
 // Devices returns a list of Devices from the DeviceManager
 func (*DeviceManager) Devices() []*Device {
-	cnt, ret := gonvml.DeviceGetCount()
+	handles, ret := refreshDeviceHandles()
 	check(ret)
 
 	var devs []*Device
-	for i := 0; i < cnt; i++ {
-		dev, ret := gonvml.DeviceGetHandleByIndex(i)
-		check(ret)
-		devs = append(devs, buildDevice(dev, int32(i)))
+	for i, handle := range handles {
+		devs = append(devs, buildDevice(handle, int32(i)))
 	}
 	return devs
 }
@@ -110,27 +244,64 @@ func (*DeviceManager) Devices() []*Device {
 func (*DeviceManager) CheckHealth(stop <-chan interface{}, devices []*Device, unhealthy chan<- *Device) {
 	checkHealth(stop, devices, unhealthy)
 }
-func buildDevice(d gonvml.Device, logicID int32) *Device {
+func buildDevice(d deviceHandle, logicID int32) *Device {
 	dev := Device{}
 	uuid, ret := d.GetUUID()
 	check(ret)
 	dev.ID = uuid
 	dev.Health = v1beta1.Healthy
 	dev.LogicID = logicID
+	dev.PhysicID = int32(d.gpuIndex)
+	if d.parent != nil {
+		log.Infof("found MIG device %s on GPU %d", uuid, d.gpuIndex)
+	}
 	return &dev
 }
 
+// migInstance identifies the MIG device by its GPU and GPU instance
+type migInstance struct {
+	gpuUUID       string
+	gpuInstanceId int
+}
+
+// getMigInstance returns the MIG instance of the device, ok is false for the whole GPU
+func getMigInstance(ndev gonvml.Device) (migInstance, gonvml.Device, bool) {
+	isMig, ret := ndev.IsMigDeviceHandle()
+	if ret != gonvml.Success || !isMig {
+		return migInstance{}, nil, false
+	}
+	parent, ret := ndev.GetDeviceHandleFromMigDeviceHandle()
+	check(ret)
+	gpuUUID, ret := parent.GetUUID()
+	check(ret)
+	gpuInstanceId, ret := ndev.GetGpuInstanceId()
+	check(ret)
+	return migInstance{gpuUUID: gpuUUID, gpuInstanceId: gpuInstanceId}, parent, true
+}
+
 // CheckHealth performs health checks on a set of devices, writing to the 'unhealthy' channel with any unhealthy devices
 func checkHealth(stop <-chan interface{}, devices []*Device, unhealthy chan<- *Device) {
 	eventSet, ret := gonvml.EventSetCreate()
 	check(ret)
 	defer gonvml.EventSetFree(eventSet)
 
+	// the events of MIG devices are registered on and sent by their GPU
+	migInstances := make(map[string]migInstance)
+	registered := make(map[string]gonvml.NvmlRetType)
 	for _, d := range devices {
 		ndev, ret := gonvml.DeviceGetHandleByUUID(d.ID)
 		check(ret)
-		// Register event for critical error
-		ret = gonvml.DeviceRegisterEvents(ndev, gonvml.EventTypeXidCriticalError, eventSet)
+		eventUUID := d.ID
+		if instance, parent, ok := getMigInstance(ndev); ok {
+			migInstances[d.ID] = instance
+			ndev, eventUUID = parent, instance.gpuUUID
+		}
+		ret, ok := registered[eventUUID]
+		if !ok {
+			// Register event for critical error
+			ret = gonvml.DeviceRegisterEvents(ndev, gonvml.EventTypeXidCriticalError, eventSet)
+			registered[eventUUID] = ret
+		}
 		if ret != gonvml.Success {
 			log.Warningf("Warning: register event for health check failed, mark it unhealthy. deviceId: %s, ret: %v", d.ID, ret)
 			unhealthy <- d
@@ -165,15 +336,25 @@ func checkHealth(stop <-chan interface{}, devices []*Device, unhealthy chan<- *D
 			continue
 		}
 		for _, d := range devices {
-			if d.ID == uuid {
+			if eventAffects(ed, uuid, d.ID, migInstances) {
 				log.Warningf("XidCriticalError: Xid=%d on Device=%s, the device will go unhealthy.", ed.EventData, d.ID)
 				unhealthy <- d
-				break
 			}
 		}
 	}
 }
 
+// eventAffects checks whether the event of the GPU with uuid affects the device, the event of the GPU
+// affects its MIG devices of the GPU instance, or all of them when the instance is not specified
+func eventAffects(ed gonvml.EventData, uuid, deviceID string, migInstances map[string]migInstance) bool {
+	instance, ok := migInstances[deviceID]
+	if !ok {
+		return deviceID == uuid
+	}
+	return instance.gpuUUID == uuid &&
+		(ed.GpuInstanceId == allInstances || int(ed.GpuInstanceId) == instance.gpuInstanceId)
+}
+
 // GetDeviceInfo create types.DeviceInfo according to Device
 func GetDeviceInfo(devs []*Device) []*types.DeviceInfo {
 	res := make([]*types.DeviceInfo, 0, len(devs))
@@ -190,12 +371,12 @@ func GetDeviceInfo(devs []*Device) []*types.DeviceInfo {
 		if ret != gonvml.Success {
 			log.Fatalln("get name failed")
 		}
-		numa, err := getNumaInformation(int(dev.LogicID))
+		numa, err := getNumaInformation(int(dev.PhysicID))
 		if err != nil {
-			log.Warningf("get numa information for device %d failed: %s", dev.LogicID, err)
+			log.Warningf("get numa information for device %d failed: %s", dev.PhysicID, err)
 		}
 		registeredMem := int32(memInfo.Total / 1024 / 1024)
-		log.Infof("nvml registered deviceId", dev.ID, "memory", registeredMem, "name", name)
+		log.Infof("nvml registered deviceId %s, memory %d, name %s", dev.ID, registeredMem, name)
 		res = append(res, &types.DeviceInfo{
 			Index:  dev.LogicID,
 			Id:     dev.ID,
@@ -210,7 +391,7 @@ func GetDeviceInfo(devs []*Device) []*types.DeviceInfo {
 }
 
 // resolveDeviceName resolve device name to abbreviations
-// example "Tesla V100-PCIE-32GB" resolve to "V100"
+// example "Tesla V100-PCIE-32GB" resolve to "V100", "NVIDIA A100-SXM4-40GB MIG 1g.5gb" resolve to "A100-MIG-1g.5gb"
 func resolveDeviceName(deviceName string) string {
 	if len(config.GPUTypeMap) != 0 {
 		abbreviation, ok := config.GPUTypeMap[deviceName]
@@ -220,6 +401,10 @@ func resolveDeviceName(deviceName string) string {
 			return abbreviation
 		}
 	}
+	// the MIG device is named as its GPU and the profile, such as "NVIDIA A100-SXM4-40GB MIG 1g.5gb"
+	if gpuName, profile, ok := strings.Cut(deviceName, migNameSeparator); ok {
+		return resolveDeviceName(gpuName) + "-MIG-" + strings.ReplaceAll(profile, " ", "")
+	}
 	pattern := `^[A-Z]+[0-9]+[A-Z]*$`
 	regex, err := regexp.Compile(pattern)
 	if err != nil {
@@ -247,10 +432,9 @@ func GetVisibleDevices(devReq types.ContainerDevices) string {
 // GetDeviceUsage get all gpu process usage
 func GetXPUUsage(index, period int32) (types.DeviceUsageInfo, map[uint32]*types.ProcessUsage, error) {
 	processMap := make(map[uint32]*types.ProcessUsage)
-	dev, ret := gonvml.DeviceGetHandleByIndex(int(index))
-	if ret != gonvml.Success {
-		log.Errorf("gonvml.DeviceGetHandleByIndex error: %v", ret)
-		return types.DeviceUsageInfo{}, nil, fmt.Errorf("gonvml.DeviceGetHandleByIndex failed: %v", ret)
+	dev, err := getDeviceHandle(index)
+	if err != nil {
+		return types.DeviceUsageInfo{}, nil, err
 	}
 	retDeviceUsageInfo, err := getDeviceUsageInfo(dev)
 	if err != nil {
@@ -266,7 +450,11 @@ func GetXPUUsage(index, period int32) (types.DeviceUsageInfo, map[uint32]*types.
 	// The default length of the array is 1624, the default position without data are filled with 0.
 	timestamp := uint64(time.Now().Unix() - int64(period*microSecond))
 	// Get the process utilization of different processes.
-	samples, ret := dev.DeviceGetProcessUtilization(timestamp)
+	var samples []gonvml.ProcessUtilizationSample
+	// The utilization of the MIG device is not supported by NVML.
+	if dev.parent == nil {
+		samples, ret = dev.DeviceGetProcessUtilization(timestamp)
+	}
 	if ret != gonvml.Success && ret != gonvml.ErrorNotFound {
 		log.Errorf("device GetProcessUtilization failed: %v", ret)
 		return types.DeviceUsageInfo{}, nil, fmt.Errorf("gonvml.DeviceGetProcessUtilization failed: %v", ret)
@@ -293,27 +481,34 @@ func GetXPUUsage(index, period int32) (types.DeviceUsageInfo, map[uint32]*types.
 	return retDeviceUsageInfo, processMap, nil
 }
 
-func getDeviceUsageInfo(dev gonvml.Device) (types.DeviceUsageInfo, error) {
-	utilization, ret := dev.GetUtilizationRates()
+func getDeviceUsageInfo(dev deviceHandle) (types.DeviceUsageInfo, error) {
+	var utilization gonvml.Utilization
+	ret := gonvml.Success
+	// The utilization of the MIG device is not supported by NVML.
+	if dev.parent == nil {
+		utilization, ret = dev.GetUtilizationRates()
+	}
 	if ret != gonvml.Success && ret != gonvml.ErrorNotFound {
 		log.Errorf("gonvml.GetUtilizationRates failed: %v", ret)
 		return types.DeviceUsageInfo{}, fmt.Errorf("gonvml.GetUtilizationRates failed: %v", ret)
 	}
-	powerUsage, ret := dev.GetPowerUsage()
+	// The power and temperature of the MIG device are those of its GPU.
+	powerUsage, ret := dev.board().GetPowerUsage()
 	if ret != gonvml.Success && ret != gonvml.ErrorNotFound {
 		log.Errorf("device GetPowerUsage failed: %v", ret)
 		return types.DeviceUsageInfo{}, fmt.Errorf("gonvml.GetPowerUsage failed: %v", ret)
 	}
-	temperature, ret := dev.GetTemperature(gonvml.NvmlTemperatureGpu)
+	temperature, ret := dev.board().GetTemperature(gonvml.NvmlTemperatureGpu)
 	if ret != gonvml.Success && ret != gonvml.ErrorNotFound {
 		log.Errorf("device GetTemperature failed: %v", ret)
 		return types.DeviceUsageInfo{}, fmt.Errorf("gonvml.GetTemperature failed: %v", ret)
 	}
 	deviceUsageInfo := types.DeviceUsageInfo{
-		CoreUtil:    utilization.Gpu,
-		MemUtil:     utilization.Memory,
-		PowerUsage:  powerUsage / milliwatts,
-		Temperature: temperature,
+		CoreUtil:            utilization.Gpu,
+		CoreUtilUnsupported: dev.parent != nil,
+		MemUtil:             utilization.Memory,
+		PowerUsage:          powerUsage / milliwatts,
+		Temperature:         temperature,
 	}
 	return deviceUsageInfo, nil
 }
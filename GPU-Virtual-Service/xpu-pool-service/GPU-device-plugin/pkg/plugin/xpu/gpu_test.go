@@ -0,0 +1,192 @@
+//go:build vgpu
+
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package xpu
+
+import (
+	"reflect"
+	"testing"
+
+	"huawei.com/vxpu-device-plugin/pkg/gonvml"
+	"huawei.com/vxpu-device-plugin/pkg/gonvml/gonvmltest"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/config"
+)
+
+// newMigGpu returns a GPU whose MIG mode is enabled with the MIG devices, the nil ones are not created
+func newMigGpu(uuid string, migDevices ...*gonvmltest.FakeDevice) *gonvmltest.FakeDevice {
+	gpu := &gonvmltest.FakeDevice{UUID: uuid, MigMode: gonvml.DeviceMigEnable, MigDevices: migDevices,
+		PowerUsage: 250000, Temperature: 60}
+	for _, migDevice := range migDevices {
+		if migDevice != nil {
+			migDevice.Parent = gpu
+		}
+	}
+	return gpu
+}
+
+// useFakeDevices replaces the devices of NVML and clears the cache of handles during the test
+func useFakeDevices(t *testing.T, devices ...*gonvmltest.FakeDevice) {
+	restore := gonvmltest.UseFakeDevices(devices)
+	t.Cleanup(func() {
+		restore()
+		handleCache.handles, handleCache.loaded = nil, false
+	})
+}
+
+// handleUUIDs returns the uuids of the handles
+func handleUUIDs(t *testing.T, handles []deviceHandle) []string {
+	t.Helper()
+	uuids := make([]string, 0, len(handles))
+	for _, h := range handles {
+		uuid, ret := h.GetUUID()
+		if ret != gonvml.Success {
+			t.Fatalf("GetUUID failed: %v", ret)
+		}
+		uuids = append(uuids, uuid)
+	}
+	return uuids
+}
+
+func TestGetDeviceHandles(t *testing.T) {
+	mig0 := &gonvmltest.FakeDevice{UUID: "MIG-0"}
+	mig2 := &gonvmltest.FakeDevice{UUID: "MIG-2"}
+	gpu1 := newMigGpu("GPU-1", mig0, nil, mig2)
+	useFakeDevices(t,
+		&gonvmltest.FakeDevice{UUID: "GPU-0"},
+		gpu1,
+		newMigGpu("GPU-2"),
+		&gonvmltest.FakeDevice{UUID: "GPU-3", Rets: map[string]gonvml.NvmlRetType{"GetMigMode": gonvml.ErrorNotSupported}},
+	)
+
+	handles, ret := getDeviceHandles()
+	if ret != gonvml.Success {
+		t.Fatalf("getDeviceHandles failed: %v", ret)
+	}
+	// the MIG devices take the place of their GPU, the GPU without MIG device is not exposed
+	if got, want := handleUUIDs(t, handles), []string{"GPU-0", "MIG-0", "MIG-2", "GPU-3"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("handles = %v, want %v", got, want)
+	}
+	gpuIndexes := []int{0, 1, 1, 3}
+	for i, h := range handles {
+		if h.gpuIndex != gpuIndexes[i] {
+			t.Errorf("gpu index of handle %d = %d, want %d", i, h.gpuIndex, gpuIndexes[i])
+		}
+	}
+	if handles[0].parent != nil || handles[1].parent != gpu1 || handles[1].board() != gpu1 {
+		t.Error("the MIG devices should be on the board of their GPU")
+	}
+}
+
+func TestGetDeviceHandlesFailed(t *testing.T) {
+	useFakeDevices(t, &gonvmltest.FakeDevice{UUID: "GPU-0",
+		Rets: map[string]gonvml.NvmlRetType{"GetMigMode": gonvml.ErrorGpuIsLost}})
+	if _, ret := getDeviceHandles(); ret != gonvml.ErrorGpuIsLost {
+		t.Errorf("getDeviceHandles = %v, want %v", ret, gonvml.ErrorGpuIsLost)
+	}
+}
+
+func TestGetDeviceHandleRefresh(t *testing.T) {
+	mig0 := &gonvmltest.FakeDevice{UUID: "MIG-0"}
+	gpu0 := newMigGpu("GPU-0", mig0)
+	useFakeDevices(t, gpu0, &gonvmltest.FakeDevice{UUID: "GPU-1"})
+	if _, ret := refreshDeviceHandles(); ret != gonvml.Success {
+		t.Fatalf("refreshDeviceHandles failed: %v", ret)
+	}
+
+	// the cached handle is used while the MIG device is not changed
+	gpu0.MigDevices = []*gonvmltest.FakeDevice{{UUID: "MIG-NEW", Parent: gpu0}}
+	if h, err := getDeviceHandle(0); err != nil || h.Device != mig0 {
+		t.Fatalf("getDeviceHandle = %v, %v, want the cached MIG-0", h.Device, err)
+	}
+
+	// the handle of the destroyed MIG device is invalid, the handles are reloaded
+	mig0.Rets = map[string]gonvml.NvmlRetType{"GetUUID": gonvml.ErrorInvalidArgument}
+	h, err := getDeviceHandle(0)
+	if err != nil {
+		t.Fatalf("getDeviceHandle failed: %v", err)
+	}
+	if uuid, _ := h.GetUUID(); uuid != "MIG-NEW" {
+		t.Errorf("getDeviceHandle = %s, want the reloaded MIG-NEW", uuid)
+	}
+	if h, err := getDeviceHandle(1); err != nil || h.parent != nil {
+		t.Errorf("getDeviceHandle(1) = %+v, %v, want GPU-1", h, err)
+	}
+	if _, err := getDeviceHandle(2); err == nil {
+		t.Error("getDeviceHandle of the unknown index should fail")
+	}
+}
+
+func TestGetXPUUsageOfMigDevice(t *testing.T) {
+	mig0 := &gonvmltest.FakeDevice{UUID: "MIG-0",
+		Processes: []gonvml.ProcessInfoV1{{Pid: 100, UsedGpuMemory: 1024}}}
+	useFakeDevices(t, newMigGpu("GPU-0", mig0))
+
+	usage, processes, err := GetXPUUsage(0, 1)
+	if err != nil {
+		t.Fatalf("GetXPUUsage failed: %v", err)
+	}
+	// the power and temperature are those of the GPU, the core utilization is not reported
+	if !usage.CoreUtilUnsupported || usage.CoreUtil != 0 || usage.PowerUsage != 250 || usage.Temperature != 60 {
+		t.Errorf("usage = %+v", usage)
+	}
+	if p := processes[100]; p == nil || p.ProcessMem != 1024 || p.ProcessCoreUtilization != 0 {
+		t.Errorf("processes = %v", processes)
+	}
+}
+
+func TestEventAffects(t *testing.T) {
+	migInstances := map[string]migInstance{
+		"MIG-0": {gpuUUID: "GPU-1", gpuInstanceId: 1},
+		"MIG-1": {gpuUUID: "GPU-1", gpuInstanceId: 2},
+	}
+	tests := []struct {
+		name          string
+		uuid          string
+		gpuInstanceId uint32
+		deviceID      string
+		want          bool
+	}{
+		{name: "event of the GPU", uuid: "GPU-0", deviceID: "GPU-0", want: true},
+		{name: "event of another GPU", uuid: "GPU-1", deviceID: "GPU-0"},
+		{name: "event of the instance", uuid: "GPU-1", gpuInstanceId: 2, deviceID: "MIG-1", want: true},
+		{name: "event of another instance", uuid: "GPU-1", gpuInstanceId: 2, deviceID: "MIG-0"},
+		{name: "event of all the instances", uuid: "GPU-1", gpuInstanceId: allInstances, deviceID: "MIG-0",
+			want: true},
+		{name: "event of the GPU of another MIG device", uuid: "GPU-0", gpuInstanceId: allInstances,
+			deviceID: "MIG-0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ed := gonvml.EventData{GpuInstanceId: tt.gpuInstanceId}
+			if got := eventAffects(ed, tt.uuid, tt.deviceID, migInstances); got != tt.want {
+				t.Errorf("eventAffects = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveDeviceName(t *testing.T) {
+	original := config.GPUTypeMap
+	defer func() { config.GPUTypeMap = original }()
+	config.GPUTypeMap = map[string]string{"NVIDIA GeForce RTX 4090": "RTX4090"}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Tesla V100-PCIE-32GB", want: "V100"},
+		{name: "NVIDIA A100-SXM4-40GB", want: "A100"},
+		{name: "NVIDIA A100-SXM4-40GB MIG 1g.5gb", want: "A100-MIG-1g.5gb"},
+		{name: "NVIDIA H100 80GB HBM3 MIG 1g.10gb+me", want: "H100-MIG-1g.10gb+me"},
+		{name: "NVIDIA GeForce RTX 4090", want: "RTX4090"},
+		{name: "NVIDIA GeForce RTX 4090 MIG 1g.6gb", want: "RTX4090-MIG-1g.6gb"},
+		{name: "Unknown Device", want: "UnknownDevice"},
+	}
+	for _, tt := range tests {
+		if got := resolveDeviceName(tt.name); got != tt.want {
+			t.Errorf("resolveDeviceName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
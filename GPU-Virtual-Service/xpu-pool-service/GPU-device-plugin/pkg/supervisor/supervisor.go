@@ -0,0 +1,356 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package supervisor runs the subsystems of the device plugin with restart policies
+// and stops them in order
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"huawei.com/vxpu-device-plugin/pkg/log"
+)
+
+// RestartPolicy decides whether a component is restarted after it returns
+type RestartPolicy int
+
+const (
+	// RestartNever never restart the component
+	RestartNever RestartPolicy = iota
+	// RestartOnFailure restart the component when it returns an error
+	RestartOnFailure
+	// RestartAlways restart the component whenever it returns
+	RestartAlways
+)
+
+// State state of a component
+type State string
+
+const (
+	// StatePending the component has not been started
+	StatePending State = "pending"
+	// StateRunning the component is running
+	StateRunning State = "running"
+	// StateBackoff the component failed and is waiting to be restarted
+	StateBackoff State = "backoff"
+	// StateStopped the component returned without error and will not be restarted
+	StateStopped State = "stopped"
+	// StateFailed the component returned an error and will not be restarted
+	StateFailed State = "failed"
+)
+
+const (
+	defaultMinBackoff   = time.Second
+	defaultMaxBackoff   = time.Minute
+	defaultStopTimeout  = 10 * time.Second
+	defaultReadyTimeout = 30 * time.Second
+	backoffFactor       = 2
+)
+
+// Component a subsystem run by the supervisor
+type Component interface {
+	// Name returns the name of the component
+	Name() string
+	// Run runs the component until ctx is cancelled or it fails
+	Run(ctx context.Context) error
+}
+
+type funcComponent struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+func (c *funcComponent) Name() string {
+	return c.name
+}
+
+func (c *funcComponent) Run(ctx context.Context) error {
+	return c.run(ctx)
+}
+
+// Func returns a component running the function
+func Func(name string, run func(ctx context.Context) error) Component {
+	return &funcComponent{name: name, run: run}
+}
+
+type readyKey struct{}
+
+// Ready marks the component run with ctx as ready, the components whose spec sets WaitReady
+// call it once they can serve, e.g. after listening on their socket
+func Ready(ctx context.Context) {
+	if ready, ok := ctx.Value(readyKey{}).(func()); ok {
+		ready()
+	}
+}
+
+// Spec how a component is run
+type Spec struct {
+	Component Component
+	Policy    RestartPolicy
+	// MinBackoff and MaxBackoff bound the exponential delay between restarts, MaxBackoff
+	// defaults to one minute and is raised to MinBackoff if it is smaller
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// StopTimeout how long to wait for the component to return on shutdown
+	StopTimeout time.Duration
+	// WaitReady the next components are not started until the component calls Ready,
+	// or ReadyTimeout has passed, components without WaitReady are ready once running
+	WaitReady    bool
+	ReadyTimeout time.Duration
+	// Critical the supervisor shuts down when the component fails and will not be restarted
+	Critical bool
+}
+
+// Status status of a component
+type Status struct {
+	Name      string
+	State     State
+	Ready     bool
+	Restarts  int
+	LastError string
+	Since     time.Time
+}
+
+type entry struct {
+	spec      Spec
+	status    Status
+	cancel    context.CancelFunc
+	done      chan struct{}
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+// Supervisor starts components in order, restarts them by their policies,
+// and stops them in reverse order
+type Supervisor struct {
+	entries   []*entry
+	listeners []func(Status)
+	failed    chan error
+	mutex     sync.RWMutex
+}
+
+// New new a supervisor instance
+func New() *Supervisor {
+	return &Supervisor{failed: make(chan error, 1)}
+}
+
+// Add adds a component, components are started in the order they are added
+func (s *Supervisor) Add(spec Spec) {
+	if spec.MinBackoff <= 0 {
+		spec.MinBackoff = defaultMinBackoff
+	}
+	if spec.MaxBackoff <= 0 {
+		spec.MaxBackoff = defaultMaxBackoff
+	}
+	if spec.MaxBackoff < spec.MinBackoff {
+		spec.MaxBackoff = spec.MinBackoff
+	}
+	if spec.StopTimeout <= 0 {
+		spec.StopTimeout = defaultStopTimeout
+	}
+	if spec.ReadyTimeout <= 0 {
+		spec.ReadyTimeout = defaultReadyTimeout
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = append(s.entries, &entry{
+		spec:   spec,
+		status: Status{Name: spec.Component.Name(), State: StatePending, Since: time.Now()},
+	})
+}
+
+// Subscribe registers a listener called on every state change of the components
+func (s *Supervisor) Subscribe(listener func(Status)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// Statuses returns the status of all components in the order they are added
+func (s *Supervisor) Statuses() []Status {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	res := make([]Status, 0, len(s.entries))
+	for _, e := range s.entries {
+		res = append(res, e.status)
+	}
+	return res
+}
+
+// State returns the state of the component with the name
+func (s *Supervisor) State(name string) (State, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, e := range s.entries {
+		if e.status.Name == name {
+			return e.status.State, true
+		}
+	}
+	return "", false
+}
+
+func (s *Supervisor) setState(e *entry, state State, err error) {
+	s.mutex.Lock()
+	e.status.State = state
+	e.status.Ready = state == StateRunning && !e.spec.WaitReady
+	e.status.Since = time.Now()
+	if err != nil {
+		e.status.LastError = err.Error()
+	}
+	s.notifyLocked(e)
+}
+
+func (s *Supervisor) setReady(e *entry) {
+	s.mutex.Lock()
+	if e.status.State != StateRunning || e.status.Ready {
+		s.mutex.Unlock()
+		return
+	}
+	e.status.Ready = true
+	s.notifyLocked(e)
+	e.readyOnce.Do(func() { close(e.ready) })
+}
+
+// notifyLocked calls the listeners with the status of the entry, the mutex is released before
+// the listeners are called so that they can read the statuses
+func (s *Supervisor) notifyLocked(e *entry) {
+	status := e.status
+	listeners := append([]func(Status){}, s.listeners...)
+	s.mutex.Unlock()
+
+	for _, listener := range listeners {
+		listener(status)
+	}
+}
+
+// waitReady waits until the component is ready or has returned, false is returned if the
+// supervisor is shutting down before that
+func (s *Supervisor) waitReady(ctx context.Context, e *entry) bool {
+	select {
+	case <-e.ready:
+	case <-e.done:
+	case <-time.After(e.spec.ReadyTimeout):
+		log.Warningf("component %s is not ready in %v, starting the next components",
+			e.status.Name, e.spec.ReadyTimeout)
+	case <-ctx.Done():
+		return false
+	case err := <-s.failed:
+		// leave the failure to Run
+		s.notifyFailed(err)
+		return false
+	}
+	return true
+}
+
+// Run starts the components one by one, a component with WaitReady is waited to be ready
+// before the next is started. Run blocks until ctx is cancelled or a critical component fails,
+// then stops the started components in reverse order
+func (s *Supervisor) Run(ctx context.Context) error {
+	s.mutex.RLock()
+	entries := append([]*entry{}, s.entries...)
+	s.mutex.RUnlock()
+
+	for _, e := range entries {
+		componentCtx, cancel := context.WithCancel(context.Background())
+		e.cancel = cancel
+		e.done = make(chan struct{})
+		e.ready = make(chan struct{})
+		go s.runComponent(componentCtx, e)
+		if e.spec.WaitReady && !s.waitReady(ctx, e) {
+			break
+		}
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		log.Infoln("supervisor is shutting down")
+	case err = <-s.failed:
+		log.Errorf("supervisor is shutting down: %v", err)
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.cancel == nil {
+			continue
+		}
+		e.cancel()
+		select {
+		case <-e.done:
+			log.Infof("component %s stopped", e.status.Name)
+		case <-time.After(e.spec.StopTimeout):
+			log.Warningf("component %s did not stop in %v", e.status.Name, e.spec.StopTimeout)
+		}
+	}
+	return err
+}
+
+func (s *Supervisor) runComponent(ctx context.Context, e *entry) {
+	defer close(e.done)
+	name := e.spec.Component.Name()
+	backoff := e.spec.MinBackoff
+	runCtx := context.WithValue(ctx, readyKey{}, func() { s.setReady(e) })
+	for {
+		s.setState(e, StateRunning, nil)
+		startTime := time.Now()
+		err := runSafely(runCtx, e.spec.Component)
+		if ctx.Err() != nil {
+			s.setState(e, StateStopped, nil)
+			return
+		}
+		if err == nil && e.spec.Policy != RestartAlways {
+			log.Infof("component %s returned", name)
+			s.setState(e, StateStopped, nil)
+			return
+		}
+		if err != nil && e.spec.Policy == RestartNever {
+			log.Errorf("component %s failed: %v", name, err)
+			s.setState(e, StateFailed, err)
+			if e.spec.Critical {
+				s.notifyFailed(fmt.Errorf("critical component %s failed: %v", name, err))
+			}
+			return
+		}
+
+		// a component which has run stably for a while restarts with the minimum backoff
+		if time.Since(startTime) > e.spec.MaxBackoff {
+			backoff = e.spec.MinBackoff
+		}
+		log.Warningf("component %s returned: %v, restart in %v", name, err, backoff)
+		s.setState(e, StateBackoff, err)
+		select {
+		case <-ctx.Done():
+			s.setState(e, StateStopped, nil)
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= backoffFactor
+		if backoff > e.spec.MaxBackoff {
+			backoff = e.spec.MaxBackoff
+		}
+		s.mutex.Lock()
+		e.status.Restarts++
+		s.mutex.Unlock()
+	}
+}
+
+func (s *Supervisor) notifyFailed(err error) {
+	select {
+	case s.failed <- err:
+	default:
+	}
+}
+
+// runSafely runs the component and turns a panic into an error
+func runSafely(ctx context.Context, c Component) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return c.Run(ctx)
+}
@@ -0,0 +1,332 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testBackoff = 10 * time.Millisecond
+	testTimeout = 5 * time.Second
+)
+
+var errTest = errors.New("test error")
+
+// recorder records the events of the test components in order
+type recorder struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) get() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string{}, r.events...)
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// runSupervisor runs the supervisor in the background, the returned function stops it and
+// returns the error of Run
+func runSupervisor(s *Supervisor) func() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Run(ctx)
+	}()
+	return func() error {
+		cancel()
+		return <-errCh
+	}
+}
+
+func statusOf(s *Supervisor, name string) Status {
+	for _, status := range s.Statuses() {
+		if status.Name == name {
+			return status
+		}
+	}
+	return Status{}
+}
+
+// failing returns a component which returns ret the first n runs and then blocks until ctx is cancelled
+func failing(name string, n int, ret error, runs *int, mutex *sync.Mutex) Component {
+	return Func(name, func(ctx context.Context) error {
+		mutex.Lock()
+		*runs++
+		run := *runs
+		mutex.Unlock()
+		if run <= n {
+			return ret
+		}
+		<-ctx.Done()
+		return nil
+	})
+}
+
+func TestAddDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		min     time.Duration
+		max     time.Duration
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{name: "unset", wantMin: defaultMinBackoff, wantMax: defaultMaxBackoff},
+		{name: "min below the default max", min: 5 * time.Second, wantMin: 5 * time.Second,
+			wantMax: defaultMaxBackoff},
+		{name: "min above the default max", min: 2 * time.Minute, wantMin: 2 * time.Minute,
+			wantMax: 2 * time.Minute},
+		{name: "max below min", min: 10 * time.Second, max: 5 * time.Second, wantMin: 10 * time.Second,
+			wantMax: 10 * time.Second},
+		{name: "both set", min: 5 * time.Second, max: 5 * time.Minute, wantMin: 5 * time.Second,
+			wantMax: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			s.Add(Spec{Component: Func("c", nil), MinBackoff: tt.min, MaxBackoff: tt.max})
+			spec := s.entries[0].spec
+			if spec.MinBackoff != tt.wantMin || spec.MaxBackoff != tt.wantMax {
+				t.Errorf("backoff = [%v, %v], want [%v, %v]", spec.MinBackoff, spec.MaxBackoff,
+					tt.wantMin, tt.wantMax)
+			}
+			if spec.StopTimeout != defaultStopTimeout || spec.ReadyTimeout != defaultReadyTimeout {
+				t.Errorf("timeouts = %v, %v", spec.StopTimeout, spec.ReadyTimeout)
+			}
+		})
+	}
+}
+
+func TestRestartPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       RestartPolicy
+		ret          error
+		wantState    State
+		wantRestarts int
+	}{
+		{name: "never restarts a failure", policy: RestartNever, ret: errTest, wantState: StateFailed},
+		{name: "never restarts a return", policy: RestartNever, wantState: StateStopped},
+		{name: "on failure restarts a failure", policy: RestartOnFailure, ret: errTest, wantState: StateRunning,
+			wantRestarts: 2},
+		{name: "on failure keeps a return", policy: RestartOnFailure, wantState: StateStopped},
+		{name: "always restarts a failure", policy: RestartAlways, ret: errTest, wantState: StateRunning,
+			wantRestarts: 2},
+		{name: "always restarts a return", policy: RestartAlways, wantState: StateRunning, wantRestarts: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mutex sync.Mutex
+			runs := 0
+			s := New()
+			s.Add(Spec{Component: failing("c", 2, tt.ret, &runs, &mutex), Policy: tt.policy,
+				MinBackoff: testBackoff})
+			stop := runSupervisor(s)
+			waitFor(t, "the final state", func() bool {
+				status := statusOf(s, "c")
+				return status.State == tt.wantState && status.Restarts == tt.wantRestarts
+			})
+			if err := stop(); err != nil {
+				t.Errorf("Run error: %v", err)
+			}
+			if tt.ret != nil && statusOf(s, "c").LastError != tt.ret.Error() {
+				t.Errorf("LastError = %q, want %q", statusOf(s, "c").LastError, tt.ret.Error())
+			}
+		})
+	}
+}
+
+func TestCriticalFailureStopsSupervisor(t *testing.T) {
+	rec := &recorder{}
+	s := New()
+	s.Add(Spec{Component: Func("other", func(ctx context.Context) error {
+		<-ctx.Done()
+		rec.add("other stopped")
+		return nil
+	})})
+	s.Add(Spec{Component: Func("critical", func(ctx context.Context) error {
+		return errTest
+	}), Policy: RestartNever, Critical: true})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Run(context.Background())
+	}()
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatal("Run should return the error of the critical component")
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("supervisor did not stop after the critical component failed")
+	}
+	if got := rec.get(); !reflect.DeepEqual(got, []string{"other stopped"}) {
+		t.Errorf("events = %v, the other components should be stopped", got)
+	}
+}
+
+func TestPanicIsRestarted(t *testing.T) {
+	var mutex sync.Mutex
+	runs := 0
+	s := New()
+	s.Add(Spec{Component: Func("c", func(ctx context.Context) error {
+		mutex.Lock()
+		runs++
+		run := runs
+		mutex.Unlock()
+		if run == 1 {
+			panic("boom")
+		}
+		<-ctx.Done()
+		return nil
+	}), Policy: RestartOnFailure, MinBackoff: testBackoff})
+	stop := runSupervisor(s)
+	defer stop()
+	waitFor(t, "the restart after the panic", func() bool {
+		status := statusOf(s, "c")
+		return status.State == StateRunning && status.Restarts == 1
+	})
+	if status := statusOf(s, "c"); status.LastError != "panic: boom" {
+		t.Errorf("LastError = %q", status.LastError)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	var mutex sync.Mutex
+	starts := make([]time.Time, 0)
+	s := New()
+	s.Add(Spec{Component: Func("c", func(ctx context.Context) error {
+		mutex.Lock()
+		starts = append(starts, time.Now())
+		mutex.Unlock()
+		return errTest
+	}), Policy: RestartOnFailure, MinBackoff: testBackoff, MaxBackoff: 4 * testBackoff})
+	stop := runSupervisor(s)
+	waitFor(t, "six runs", func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(starts) >= 6
+	})
+	stop()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	// the delay doubles from MinBackoff and is capped at MaxBackoff
+	want := []time.Duration{testBackoff, 2 * testBackoff, 4 * testBackoff, 4 * testBackoff, 4 * testBackoff}
+	for i, min := range want {
+		if got := starts[i+1].Sub(starts[i]); got < min {
+			t.Errorf("delay before restart %d = %v, want at least %v", i+1, got, min)
+		}
+	}
+	// the delay is capped, allow the scheduling latency of a loaded machine
+	if got := starts[5].Sub(starts[4]); got > 4*testBackoff+time.Second {
+		t.Errorf("delay before restart 5 = %v, want about %v", got, 4*testBackoff)
+	}
+}
+
+func TestStartsInOrderAndStopsInReverse(t *testing.T) {
+	rec := &recorder{}
+	ready := make(chan struct{})
+	s := New()
+	s.Add(Spec{Component: Func("first", func(ctx context.Context) error {
+		rec.add("first started")
+		<-ready
+		Ready(ctx)
+		<-ctx.Done()
+		rec.add("first stopped")
+		return nil
+	}), WaitReady: true})
+	s.Add(Spec{Component: Func("second", func(ctx context.Context) error {
+		rec.add("second started")
+		<-ctx.Done()
+		rec.add("second stopped")
+		return nil
+	})})
+	stop := runSupervisor(s)
+
+	waitFor(t, "the first component", func() bool { return len(rec.get()) == 1 })
+	if status := statusOf(s, "first"); status.State != StateRunning || status.Ready {
+		t.Errorf("first status = %+v, want running and not ready", status)
+	}
+	if status := statusOf(s, "second"); status.State != StatePending {
+		t.Errorf("second should not start before first is ready, status = %+v", status)
+	}
+	close(ready)
+	waitFor(t, "the second component", func() bool { return statusOf(s, "second").State == StateRunning })
+	if !statusOf(s, "first").Ready || !statusOf(s, "second").Ready {
+		t.Errorf("statuses = %+v, want both ready", s.Statuses())
+	}
+
+	if err := stop(); err != nil {
+		t.Errorf("Run error: %v", err)
+	}
+	want := []string{"first started", "second started", "second stopped", "first stopped"}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestReadyTimeout(t *testing.T) {
+	s := New()
+	s.Add(Spec{Component: Func("never-ready", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}), WaitReady: true, ReadyTimeout: testBackoff})
+	s.Add(Spec{Component: Func("next", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})})
+	stop := runSupervisor(s)
+	defer stop()
+	waitFor(t, "the next component after the ready timeout", func() bool {
+		return statusOf(s, "next").State == StateRunning
+	})
+}
+
+func TestShutdownWhileWaitingForReady(t *testing.T) {
+	s := New()
+	s.Add(Spec{Component: Func("never-ready", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}), WaitReady: true})
+	s.Add(Spec{Component: Func("next", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})})
+	stop := runSupervisor(s)
+	waitFor(t, "the first component", func() bool { return statusOf(s, "never-ready").State == StateRunning })
+	if err := stop(); err != nil {
+		t.Errorf("Run error: %v", err)
+	}
+	if status := statusOf(s, "next"); status.State != StatePending {
+		t.Errorf("next status = %+v, want pending", status)
+	}
+	if status := statusOf(s, "never-ready"); status.State != StateStopped {
+		t.Errorf("never-ready status = %+v, want stopped", status)
+	}
+}
@@ -0,0 +1,274 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package violation detects the vxpus which exceed their memory or core limit
+package violation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"huawei.com/vxpu-device-plugin/pkg/lock"
+	"huawei.com/vxpu-device-plugin/pkg/log"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/config"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/types"
+	"huawei.com/vxpu-device-plugin/pkg/plugin/util"
+)
+
+const (
+	// ActionEvent record a kubernetes event for the pod
+	ActionEvent = "event"
+	// ActionAnnotation patch the violation to the pod annotation
+	ActionAnnotation = "annotation"
+	// ActionMetric expose the violation in the vxpu info
+	ActionMetric = "metric"
+	// ActionSigterm send SIGTERM to the host pids of the container using the xpu
+	ActionSigterm = "sigterm"
+
+	// LimitMemory the vxpu memory used exceeds the memory limit
+	LimitMemory = "memory"
+	// LimitCore the vxpu core utilization exceeds the core limit
+	LimitCore = "core"
+
+	// ViolationAnnotation annotation of the pod whose vxpu exceeds its limit
+	ViolationAnnotation = "huawei.com/vxpu-limit-violation"
+
+	eventReason    = "VxpuLimitExceeded"
+	eventComponent = "xpu-device-plugin"
+	maxCoreLimit   = 100
+	timeFormat     = "2006.01.02 15:04:05"
+)
+
+var supportedActions = map[string]bool{
+	ActionEvent:      true,
+	ActionAnnotation: true,
+	ActionMetric:     true,
+	ActionSigterm:    true,
+}
+
+// ParseActions parses the comma separated actions
+func ParseActions(s string) ([]string, error) {
+	actions := make([]string, 0)
+	for _, action := range strings.Split(s, ",") {
+		action = strings.TrimSpace(action)
+		if action == "" {
+			continue
+		}
+		if !supportedActions[action] {
+			return nil, fmt.Errorf("unsupported violation action %s", action)
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// PidsGetter returns the host pids of the container which are using the vxpu
+type PidsGetter func(vxpu types.VxpuDevice) []uint32
+
+// Config configure of the detector
+type Config struct {
+	Actions []string
+	// Window how long a vxpu exceeds its limit before the actions are taken
+	Window time.Duration
+	// CoreTolerance percentage of core utilization allowed above the core limit
+	CoreTolerance int64
+}
+
+type violation struct {
+	vxpu      types.VxpuDevice
+	limitType string
+	since     time.Time
+	reported  bool
+}
+
+// Detector compares the sampled usage of vxpus with their limits
+type Detector struct {
+	conf       Config
+	pidsOf     PidsGetter
+	actions    map[string]bool
+	violations map[string]*violation
+	mutex      sync.RWMutex
+}
+
+// NewDetector new a violation detector instance
+func NewDetector(conf Config, pidsOf PidsGetter) *Detector {
+	actions := make(map[string]bool)
+	for _, action := range conf.Actions {
+		actions[action] = true
+	}
+	return &Detector{
+		conf:       conf,
+		pidsOf:     pidsOf,
+		actions:    actions,
+		violations: make(map[string]*violation),
+	}
+}
+
+// MetricEnabled whether the violations should be exposed in the vxpu info
+func (d *Detector) MetricEnabled() bool {
+	return d.actions[ActionMetric]
+}
+
+// Exceeded whether the vxpu has exceeded the limit for the sustained window
+func (d *Detector) Exceeded(vxpu types.VxpuDevice, limitType string) bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	v, ok := d.violations[violationKey(vxpu, limitType)]
+	return ok && v.reported
+}
+
+// violationKey returns the key of the violation, the vxpu ids are reused after the pods are released,
+// so the violations are keyed by the pod uid as well
+func violationKey(vxpu types.VxpuDevice, limitType string) string {
+	return vxpu.PodUID + "/" + vxpu.Id + "/" + limitType
+}
+
+// exceededLimits returns the limits exceeded by the vxpu of device, the core limit is not checked when
+// the core utilization of device is not reported
+func (d *Detector) exceededLimits(device *types.XPUDevice, v types.VxpuDevice) []string {
+	limits := make([]string, 0)
+	if v.VxpuMemoryLimit > 0 && v.VxpuMemoryUsed > uint64(v.VxpuMemoryLimit) {
+		limits = append(limits, LimitMemory)
+	}
+	if !device.CoreUtilizationUnsupported && v.VxpuCoreLimit > 0 && v.VxpuCoreLimit < maxCoreLimit &&
+		v.VxpuCoreUtilization > float64(v.VxpuCoreLimit+d.conf.CoreTolerance) {
+		limits = append(limits, LimitCore)
+	}
+	return limits
+}
+
+// Check updates the violations with the usage sampled at now, and takes the actions
+// for the vxpus which have exceeded the limit for the sustained window
+func (d *Detector) Check(now time.Time, xpuDevices map[string]*types.XPUDevice) {
+	current := make(map[string]bool)
+	toReport := make([]*violation, 0)
+
+	d.mutex.Lock()
+	for _, device := range xpuDevices {
+		for _, v := range device.VxpuDeviceList {
+			for _, limitType := range d.exceededLimits(device, v) {
+				key := violationKey(v, limitType)
+				current[key] = true
+				vio, ok := d.violations[key]
+				if !ok {
+					vio = &violation{limitType: limitType, since: now}
+					d.violations[key] = vio
+				}
+				vio.vxpu = v
+				if !vio.reported && now.Sub(vio.since) >= d.conf.Window {
+					vio.reported = true
+					toReport = append(toReport, vio)
+				}
+			}
+		}
+	}
+	for key, vio := range d.violations {
+		if !current[key] {
+			if vio.reported {
+				log.Infof("vxpu %s of pod %s container %s is back under its %s limit",
+					vio.vxpu.Id, vio.vxpu.PodUID, vio.vxpu.ContainerName, vio.limitType)
+			}
+			delete(d.violations, key)
+		}
+	}
+	d.mutex.Unlock()
+
+	for _, vio := range toReport {
+		d.report(vio)
+	}
+}
+
+func (vio *violation) message() string {
+	if vio.limitType == LimitMemory {
+		return fmt.Sprintf("vxpu %s of container %s used %d MiB memory, exceeds the limit %d MiB since %s",
+			vio.vxpu.Id, vio.vxpu.ContainerName, vio.vxpu.VxpuMemoryUsed, vio.vxpu.VxpuMemoryLimit,
+			vio.since.Format(timeFormat))
+	}
+	return fmt.Sprintf("vxpu %s of container %s used %.2f%% core, exceeds the limit %d%% since %s",
+		vio.vxpu.Id, vio.vxpu.ContainerName, vio.vxpu.VxpuCoreUtilization, vio.vxpu.VxpuCoreLimit,
+		vio.since.Format(timeFormat))
+}
+
+func (d *Detector) report(vio *violation) {
+	logger := log.WithPodUID(vio.vxpu.PodUID).WithDeviceUUID(vio.vxpu.GpuId)
+	logger.Warningf("pod %s: %s", vio.vxpu.PodUID, vio.message())
+	if d.actions[ActionEvent] || d.actions[ActionAnnotation] {
+		pod, err := getPod(vio.vxpu)
+		if err != nil {
+			logger.Errorf("get pod %s for violation error: %v", vio.vxpu.PodUID, err)
+		} else {
+			d.reportToPod(pod, vio)
+		}
+	}
+	if d.actions[ActionSigterm] {
+		for _, pid := range d.pidsOf(vio.vxpu) {
+			logger.Warningf("send SIGTERM to pid %d of pod %s container %s",
+				pid, vio.vxpu.PodUID, vio.vxpu.ContainerName)
+			if err := syscall.Kill(int(pid), syscall.SIGTERM); err != nil {
+				logger.Errorf("send SIGTERM to pid %d error: %v", pid, err)
+			}
+		}
+	}
+}
+
+func (d *Detector) reportToPod(pod *v1.Pod, vio *violation) {
+	if d.actions[ActionEvent] {
+		if err := recordEvent(pod, vio.message()); err != nil {
+			log.Errorf("record violation event for pod %s error: %v", pod.Name, err)
+		}
+	}
+	if d.actions[ActionAnnotation] {
+		annotation := fmt.Sprintf("container=%s,vxpu=%s,limit=%s,since=%s", vio.vxpu.ContainerName,
+			vio.vxpu.Id, vio.limitType, vio.since.Format(timeFormat))
+		if err := util.PatchPodAnnotations(pod, map[string]string{ViolationAnnotation: annotation}); err != nil {
+			log.Errorf("patch violation annotation for pod %s error: %v", pod.Name, err)
+		}
+	}
+}
+
+// getPod gets the pod of the vxpu, the uid is checked in case the pod has been recreated with the same name
+func getPod(vxpu types.VxpuDevice) (*v1.Pod, error) {
+	pod, err := util.GetPod(vxpu.PodNamespace, vxpu.PodName)
+	if err != nil {
+		return nil, err
+	}
+	if string(pod.UID) != vxpu.PodUID {
+		return nil, fmt.Errorf("pod %s/%s has been recreated, uid %s is not %s",
+			vxpu.PodNamespace, vxpu.PodName, pod.UID, vxpu.PodUID)
+	}
+	return pod, nil
+}
+
+func recordEvent(pod *v1.Pod, message string) error {
+	now := metav1.Now()
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: pod.Name + ".",
+			Namespace:    pod.Namespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Namespace:  pod.Namespace,
+			Name:       pod.Name,
+			UID:        pod.UID,
+		},
+		Reason:         eventReason,
+		Message:        message,
+		Type:           v1.EventTypeWarning,
+		Source:         v1.EventSource{Component: eventComponent, Host: config.NodeName},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := lock.GetClient().CoreV1().Events(pod.Namespace).Create(context.Background(), event, metav1.CreateOptions{})
+	return err
+}
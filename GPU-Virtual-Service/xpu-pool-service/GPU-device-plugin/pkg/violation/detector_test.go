@@ -0,0 +1,169 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package violation
+
+import (
+	"testing"
+	"time"
+
+	"huawei.com/vxpu-device-plugin/pkg/plugin/types"
+)
+
+const (
+	testGpuId  = "GPU-0"
+	testPodUID = "pod-a"
+	testWindow = time.Minute
+)
+
+var testStart = time.Unix(1000, 0)
+
+func newTestVxpu(memoryUsed uint64, core float64) types.VxpuDevice {
+	return types.VxpuDevice{
+		Id:                  testGpuId + "-0",
+		GpuId:               testGpuId,
+		PodUID:              testPodUID,
+		ContainerName:       "main",
+		VxpuMemoryUsed:      memoryUsed,
+		VxpuCoreUtilization: core,
+		VxpuMemoryLimit:     1024,
+		VxpuCoreLimit:       50,
+	}
+}
+
+func newTestDevices(vxpus ...types.VxpuDevice) map[string]*types.XPUDevice {
+	return map[string]*types.XPUDevice{testGpuId: {Id: testGpuId, VxpuDeviceList: vxpus}}
+}
+
+// newTestDetector returns a detector taking the sigterm action, pidsOf counts the reports per pod
+func newTestDetector(tolerance int64, reports map[string]int) *Detector {
+	return NewDetector(Config{
+		Actions:       []string{ActionMetric, ActionSigterm},
+		Window:        testWindow,
+		CoreTolerance: tolerance,
+	}, func(vxpu types.VxpuDevice) []uint32 {
+		reports[vxpu.PodUID]++
+		return nil
+	})
+}
+
+func TestParseActions(t *testing.T) {
+	actions, err := ParseActions(" event, metric,,sigterm ")
+	if err != nil || len(actions) != 3 || actions[0] != ActionEvent || actions[2] != ActionSigterm {
+		t.Errorf("ParseActions = %v, %v", actions, err)
+	}
+	if _, err := ParseActions("metric,kill"); err == nil {
+		t.Error("ParseActions should reject unsupported actions")
+	}
+}
+
+func TestCheckWindow(t *testing.T) {
+	over := newTestVxpu(2048, 0)
+	under := newTestVxpu(512, 0)
+	tests := []struct {
+		name         string
+		samples      []types.VxpuDevice
+		offsets      []time.Duration
+		wantExceeded bool
+		wantReports  int
+	}{
+		{name: "under the limit", samples: []types.VxpuDevice{under, under},
+			offsets: []time.Duration{0, testWindow}},
+		{name: "shorter than the window", samples: []types.VxpuDevice{over, over},
+			offsets: []time.Duration{0, testWindow - time.Second}},
+		{name: "sustained for the window", samples: []types.VxpuDevice{over, over, over},
+			offsets: []time.Duration{0, testWindow / 2, testWindow}, wantExceeded: true, wantReports: 1},
+		{name: "reported once", samples: []types.VxpuDevice{over, over, over},
+			offsets: []time.Duration{0, testWindow, 2 * testWindow}, wantExceeded: true, wantReports: 1},
+		{name: "back under the limit resets", samples: []types.VxpuDevice{over, over, under},
+			offsets: []time.Duration{0, testWindow, 2 * testWindow}, wantReports: 1},
+		{name: "window restarts after a dip", samples: []types.VxpuDevice{over, under, over, over},
+			offsets: []time.Duration{0, testWindow / 2, testWindow, testWindow + testWindow/2}},
+		{name: "memory at the limit", samples: []types.VxpuDevice{newTestVxpu(1024, 0), newTestVxpu(1024, 0)},
+			offsets: []time.Duration{0, testWindow}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := make(map[string]int)
+			d := newTestDetector(0, reports)
+			for i, v := range tt.samples {
+				d.Check(testStart.Add(tt.offsets[i]), newTestDevices(v))
+			}
+			last := tt.samples[len(tt.samples)-1]
+			if got := d.Exceeded(last, LimitMemory); got != tt.wantExceeded {
+				t.Errorf("Exceeded = %v, want %v", got, tt.wantExceeded)
+			}
+			if reports[testPodUID] != tt.wantReports {
+				t.Errorf("reports = %d, want %d", reports[testPodUID], tt.wantReports)
+			}
+		})
+	}
+}
+
+func TestCheckCoreTolerance(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance int64
+		core      float64
+		coreLimit int64
+		want      bool
+	}{
+		{name: "under the limit", tolerance: 10, core: 40, coreLimit: 50},
+		{name: "within the tolerance", tolerance: 10, core: 55, coreLimit: 50},
+		{name: "at the tolerance", tolerance: 10, core: 60, coreLimit: 50},
+		{name: "above the tolerance", tolerance: 10, core: 60.5, coreLimit: 50, want: true},
+		{name: "no tolerance", tolerance: 0, core: 50.5, coreLimit: 50, want: true},
+		{name: "no core limit", tolerance: 0, core: 90, coreLimit: 0},
+		{name: "full core limit", tolerance: 0, core: 100, coreLimit: maxCoreLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDetector(tt.tolerance, make(map[string]int))
+			v := newTestVxpu(0, tt.core)
+			v.VxpuCoreLimit = tt.coreLimit
+			d.Check(testStart, newTestDevices(v))
+			d.Check(testStart.Add(testWindow), newTestDevices(v))
+			if got := d.Exceeded(v, LimitCore); got != tt.want {
+				t.Errorf("Exceeded = %v, want %v", got, tt.want)
+			}
+			if d.Exceeded(v, LimitMemory) {
+				t.Error("memory limit should not be exceeded")
+			}
+		})
+	}
+}
+
+func TestCheckCoreUnsupported(t *testing.T) {
+	d := newTestDetector(0, make(map[string]int))
+	v := newTestVxpu(2048, 90)
+	for _, offset := range []time.Duration{0, testWindow} {
+		devices := newTestDevices(v)
+		devices[testGpuId].CoreUtilizationUnsupported = true
+		d.Check(testStart.Add(offset), devices)
+	}
+	if d.Exceeded(v, LimitCore) {
+		t.Error("core limit should not be checked when the core utilization is not reported")
+	}
+	if !d.Exceeded(v, LimitMemory) {
+		t.Error("memory limit should still be checked")
+	}
+}
+
+func TestCheckKeysByPod(t *testing.T) {
+	reports := make(map[string]int)
+	d := newTestDetector(0, reports)
+	old := newTestVxpu(2048, 0)
+	d.Check(testStart, newTestDevices(old))
+	// the pod is released and the vxpu id is reused by a new pod, its window starts over
+	reused := old
+	reused.PodUID = "pod-b"
+	d.Check(testStart.Add(testWindow), newTestDevices(reused))
+	if d.Exceeded(reused, LimitMemory) || reports["pod-b"] != 0 {
+		t.Error("the window of the new pod should not include the samples of the released pod")
+	}
+	d.Check(testStart.Add(2*testWindow), newTestDevices(reused))
+	if !d.Exceeded(reused, LimitMemory) || d.Exceeded(old, LimitMemory) || reports["pod-b"] != 1 {
+		t.Errorf("only the new pod should be exceeded and reported, reports: %v", reports)
+	}
+}
@@ -12,32 +12,79 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"huawei.com/vxpu-device-plugin/pkg/lock"
 	"huawei.com/vxpu-device-plugin/pkg/log"
 	"huawei.com/xpu-exporter/collector/gpuservice"
 	"huawei.com/xpu-exporter/collector/npuservice"
+	"huawei.com/xpu-exporter/common/client"
+	"huawei.com/xpu-exporter/common/config"
+	"huawei.com/xpu-exporter/common/metadata"
+	"huawei.com/xpu-exporter/common/otlp"
+	"huawei.com/xpu-exporter/common/relabel"
 	"huawei.com/xpu-exporter/server"
 	"huawei.com/xpu-exporter/versions"
 )
 
 var (
-	updateTime int
-	xpuType    string
+	updateTime    int
+	xpuType       string
+	dcmiLibPath   string
+	npuSourceFile string
+	// pod 元数据相关参数
+	podMetadata    bool
+	nodeName       string
+	podLabels      string
+	podAnnotations string
+	resolver       *metadata.Resolver
+	// OTLP 推送相关参数
+	otlpEndpoint   string
+	otlpProtocol   string
+	otlpHeaders    string
+	otlpInsecure   bool
+	otlpCAFile     string
+	otlpInterval   int
+	otlpAttributes string
+	clusterName    string
+	// pids 服务客户端相关参数
+	pidsSock     string
+	samplePeriod int
+	pidsTimeout  int
+	// 配置文件相关参数
+	configFile  string
+	cacheTime   int
+	logDir      string
+	fileConf    *config.Config
+	setFlags    = map[string]bool{}
+	reloadMutex sync.Mutex
 )
 
 const (
 	exporterServerPort = 8082                        // 默认 HTTP 服务端口
 	updateTimeConst    = 5                           // 默认更新间隔（秒）
 	oneMinute          = 60                          // 一分钟的秒数
-	cacheTime          = 65 * time.Second            // 缓存过期时间
+	defaultCacheTime   = 65                          // 默认缓存过期时间（秒）
+	maxCacheTime       = 3600                        // 最大缓存过期时间（秒）
+	logLevelFlag       = "log-level"                 // 日志级别参数，由 log 包定义
+	logFormatFlag      = "log-format"                // 日志格式参数，由 log 包定义
 	defaultConcurrency = 5                           // 默认最大并发数
 	defaultConnection  = 20                          // 默认连接数限制
 	defaultLogDir      = "/var/log/xpu/xpu-exporter" // 默认日志目录
+	xpuTypeAuto        = "auto"                      // 自动检测节点上的 XPU 类型
+	gpuPidsSockPath    = client.DefaultSockPath      // GPU 设备插件的 pids 服务
+	defaultPidsTimeout = 10                          // 默认 pids 服务调用超时（秒）
+	nvidiaCtlPath      = "/dev/nvidiactl"            // NVIDIA 驱动控制设备
+	davinciManagerPath = "/dev/davinci_manager"      // 昇腾驱动管理设备
+	defaultOtlpPeriod  = 30                          // 默认 OTLP 推送间隔（秒）
+	maxOtlpPeriod      = 3600                        // 最大 OTLP 推送间隔（秒）
 )
 
 var serverHandler *server.ExporterServer
@@ -46,7 +93,47 @@ var serverHandler *server.ExporterServer
 func init() {
 	serverHandler = &server.ExporterServer{}
 	// 定义命令行参数
-	flag.StringVar(&xpuType, "type", "", "Set xpu type,range[gpu,npu],can not be empty")
+	flag.StringVar(&configFile, "config", "",
+		"The yaml config file whose keys are the names of the flags, the flags set on the command line override "+
+			"the file, the limits, collectors and logLevel are reloaded when the file changes or on SIGHUP")
+	flag.IntVar(&cacheTime, "cacheTime", defaultCacheTime,
+		"The cached metrics older than the time (seconds) are marked stale, range[updateTime-3600]")
+	flag.StringVar(&logDir, "logDir", defaultLogDir, "The dir of the log file")
+	flag.BoolVar(&serverHandler.HTTPS, "https", false,
+		"Enable https with the certificate in /opt/xpu/certs when tlsCertFile is not set, the same as HTTPS_ENABLE=on")
+	flag.StringVar(&xpuType, "type", "", "Set xpu types separated by comma, range[gpu,npu], "+
+		"or auto to detect the xpus on the node, can not be empty")
+	flag.StringVar(&dcmiLibPath, "dcmiLibPath", npuservice.DefaultDcmiLibPath,
+		"The path of libdcmi.so used by the npu collector")
+	flag.StringVar(&npuSourceFile, "npuSourceFile", "",
+		"Read the npu metrics from the json file instead of libdcmi, used for test")
+	flag.BoolVar(&podMetadata, "podMetadata", false,
+		"Add the namespace, pod name and the allowed pod labels and annotations to the vgpu metrics")
+	flag.StringVar(&nodeName, "nodeName", os.Getenv("NODE_NAME"),
+		"The name of the node, used to watch the pods on the node when podMetadata is enabled")
+	flag.StringVar(&podLabels, "podLabels", "",
+		"The pod label keys separated by comma added to the vgpu metrics as label_<key>")
+	flag.StringVar(&podAnnotations, "podAnnotations", "",
+		"The pod annotation keys separated by comma added to the vgpu metrics as annotation_<key>")
+	flag.StringVar(&otlpEndpoint, "otlpEndpoint", "",
+		"Push the metrics to the OpenTelemetry receiver at the endpoint, host:port or url, disabled when empty")
+	flag.StringVar(&otlpProtocol, "otlpProtocol", otlp.ProtocolGRPC, "The otlp protocol, range[grpc,http]")
+	flag.StringVar(&otlpHeaders, "otlpHeaders", "",
+		"The headers key=value separated by comma sent with every otlp request")
+	flag.BoolVar(&otlpInsecure, "otlpInsecure", false, "Push the otlp metrics without TLS")
+	flag.StringVar(&otlpCAFile, "otlpCAFile", "",
+		"The ca file to verify the otlp receiver, the system roots are used when empty")
+	flag.IntVar(&otlpInterval, "otlpInterval", defaultOtlpPeriod,
+		"Interval (seconds) to push the otlp metrics, range[1-3600]")
+	flag.StringVar(&otlpAttributes, "otlpAttributes", "",
+		"The extra otlp resource attributes key=value separated by comma")
+	flag.StringVar(&clusterName, "cluster", "", "The cluster name set as the k8s.cluster.name otlp resource attribute")
+	flag.StringVar(&pidsSock, "pidsSock", gpuPidsSockPath,
+		"The socket of the pids service of the gpu device plugin, the exporter reconnects when it is recreated")
+	flag.IntVar(&samplePeriod, "samplePeriod", client.DefaultPeriod,
+		"The sample window (seconds) of the vgpu utilization queried from the pids service, range[1-86400]")
+	flag.IntVar(&pidsTimeout, "pidsTimeout", defaultPidsTimeout,
+		"The deadline (seconds) of one call to the pids service, range[1-60]")
 	flag.IntVar(&updateTime, "updateTime", updateTimeConst,
 		"Interval (seconds) to update the npu metric cache,range[1-60]")
 	flag.IntVar(&serverHandler.Port, "port", exporterServerPort,
@@ -59,8 +146,21 @@ func init() {
 		"the tcp connection limit for each Ip, range is [1,128]")
 	flag.IntVar(&serverHandler.LimitTotalConn, "limitTotalConn", defaultConnection,
 		"the tcp connection limit for all request, range is [1,512]")
+	flag.StringVar(&serverHandler.CertFile, "tlsCertFile", "",
+		"The certificate file of the https service, enables https together with tlsKeyFile, "+
+			"the certificate is reloaded when the file changes")
+	flag.StringVar(&serverHandler.KeyFile, "tlsKeyFile", "", "The private key file of the https service")
+	flag.StringVar(&serverHandler.ClientCAFile, "tlsClientCAFile", "",
+		"The ca file to verify the client certificates, enables mTLS of the https service when set")
 	flag.StringVar(&serverHandler.LimitIPReq, "limitIPReq", "20/1",
 		"the http request limit counts for each Ip,20/1 means allow 20 request in 1 seconds")
+	flag.StringVar(&serverHandler.TrustedProxies, "trustedProxies", "",
+		"The proxy CIDRs separated by comma whose X-Forwarded-For and X-Real-Ip headers identify the client ip, "+
+			"the headers are ignored when empty")
+	flag.StringVar(&serverHandler.AllowCIDRs, "allowCIDRs", "",
+		"The client CIDRs separated by comma allowed to access the service, all are allowed when empty")
+	flag.StringVar(&serverHandler.DenyCIDRs, "denyCIDRs", "",
+		"The client CIDRs separated by comma denied to access the service, checked before allowCIDRs")
 }
 
 // checkCommonParamValid 检查通用参数的有效性
@@ -69,37 +169,287 @@ func checkCommonParamValid() error {
 	if updateTime > oneMinute || updateTime < 1 {
 		return errors.New("the updateTime is invalid")
 	}
-	return nil
+	// 验证缓存过期时间不小于更新间隔
+	if cacheTime < updateTime || cacheTime > maxCacheTime {
+		return errors.New("the cacheTime is invalid")
+	}
+	// 验证 pids 服务调用超时是否在有效范围内（1-60秒）
+	if pidsTimeout > oneMinute || pidsTimeout < 1 {
+		return errors.New("the pidsTimeout is invalid")
+	}
+	return pidsClientConfig().Validate()
 }
 
-// loadCollectorService 根据 XPU 类型加载相应的收集器服务
-func loadCollectorService() error {
+// pidsClientConfig 返回 GPU 收集器连接 pids 服务的配置
+func pidsClientConfig() client.Config {
+	return client.Config{
+		SockPath:    pidsSock,
+		Period:      samplePeriod,
+		CallTimeout: time.Duration(pidsTimeout) * time.Second,
+	}
+}
+
+// detectXpuTypes 根据节点上的设备文件与服务检测 XPU 类型
+func detectXpuTypes() []string {
+	var types []string
+	if fileExists(pidsSock) || fileExists(nvidiaCtlPath) {
+		types = append(types, gpuservice.CollectorName)
+	}
+	if npuSourceFile != "" || fileExists(davinciManagerPath) || fileExists(dcmiLibPath) {
+		types = append(types, npuservice.CollectorName)
+	}
+	return types
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// parseXpuTypes 解析 --type 参数，支持逗号分隔的列表或 auto
+func parseXpuTypes() ([]string, error) {
+	if strings.TrimSpace(xpuType) == xpuTypeAuto {
+		types := detectXpuTypes()
+		if len(types) == 0 {
+			return nil, errors.New("type=auto detected no gpu or npu on the node")
+		}
+		log.Infof("detected xpu types: %v", types)
+		return types, nil
+	}
+	types := splitList(xpuType)
+	if len(types) == 0 {
+		return nil, fmt.Errorf("the mandatory parameter type=npu, type=gpu, type=gpu,npu or type=auto is missing")
+	}
+	return types, nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadResolver 开启 podMetadata 时创建 pod 元数据解析器，仅监听本节点上的 pod
+func loadResolver() error {
+	if !podMetadata {
+		return nil
+	}
+	if err := lock.NewClient(); err != nil {
+		return fmt.Errorf("create kubernetes client error: %v", err)
+	}
 	var err error
-	switch xpuType {
-	case npuservice.CollectorName:
-		// 加载 NPU 收集器服务
-		err = serverHandler.RegisterCollectorService(npuservice.New(npuservice.CollectorName))
-	case gpuservice.CollectorName:
-		// 加载 GPU 收集器服务
-		err = serverHandler.RegisterCollectorService(gpuservice.New(gpuservice.CollectorName))
+	resolver, err = metadata.NewResolver(lock.GetClient(), metadata.Config{
+		NodeName:    nodeName,
+		Labels:      splitList(podLabels),
+		Annotations: splitList(podAnnotations),
+	})
+	serverHandler.Resolver = resolver
+	return err
+}
+
+// loadOtlpExporter 配置 otlpEndpoint 时创建 OTLP 推送器，推送与 /metrics 相同的指标
+func loadOtlpExporter(gatherer prometheus.Gatherer) (*otlp.Exporter, error) {
+	if otlpEndpoint == "" {
+		return nil, nil
+	}
+	if otlpInterval < 1 || otlpInterval > maxOtlpPeriod {
+		return nil, errors.New("the otlpInterval is invalid")
+	}
+	headers, err := otlp.ParseKeyValues(otlpHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("the otlpHeaders is invalid: %v", err)
+	}
+	attributes, err := otlp.ParseKeyValues(otlpAttributes)
+	if err != nil {
+		return nil, fmt.Errorf("the otlpAttributes is invalid: %v", err)
+	}
+	return otlp.NewExporter(gatherer, otlp.Config{
+		Endpoint:   otlpEndpoint,
+		Protocol:   otlpProtocol,
+		Headers:    headers,
+		Insecure:   otlpInsecure,
+		CAFile:     otlpCAFile,
+		Interval:   time.Duration(otlpInterval) * time.Second,
+		NodeName:   nodeName,
+		NodeIP:     serverHandler.Ip,
+		Cluster:    clusterName,
+		Attributes: attributes,
+		Version:    versions.BuildVersion,
+	})
+}
+
+// loadCollectorService 根据 XPU 类型加载相应的收集器服务，每种类型一个收集器
+func loadCollectorService() error {
+	types, err := parseXpuTypes()
+	if err != nil {
+		return err
+	}
+	for _, t := range types {
+		switch t {
+		case npuservice.CollectorName:
+			// 加载 NPU 收集器服务，指定 npuSourceFile 时从文件读取指标，否则通过 libdcmi 采集
+			var source npuservice.DeviceSource = npuservice.NewDcmiSource(dcmiLibPath, npuservice.DefaultConfigDir)
+			if npuSourceFile != "" {
+				source = npuservice.NewFileSource(npuSourceFile)
+			}
+			err = serverHandler.RegisterCollectorService(npuservice.NewWithSource(npuservice.CollectorName, source))
+		case gpuservice.CollectorName:
+			// 加载 GPU 收集器服务
+			err = serverHandler.RegisterCollectorService(
+				gpuservice.NewWithClient(gpuservice.CollectorName, resolver, client.New(pidsClientConfig())))
+		default:
+			// XPU 类型参数无效
+			err = fmt.Errorf("the parameter type value[%s] error, range[gpu,npu,auto]", t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadConfigFile 读取配置文件，命令行未设置的参数使用文件中的值
+func loadConfigFile() error {
+	flag.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+	if configFile == "" {
+		return nil
+	}
+	conf, err := config.Load(configFile)
+	if err != nil {
+		return fmt.Errorf("load config file %s error: %v", configFile, err)
+	}
+	for key, value := range conf.Values() {
+		name := flagName(key)
+		if setFlags[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("the %s of config file is invalid: %v", key, err)
+		}
+	}
+	fileConf = conf
+	return nil
+}
+
+// flagName 返回配置项对应的命令行参数名
+func flagName(key string) string {
+	switch key {
+	case config.LogLevelKey:
+		return logLevelFlag
+	case config.LogFormatKey:
+		return logFormatFlag
 	default:
-		// XPU 类型参数缺失或无效
-		err = fmt.Errorf("the mandatory parameter type=npu or type=gpu is missing or value[%s] error", xpuType)
+		return key
+	}
+}
+
+// configValue 返回配置项的生效值，优先级为命令行参数、配置文件、参数默认值
+func configValue(conf *config.Config, key string) string {
+	name := flagName(key)
+	f := flag.Lookup(name)
+	if f == nil {
+		return ""
+	}
+	if setFlags[name] {
+		return f.Value.String()
+	}
+	if value, ok := conf.Values()[key]; ok {
+		return value
+	}
+	return f.DefValue
+}
+
+// reloadConfig 重新加载配置文件中可运行时生效的配置：请求限制、收集器开关、指标过滤重标记规则与日志级别
+func reloadConfig() {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+	conf, err := config.Load(configFile)
+	if err != nil {
+		log.Errorf("reload config file %s error: %v, keep the previous config", configFile, err)
+		return
+	}
+	if keys := config.RestartRequired(fileConf, conf); len(keys) > 0 {
+		log.Warningf("the changes of %v in config file take effect after restart", keys)
+	}
+	concurrency, err := strconv.Atoi(configValue(conf, "concurrency"))
+	if err != nil {
+		log.Errorf("reload config file error: the concurrency is invalid: %v", err)
+		return
+	}
+	if err = serverHandler.ReloadLimits(server.Limits{
+		Concurrency:    concurrency,
+		LimitIPReq:     configValue(conf, "limitIPReq"),
+		TrustedProxies: configValue(conf, "trustedProxies"),
+		AllowCIDRs:     configValue(conf, "allowCIDRs"),
+		DenyCIDRs:      configValue(conf, "denyCIDRs"),
+	}); err != nil {
+		log.Errorf("reload the limits of config file error: %v, keep the previous limits", err)
+	}
+	serverHandler.SetCollectorsEnabled(conf.Collectors)
+	if err = serverHandler.Relabeler.Update(conf.Metrics); err != nil {
+		log.Errorf("reload the metrics of config file error: %v, keep the previous rules", err)
+	}
+	// the level is only set when it changes, so that a reload keeps the debug level switched by SIGUSR1
+	if level := configValue(conf, config.LogLevelKey); level != configValue(fileConf, config.LogLevelKey) {
+		if err = log.SetLevel(level); err != nil {
+			log.Errorf("reload the log level of config file error: %v", err)
+		}
+	}
+	fileConf = conf
+	log.Infof("config file %s reloaded", configFile)
+}
+
+// watchConfig 在配置文件变化或收到 SIGHUP 信号时重新加载配置
+func watchConfig(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	go config.Watch(ctx, configFile, reloadConfig)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			reloadConfig()
+		}
 	}
-	return err
 }
 
 func main() {
 	flag.Parse()
+	if err := loadConfigFile(); err != nil {
+		log.Fatalln(err)
+	}
 
 	syscall.Umask(0)
-	logFileName := path.Join(defaultLogDir, "xpu-exporter.log")
-	log.InitLogging(logFileName)
+	logFileName := path.Join(logDir, "xpu-exporter.log")
+	log.SetComponent("xpu-exporter")
+	if err := log.InitLogging(logFileName); err != nil {
+		log.Fatalln(err)
+	}
 
 	log.Infof("npu exporter starting and the version is %s", versions.BuildVersion)
+	if err := loadResolver(); err != nil {
+		log.Fatalln(err)
+	}
 	if err := loadCollectorService(); err != nil {
 		log.Fatalln(err)
 	}
+	if fileConf != nil {
+		serverHandler.SetCollectorsEnabled(fileConf.Collectors)
+		// 配置文件存在时才可能在运行时更新规则，此时总是对采集结果应用规则
+		relabeler, err := relabel.NewRelabeler(fileConf.Metrics)
+		if err != nil {
+			log.Fatalf("the metrics of config file is invalid: %v", err)
+		}
+		serverHandler.Relabeler = relabeler
+	}
 
 	if err := checkCommonParamValid(); err != nil {
 		log.Fatalln(err)
@@ -109,12 +459,45 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	c := serverHandler.CreateCollector(cacheTime, time.Duration(updateTime)*time.Second)
 	reg := prometheus.NewRegistry()
-	reg.MustRegister(c)
+	for _, c := range serverHandler.CreateCollectors(time.Duration(cacheTime)*time.Second,
+		time.Duration(updateTime)*time.Second) {
+		reg.MustRegister(c)
+	}
+	otlpExporter, err := loadOtlpExporter(serverHandler.Gatherer(reg))
+	if err != nil {
+		log.Fatalln(err)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	wg := &sync.WaitGroup{}
+	if resolver != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resolver.Run(ctx)
+		}()
+	}
+	if fileConf != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			watchConfig(ctx)
+		}()
+	}
+	// 收到 SIGUSR1 时切换到 debug 日志级别，收到 SIGUSR2 时恢复配置的日志级别
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.WatchLevelSignals(ctx)
+	}()
+	if otlpExporter != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			otlpExporter.Run(ctx)
+		}()
+	}
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
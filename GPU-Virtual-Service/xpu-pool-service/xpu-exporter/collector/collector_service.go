@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	"huawei.com/xpu-exporter/common/utils"
 )
 
 // ICollectorService abstracts the collector service model and is compatible with various XPUs.
@@ -24,3 +26,9 @@ type ICollectorService interface {
 	//GetName return the name of collector service
 	GetName() string
 }
+
+// IDeviceProvider is implemented by the collector services which can return their cached devices.
+type IDeviceProvider interface {
+	// Devices returns the cached xpu devices with the nested vxpus keyed by device id
+	Devices() map[string]*utils.XPUDevice
+}
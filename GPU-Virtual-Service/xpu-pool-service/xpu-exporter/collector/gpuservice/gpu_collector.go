@@ -7,7 +7,9 @@
 package gpuservice
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"strconv"
 	"time"
@@ -15,14 +17,15 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"huawei.com/vxpu-device-plugin/pkg/log"
 
+	"huawei.com/xpu-exporter/collector"
 	"huawei.com/xpu-exporter/common/cache"
 	"huawei.com/xpu-exporter/common/client"
+	"huawei.com/xpu-exporter/common/metadata"
 	"huawei.com/xpu-exporter/common/utils"
 	"huawei.com/xpu-exporter/versions"
 )
 
 const (
-	podName       = "pod_name"
 	cntrName      = "container_name"
 	gpuUUid       = "gpu_uuid"
 	podUid        = "pod_uuid"
@@ -36,12 +39,26 @@ const (
 	model         = "model"
 	driverVersion = "driver_version"
 	cudaVersion   = "cuda_version"
+	limitType     = "limit_type"
+	resource      = "resource"
+
+	limitTypeMemory = "memory"
+	limitTypeCore   = "core"
+	resourceMemory  = "memory"
+	resourceCore    = "core"
+	resourceVgpu    = "vgpu"
+
+	// the memory of the pids service is in MiB, the core limit is in percent of a gpu
+	bytesPerMiB = 1024 * 1024
+	coresPerGpu = 100
 )
 
 var (
-	vgpuLabel = []string{gpuUUid, nodeName, nodeIp, podUid, cntrName, vgpuId, vgpuCoreLimit, vgpuMemLimit}
-	gpuLabel  = []string{gpuUUid, nodeName, nodeIp, nvmlIndex, model, driverVersion, cudaVersion}
-	nodeLabel = []string{nodeName, nodeIp}
+	vgpuLabel      = []string{gpuUUid, nodeName, nodeIp, podUid, cntrName, vgpuId, vgpuCoreLimit, vgpuMemLimit}
+	vgpuLimitLabel = append(append([]string{}, vgpuLabel...), limitType)
+	gpuLabel       = []string{gpuUUid, nodeName, nodeIp, nvmlIndex, model, driverVersion, cudaVersion}
+	gpuRatioLabel  = append(append([]string{}, gpuLabel...), resource)
+	nodeLabel      = []string{nodeName, nodeIp}
 )
 
 var (
@@ -61,28 +78,71 @@ var (
 		"power usage of gpu, the unit is milliwatts", gpuLabel, nil)
 	xpuGpuTemperatureDesc = prometheus.NewDesc("xpu_gpu_temperature",
 		"temperature of gpu, the unit is Celsius", gpuLabel, nil)
-	xpuVgpuUtilizationDesc = prometheus.NewDesc("xpu_vgpu_util",
-		"the utilization rate of computing power for vgpu", vgpuLabel, nil)
-	xpuVgpuMemoryUtilizationDesc = prometheus.NewDesc("xpu_vgpu_mem_util",
-		"the utilization rate of memory for vgpu", vgpuLabel, nil)
 	xpuVgpuNumberDesc = prometheus.NewDesc("xpu_vgpu_num",
 		"real time quantity of vgpu", []string{nodeName, nodeIp, gpuUUid}, nil)
 	xpuVgpuPodNumberDesc = prometheus.NewDesc("xpu_vgpu_pod_num",
 		"real time quantity of vgpu pods", []string{nodeName, nodeIp, gpuUUid}, nil)
+	xpuGpuMemoryAllocatedDesc = prometheus.NewDesc("xpu_gpu_mem_allocated_bytes",
+		"memory of gpu allocated to the vgpus by their memory limits, the unit is bytes", gpuLabel, nil)
+	xpuGpuMemoryFreeDesc = prometheus.NewDesc("xpu_gpu_mem_free_bytes",
+		"memory of gpu not allocated to any vgpu, the unit is bytes", gpuLabel, nil)
+	xpuGpuCoreAllocatedDesc = prometheus.NewDesc("xpu_gpu_core_allocated",
+		"computing power of gpu allocated to the vgpus by their core limits, in percent of the gpu", gpuLabel, nil)
+	xpuGpuCoreFreeDesc = prometheus.NewDesc("xpu_gpu_core_free",
+		"computing power of gpu not allocated to any vgpu, in percent of the gpu", gpuLabel, nil)
+	xpuGpuVgpuCapacityDesc = prometheus.NewDesc("xpu_gpu_vgpu_capacity",
+		"number of vgpus the gpu can be split into", gpuLabel, nil)
+	xpuGpuAllocationRatioDesc = prometheus.NewDesc("xpu_gpu_allocation_ratio",
+		"allocated ratio of the memory, core or vgpu capacity of gpu, range [0,1]", gpuRatioLabel, nil)
 
 	descriptions = []*prometheus.Desc{versionInfoDesc, xpuGpuUtilizationDesc, xpuGpuMemoryUtilizationDesc,
 		xpuGpuStatusDesc, xpuGpuNumberDesc, xpuGpuMemoryDesc, xpuGpuPowerUsageDesc, xpuGpuTemperatureDesc,
-		xpuVgpuUtilizationDesc, xpuVgpuMemoryUtilizationDesc, xpuVgpuNumberDesc, xpuVgpuPodNumberDesc}
+		xpuVgpuNumberDesc, xpuVgpuPodNumberDesc, xpuGpuMemoryAllocatedDesc, xpuGpuMemoryFreeDesc,
+		xpuGpuCoreAllocatedDesc, xpuGpuCoreFreeDesc, xpuGpuVgpuCapacityDesc, xpuGpuAllocationRatioDesc}
 )
 
+// vgpuDescriptions descriptions of the per vgpu metrics, whose labels are extended by the pod metadata
+type vgpuDescriptions struct {
+	utilization       *prometheus.Desc
+	memoryUtilization *prometheus.Desc
+	limitExceeded     *prometheus.Desc
+	memoryUsed        *prometheus.Desc
+	memoryLimit       *prometheus.Desc
+	coreLimit         *prometheus.Desc
+}
+
+func newVgpuDescriptions(extraLabels []string) vgpuDescriptions {
+	labels := append(append([]string{}, vgpuLabel...), extraLabels...)
+	limitLabels := append(append([]string{}, vgpuLimitLabel...), extraLabels...)
+	return vgpuDescriptions{
+		utilization: prometheus.NewDesc("xpu_vgpu_util",
+			"the utilization rate of computing power for vgpu", labels, nil),
+		memoryUtilization: prometheus.NewDesc("xpu_vgpu_mem_util",
+			"the utilization rate of memory for vgpu", labels, nil),
+		limitExceeded: prometheus.NewDesc("xpu_vgpu_limit_exceeded",
+			"whether the vgpu exceeds its memory or core limit for the sustained window", limitLabels, nil),
+		memoryUsed: prometheus.NewDesc("xpu_vgpu_mem_used_bytes",
+			"memory used by the processes of vgpu, the unit is bytes", labels, nil),
+		memoryLimit: prometheus.NewDesc("xpu_vgpu_mem_limit_bytes",
+			"memory limit of vgpu, the unit is bytes", labels, nil),
+		coreLimit: prometheus.NewDesc("xpu_vgpu_core_limit",
+			"computing power limit of vgpu, in percent of the gpu", labels, nil),
+	}
+}
+
 const (
-	cacheSize = 128
+	cacheSize    = 128
+	noExpiration = -1
 )
 
 type gpuCollector struct {
 	cache      *cache.ConcurrencyLRUCache
 	updateTime time.Duration
 	cacheTime  time.Duration
+	resolver   *metadata.Resolver
+	pidsClient *client.Client
+	vgpuDescs  vgpuDescriptions
+	refresher  *collector.Refresher
 }
 
 // Describe implements prometheus.Collector
@@ -94,6 +154,13 @@ func (n *gpuCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, desc := range descriptions {
 		ch <- desc
 	}
+	ch <- n.vgpuDescs.utilization
+	ch <- n.vgpuDescs.memoryUtilization
+	ch <- n.vgpuDescs.limitExceeded
+	ch <- n.vgpuDescs.memoryUsed
+	ch <- n.vgpuDescs.memoryLimit
+	ch <- n.vgpuDescs.coreLimit
+	n.refresher.Describe(ch)
 }
 
 // Collect implements prometheus.Collector
@@ -103,7 +170,7 @@ func (n *gpuCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	gpuDeviceMap := getVgpuInfoInCache(ch, n)
+	gpuDeviceMap := n.getVgpuInfoInCache()
 	ch <- prometheus.MustNewConstMetric(versionInfoDesc, prometheus.GaugeValue, 1,
 		[]string{versions.BuildVersion}...)
 
@@ -116,43 +183,46 @@ func (n *gpuCollector) Collect(ch chan<- prometheus.Metric) {
 		nodeName = gpuDevice.NodeName
 		nodeIp = gpuDevice.NodeIp
 		updateGpuDeviceInfo(ch, gpuDevice)
+		updateGpuAllocation(ch, gpuDevice)
 		vgpuDeviceCount := len(gpuDevice.VxpuDeviceList)
 		if vgpuDeviceCount <= 0 {
 			continue
 		}
 		vgpuDeviceTotalCount += vgpuDeviceCount
-		updateVgpuDeviceInfo(ch, gpuDevice)
+		n.updateVgpuDeviceInfo(ch, gpuDevice)
 	}
-	ch <- prometheus.MustNewConstMetric(xpuGpuNumberDesc, prometheus.CounterValue, float64(gpuDeviceCount),
+	ch <- prometheus.MustNewConstMetric(xpuGpuNumberDesc, prometheus.GaugeValue, float64(gpuDeviceCount),
 		[]string{nodeName, nodeIp}...)
+	n.refresher.Collect(ch)
 }
 
-func getVgpuInfoInCache(ch chan<- prometheus.Metric, n *gpuCollector) map[string]*utils.XPUDevice {
-	if ch == nil {
-		log.Errorln("metric channel is nil")
-		return nil
+// updateCache query the vgpu info through the pids service and store the parsed devices in the cache,
+// the cached devices never expire so that the last ones are served while the pids service is unavailable
+func (n *gpuCollector) updateCache() error {
+	vgpuInfo, err := n.pidsClient.GetAllVxpuInfo(context.Background())
+	if err != nil {
+		return err
 	}
+	var gpuDeviceMap map[string]*utils.XPUDevice
+	if err = json.Unmarshal([]byte(vgpuInfo), &gpuDeviceMap); err != nil {
+		return fmt.Errorf("convert vgpu info failed: %v", err)
+	}
+	return n.cache.Set(vgpuInfoCacheKey, gpuDeviceMap, noExpiration)
+}
 
+// getVgpuInfoInCache returns the devices stored by the background refresh, nil before the first success
+func (n *gpuCollector) getVgpuInfoInCache() map[string]*utils.XPUDevice {
+	if n.cache == nil {
+		return nil
+	}
 	obj, err := n.cache.Get(vgpuInfoCacheKey)
-	if obj == nil {
-		log.Warningln("no cache, start to get vgpuInfo and rebuild cache.")
-		vgpuInfo, err := client.GetAllVxpuInfo()
-		if err != nil {
-			log.Errorf("get vgpuInfo error: %v", err)
-			return nil
-		}
-		if err = n.cache.Set(vgpuInfoCacheKey, vgpuInfo, n.cacheTime); err != nil {
-			log.Errorf("no cache for prometheus, try to build cache failed, error is: %v", err)
-		} else {
-			log.Infoln("rebuild cache successfully")
-		}
-		obj = vgpuInfo
+	if err != nil || obj == nil {
+		return nil
 	}
-
-	var gpuDeviceMap map[string]*utils.XPUDevice
-	err = json.Unmarshal([]byte(obj.(string)), &gpuDeviceMap)
-	if err != nil {
-		log.Errorf("Error vgpu info cache and convert failed: %v", err)
+	gpuDeviceMap, ok := obj.(map[string]*utils.XPUDevice)
+	if !ok {
+		log.Errorln("Error vgpu info cache convert failed")
+		return nil
 	}
 	return gpuDeviceMap
 }
@@ -162,33 +232,74 @@ func updateGpuDeviceInfo(ch chan<- prometheus.Metric, gpu *utils.XPUDevice) {
 		log.Warningln("Invalid param in function updateGpuDeviceInfo")
 		return
 	}
-	ch <- prometheus.MustNewConstMetric(xpuGpuUtilizationDesc, prometheus.GaugeValue, gpu.XpuUtilization,
-		[]string{gpu.Id, gpu.NodeName, gpu.NodeIp, strconv.Itoa(int(gpu.Index)), gpu.Type, gpu.DriverVersion,
-			strconv.Itoa(gpu.FrameworkVersion)}...)
+	labels := gpuLabelValues(gpu)
+	ch <- prometheus.MustNewConstMetric(xpuGpuUtilizationDesc, prometheus.GaugeValue, gpu.XpuUtilization, labels...)
 	ch <- prometheus.MustNewConstMetric(xpuGpuMemoryUtilizationDesc, prometheus.GaugeValue,
-		gpu.MemoryUtilization, []string{gpu.Id, gpu.NodeName, gpu.NodeIp, strconv.Itoa(int(gpu.Index)),
-			gpu.Type, gpu.DriverVersion, strconv.Itoa(gpu.FrameworkVersion)}...)
+		gpu.MemoryUtilization, labels...)
 	var gpuStatus = 0
 	if gpu.Health {
 		gpuStatus = 1
 	}
-	ch <- prometheus.MustNewConstMetric(xpuGpuStatusDesc, prometheus.GaugeValue, float64(gpuStatus),
-		[]string{gpu.Id, gpu.NodeName, gpu.NodeIp, strconv.Itoa(int(gpu.Index)), gpu.Type, gpu.DriverVersion,
-			strconv.Itoa(gpu.FrameworkVersion)}...)
-	ch <- prometheus.MustNewConstMetric(xpuGpuMemoryDesc, prometheus.GaugeValue,
-		float64(gpu.MemoryTotal), []string{gpu.Id, gpu.NodeName, gpu.NodeIp, strconv.Itoa(int(gpu.Index)),
-			gpu.Type, gpu.DriverVersion, strconv.Itoa(gpu.FrameworkVersion)}...)
-	ch <- prometheus.MustNewConstMetric(xpuGpuPowerUsageDesc, prometheus.GaugeValue,
-		float64(gpu.PowerUsage), []string{gpu.Id, gpu.NodeName, gpu.NodeIp, strconv.Itoa(int(gpu.Index)),
-			gpu.Type, gpu.DriverVersion, strconv.Itoa(gpu.FrameworkVersion)}...)
-	ch <- prometheus.MustNewConstMetric(xpuGpuTemperatureDesc, prometheus.GaugeValue,
-		float64(gpu.Temperature), []string{gpu.Id, gpu.NodeName, gpu.NodeIp, strconv.Itoa(int(gpu.Index)),
-			gpu.Type, gpu.DriverVersion, strconv.Itoa(gpu.FrameworkVersion)}...)
+	ch <- prometheus.MustNewConstMetric(xpuGpuStatusDesc, prometheus.GaugeValue, float64(gpuStatus), labels...)
+	ch <- prometheus.MustNewConstMetric(xpuGpuMemoryDesc, prometheus.GaugeValue, float64(gpu.MemoryTotal), labels...)
+	ch <- prometheus.MustNewConstMetric(xpuGpuPowerUsageDesc, prometheus.GaugeValue, float64(gpu.PowerUsage),
+		labels...)
+	ch <- prometheus.MustNewConstMetric(xpuGpuTemperatureDesc, prometheus.GaugeValue, float64(gpu.Temperature),
+		labels...)
 	ch <- prometheus.MustNewConstMetric(xpuVgpuNumberDesc, prometheus.GaugeValue, float64(len(gpu.VxpuDeviceList)),
 		[]string{gpu.NodeName, gpu.NodeIp, gpu.Id}...)
 }
 
-func updateVgpuDeviceInfo(ch chan<- prometheus.Metric, gpu *utils.XPUDevice) {
+func gpuLabelValues(gpu *utils.XPUDevice, extra ...string) []string {
+	return append([]string{gpu.Id, gpu.NodeName, gpu.NodeIp, strconv.Itoa(int(gpu.Index)), gpu.Type,
+		gpu.DriverVersion, strconv.Itoa(gpu.FrameworkVersion)}, extra...)
+}
+
+// updateGpuAllocation exports the memory and cores of gpu allocated to the vgpus by their limits,
+// a vgpu without a core limit allocates no cores
+func updateGpuAllocation(ch chan<- prometheus.Metric, gpu *utils.XPUDevice) {
+	var allocatedMemory, allocatedCores int64
+	for _, vgpu := range gpu.VxpuDeviceList {
+		allocatedMemory += vgpu.VxpuMemoryLimit
+		allocatedCores += vgpu.VxpuCoreLimit
+	}
+	freeMemory := int64(gpu.MemoryTotal) - allocatedMemory
+	if freeMemory < 0 {
+		freeMemory = 0
+	}
+	freeCores := coresPerGpu - allocatedCores
+	if freeCores < 0 {
+		freeCores = 0
+	}
+	labels := gpuLabelValues(gpu)
+	ch <- prometheus.MustNewConstMetric(xpuGpuMemoryAllocatedDesc, prometheus.GaugeValue,
+		float64(allocatedMemory)*bytesPerMiB, labels...)
+	ch <- prometheus.MustNewConstMetric(xpuGpuMemoryFreeDesc, prometheus.GaugeValue,
+		float64(freeMemory)*bytesPerMiB, labels...)
+	ch <- prometheus.MustNewConstMetric(xpuGpuCoreAllocatedDesc, prometheus.GaugeValue,
+		float64(allocatedCores), labels...)
+	ch <- prometheus.MustNewConstMetric(xpuGpuCoreFreeDesc, prometheus.GaugeValue, float64(freeCores), labels...)
+	ch <- prometheus.MustNewConstMetric(xpuGpuVgpuCapacityDesc, prometheus.GaugeValue, float64(gpu.Count), labels...)
+
+	ratios := map[string]float64{
+		resourceMemory: ratio(float64(allocatedMemory), float64(gpu.MemoryTotal)),
+		resourceCore:   ratio(float64(allocatedCores), coresPerGpu),
+		resourceVgpu:   ratio(float64(len(gpu.VxpuDeviceList)), float64(gpu.Count)),
+	}
+	for name, value := range ratios {
+		ch <- prometheus.MustNewConstMetric(xpuGpuAllocationRatioDesc, prometheus.GaugeValue, value,
+			gpuLabelValues(gpu, name)...)
+	}
+}
+
+func ratio(used, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return used / total
+}
+
+func (n *gpuCollector) updateVgpuDeviceInfo(ch chan<- prometheus.Metric, gpu *utils.XPUDevice) {
 	if !validate(ch) {
 		log.Warningln("Invalid param in function updateVgpuDeviceInfo")
 		return
@@ -196,14 +307,20 @@ func updateVgpuDeviceInfo(ch chan<- prometheus.Metric, gpu *utils.XPUDevice) {
 	var vgpuPodNumber = 0
 	vgpuPodMap := make(map[string]int)
 	for _, vgpu := range gpu.VxpuDeviceList {
-		ch <- prometheus.MustNewConstMetric(xpuVgpuUtilizationDesc, prometheus.GaugeValue,
-			vgpu.VxpuCoreUtilization, []string{gpu.Id, gpu.NodeName, gpu.NodeIp, vgpu.PodUID,
-				vgpu.ContainerName, vgpu.Id, strconv.Itoa(int(vgpu.VxpuCoreLimit)),
-				strconv.Itoa(int(vgpu.VxpuMemoryLimit))})
-		ch <- prometheus.MustNewConstMetric(xpuVgpuMemoryUtilizationDesc, prometheus.GaugeValue,
-			vgpu.VxpuMemoryUtilization, []string{gpu.Id, gpu.NodeName, gpu.NodeIp, vgpu.PodUID,
-				vgpu.ContainerName, vgpu.Id, strconv.Itoa(int(vgpu.VxpuCoreLimit)),
-				strconv.Itoa(int(vgpu.VxpuMemoryLimit))})
+		labels := append([]string{gpu.Id, gpu.NodeName, gpu.NodeIp, vgpu.PodUID, vgpu.ContainerName, vgpu.Id,
+			strconv.Itoa(int(vgpu.VxpuCoreLimit)), strconv.Itoa(int(vgpu.VxpuMemoryLimit))},
+			n.resolver.LabelValues(vgpu.PodUID)...)
+		ch <- prometheus.MustNewConstMetric(n.vgpuDescs.utilization, prometheus.GaugeValue,
+			vgpu.VxpuCoreUtilization, labels...)
+		ch <- prometheus.MustNewConstMetric(n.vgpuDescs.memoryUtilization, prometheus.GaugeValue,
+			vgpu.VxpuMemoryUtilization, labels...)
+		ch <- prometheus.MustNewConstMetric(n.vgpuDescs.memoryUsed, prometheus.GaugeValue,
+			float64(vgpu.VxpuMemoryUsed)*bytesPerMiB, labels...)
+		ch <- prometheus.MustNewConstMetric(n.vgpuDescs.memoryLimit, prometheus.GaugeValue,
+			float64(vgpu.VxpuMemoryLimit)*bytesPerMiB, labels...)
+		ch <- prometheus.MustNewConstMetric(n.vgpuDescs.coreLimit, prometheus.GaugeValue,
+			float64(vgpu.VxpuCoreLimit), labels...)
+		n.updateVgpuLimitExceeded(ch, vgpu, labels)
 		if _, ok := vgpuPodMap[vgpu.PodUID]; !ok {
 			vgpuPodNumber += 1
 			vgpuPodMap[vgpu.PodUID] = vgpuPodNumber
@@ -213,6 +330,21 @@ func updateVgpuDeviceInfo(ch chan<- prometheus.Metric, gpu *utils.XPUDevice) {
 		[]string{gpu.NodeName, gpu.NodeIp, gpu.Id}...)
 }
 
+// updateVgpuLimitExceeded inserts the limit type after the vgpu labels and before the pod metadata labels
+func (n *gpuCollector) updateVgpuLimitExceeded(ch chan<- prometheus.Metric, vgpu utils.VxpuDevice, labels []string) {
+	exceeded := map[string]bool{limitTypeMemory: vgpu.MemoryLimitExceeded, limitTypeCore: vgpu.CoreLimitExceeded}
+	for limit, ok := range exceeded {
+		var value = 0
+		if ok {
+			value = 1
+		}
+		limitLabels := append(append(append([]string{}, labels[:len(vgpuLabel)]...), limit),
+			labels[len(vgpuLabel):]...)
+		ch <- prometheus.MustNewConstMetric(n.vgpuDescs.limitExceeded, prometheus.GaugeValue, float64(value),
+			limitLabels...)
+	}
+}
+
 func validate(ch chan<- prometheus.Metric, objs ...interface{}) bool {
 	if ch == nil {
 		return false
@@ -7,31 +7,39 @@ package gpuservice
 
 import (
 	"context"
-	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"huawei.com/vxpu-device-plugin/pkg/log"
+
 	"huawei.com/xpu-exporter/collector"
 	"huawei.com/xpu-exporter/common/cache"
 	"huawei.com/xpu-exporter/common/client"
+	"huawei.com/xpu-exporter/common/metadata"
+	"huawei.com/xpu-exporter/common/utils"
 )
 
 const (
 	// CollectorName for gpu collector
-	CollectorName       = "gpu"
-	vgpuInfoCacheKey    = "xpu-exporter-vgpu-info"
-	updateCachePattern  = "update cache,key is %s"
-	tickerFailedPattern = "%s ticker failed, task shutdown"
+	CollectorName    = "gpu"
+	vgpuInfoCacheKey = "xpu-exporter-vgpu-info"
 )
 
 type gpuCollectorService struct {
 	serviceName string
+	resolver    *metadata.Resolver
+	pidsClient  *client.Client
 	collector   gpuCollector
 }
 
 // New create one gpu collector service instance
 func New(name string) collector.ICollectorService {
-	return &gpuCollectorService{serviceName: name}
+	return NewWithClient(name, nil, client.New(client.Config{}))
+}
+
+// NewWithClient create one gpu collector service instance querying the vgpus through pidsClient
+func NewWithClient(name string, resolver *metadata.Resolver, pidsClient *client.Client) collector.ICollectorService {
+	return &gpuCollectorService{serviceName: name, resolver: resolver, pidsClient: pidsClient}
 }
 
 // GetName obtains the service name.
@@ -45,41 +53,31 @@ func (s *gpuCollectorService) CreateCollector(cacheTime time.Duration, updateTim
 		cache:      cache.New(cacheSize),
 		cacheTime:  cacheTime,
 		updateTime: updateTime,
+		resolver:   s.resolver,
+		pidsClient: s.pidsClient,
+		vgpuDescs:  newVgpuDescriptions(s.resolver.LabelNames()),
 	}
+	s.collector.refresher = collector.NewRefresher(s.serviceName, s.collector.updateCache, updateTime, cacheTime)
 	return &s.collector
 }
 
-// Start start collect gpu monitoring data
-func (s *gpuCollectorService) Start(ctx context.Context, fn context.CancelFunc) {
-	group := &sync.WaitGroup{}
-	vgpuInfoCollect(ctx, group, &s.collector)
-	group.Wait()
-}
-
-func vgpuInfoCollect(ctx context.Context, group *sync.WaitGroup, n *gpuCollector) {
-	group.Add(1)
-	go func() {
-		setVgpuInfoToCache(ctx, group, n)
-	}()
+// Devices returns the cached gpu devices with the nested vgpus
+func (s *gpuCollectorService) Devices() map[string]*utils.XPUDevice {
+	return s.collector.getVgpuInfoInCache()
 }
 
-func setVgpuInfoToCache(ctx context.Context, group *sync.WaitGroup, n *gpuCollector) {
-	defer group.Done()
-	ticker := time.NewTicker(n.updateTime)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			_, err := client.GetAllVxpuInfo()
-			if err != nil {
-				return
-			}
-			if _, ok := <-ticker.C; !ok {
-				return
-			}
-		}
+// Start refresh the vgpu info cache in the background until ctx is done, the connection to the pids service
+// is kept and watched meanwhile
+func (s *gpuCollectorService) Start(ctx context.Context, fn context.CancelFunc) {
+	if s.collector.refresher == nil {
+		log.Errorln("gpu collector is not created, task shutdown")
+		return
 	}
+	defer func() {
+		if err := s.pidsClient.Close(); err != nil {
+			log.Warningf("close pids service connection error: %v", err)
+		}
+	}()
+	go s.pidsClient.Watch(ctx)
+	s.collector.refresher.Run(ctx)
 }
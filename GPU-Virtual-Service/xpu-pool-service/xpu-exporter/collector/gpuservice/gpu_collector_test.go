@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package gpuservice implement gpu collecion service interface.
+package gpuservice
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"huawei.com/xpu-exporter/common/utils"
+)
+
+const testVgpuInfo = `{"GPU-0":{"Index":0,"Id":"GPU-0","Type":"A100","Health":true,"Count":4,
+"MemoryTotal":40960,"NodeName":"node1","NodeIp":"192.168.0.1","DriverVersion":"535.54","FrameworkVersion":12020,
+"VxpuDeviceList":[
+{"Id":"GPU-0-0","GpuId":"GPU-0","PodUID":"pod-1","ContainerName":"train","VxpuMemoryUsed":2048,
+"VxpuMemoryLimit":10240,"VxpuCoreLimit":30},
+{"Id":"GPU-0-1","GpuId":"GPU-0","PodUID":"pod-2","ContainerName":"infer","VxpuMemoryUsed":1024,
+"VxpuMemoryLimit":10240,"VxpuCoreLimit":20}]}}`
+
+func newTestCollector(t *testing.T) prometheus.Collector {
+	s := New(CollectorName)
+	c := s.CreateCollector(time.Minute, time.Second)
+	assert.NotNil(t, c)
+	gc, ok := c.(*gpuCollector)
+	assert.True(t, ok)
+	var devices map[string]*utils.XPUDevice
+	assert.NoError(t, json.Unmarshal([]byte(testVgpuInfo), &devices))
+	assert.NoError(t, gc.cache.Set(vgpuInfoCacheKey, devices, noExpiration))
+	return c
+}
+
+func TestCollectAllocationMetrics(t *testing.T) {
+	c := newTestCollector(t)
+	expected := `
+# HELP xpu_gpu_num number of gpus
+# TYPE xpu_gpu_num gauge
+xpu_gpu_num{node_ip="192.168.0.1",node_name="node1"} 1
+# HELP xpu_gpu_mem_allocated_bytes memory of gpu allocated to the vgpus by their memory limits, the unit is bytes
+# TYPE xpu_gpu_mem_allocated_bytes gauge
+xpu_gpu_mem_allocated_bytes{cuda_version="12020",driver_version="535.54",gpu_uuid="GPU-0",model="A100",node_ip="192.168.0.1",node_name="node1",nvml_index="0"} 2.147483648e+10
+# HELP xpu_gpu_mem_free_bytes memory of gpu not allocated to any vgpu, the unit is bytes
+# TYPE xpu_gpu_mem_free_bytes gauge
+xpu_gpu_mem_free_bytes{cuda_version="12020",driver_version="535.54",gpu_uuid="GPU-0",model="A100",node_ip="192.168.0.1",node_name="node1",nvml_index="0"} 2.147483648e+10
+# HELP xpu_gpu_core_free computing power of gpu not allocated to any vgpu, in percent of the gpu
+# TYPE xpu_gpu_core_free gauge
+xpu_gpu_core_free{cuda_version="12020",driver_version="535.54",gpu_uuid="GPU-0",model="A100",node_ip="192.168.0.1",node_name="node1",nvml_index="0"} 50
+# HELP xpu_gpu_allocation_ratio allocated ratio of the memory, core or vgpu capacity of gpu, range [0,1]
+# TYPE xpu_gpu_allocation_ratio gauge
+xpu_gpu_allocation_ratio{cuda_version="12020",driver_version="535.54",gpu_uuid="GPU-0",model="A100",node_ip="192.168.0.1",node_name="node1",nvml_index="0",resource="core"} 0.5
+xpu_gpu_allocation_ratio{cuda_version="12020",driver_version="535.54",gpu_uuid="GPU-0",model="A100",node_ip="192.168.0.1",node_name="node1",nvml_index="0",resource="memory"} 0.5
+xpu_gpu_allocation_ratio{cuda_version="12020",driver_version="535.54",gpu_uuid="GPU-0",model="A100",node_ip="192.168.0.1",node_name="node1",nvml_index="0",resource="vgpu"} 0.5
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "xpu_gpu_num",
+		"xpu_gpu_mem_allocated_bytes", "xpu_gpu_mem_free_bytes", "xpu_gpu_core_free", "xpu_gpu_allocation_ratio"))
+}
+
+func TestCollectVgpuAbsoluteMetrics(t *testing.T) {
+	c := newTestCollector(t)
+	expected := `
+# HELP xpu_vgpu_mem_used_bytes memory used by the processes of vgpu, the unit is bytes
+# TYPE xpu_vgpu_mem_used_bytes gauge
+xpu_vgpu_mem_used_bytes{container_name="infer",gpu_uuid="GPU-0",node_ip="192.168.0.1",node_name="node1",pod_uuid="pod-2",vgpu_core_limit="20",vgpu_id="GPU-0-1",vgpu_mem_limit="10240"} 1.073741824e+09
+xpu_vgpu_mem_used_bytes{container_name="train",gpu_uuid="GPU-0",node_ip="192.168.0.1",node_name="node1",pod_uuid="pod-1",vgpu_core_limit="30",vgpu_id="GPU-0-0",vgpu_mem_limit="10240"} 2.147483648e+09
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "xpu_vgpu_mem_used_bytes"))
+	assert.Equal(t, 2, testutil.CollectAndCount(c, "xpu_vgpu_mem_limit_bytes"))
+	assert.Equal(t, 2, testutil.CollectAndCount(c, "xpu_vgpu_core_limit"))
+	assert.Equal(t, 1, testutil.CollectAndCount(c, "xpu_gpu_vgpu_capacity"))
+}
+
+func TestCollectWithoutCache(t *testing.T) {
+	s := New(CollectorName)
+	c := s.CreateCollector(time.Minute, time.Second)
+	// nothing is refreshed yet, the scrape does not query the pids service and reports the stale cache
+	assert.Equal(t, 0, testutil.CollectAndCount(c, "xpu_gpu_util"))
+	assert.Equal(t, 1, testutil.CollectAndCount(c, "xpu_exporter_cache_stale"))
+	assert.Equal(t, 0, testutil.CollectAndCount(c, "xpu_exporter_cache_age_seconds"))
+}
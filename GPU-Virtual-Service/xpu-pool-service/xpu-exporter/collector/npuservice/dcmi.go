@@ -0,0 +1,354 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// In this file, the cgo feature is used to invoke the DCMI library of the Ascend driver.
+// Only the APIs needed by the npu collector are bound.
+
+package npuservice
+
+// #cgo LDFLAGS: -ldl
+/*
+#include <stddef.h>
+#include <dlfcn.h>
+#include <stdio.h>
+#include <stdlib.h>
+
+#define DCMI_OK 0
+#define DCMI_ERR_LIBRARY_NOT_FOUND -99998
+#define DCMI_ERR_FUNCTION_NOT_FOUND -99999
+#define DCMI_CHIP_INFO_LEN 32
+#define DCMI_DIE_ID_LEN 5
+#define DCMI_MAX_CARD_NUM 64
+#define DCMI_MAX_PROC_NUM 1024
+
+struct dcmi_chip_info {
+    unsigned char chip_type[DCMI_CHIP_INFO_LEN];
+    unsigned char chip_name[DCMI_CHIP_INFO_LEN];
+    unsigned char chip_ver[DCMI_CHIP_INFO_LEN];
+    unsigned int aicore_cnt;
+};
+
+struct dcmi_hbm_info {
+    unsigned long long memory_size;
+    unsigned int freq;
+    unsigned long long memory_usage;
+    int temp;
+    unsigned int bandwith_util_rate;
+};
+
+struct dcmi_die_id {
+    unsigned int soc_die[DCMI_DIE_ID_LEN];
+};
+
+struct dcmi_proc_mem_info {
+    int proc_id;
+    unsigned long proc_mem_usage;
+};
+
+typedef int (*DcmiInitFunc)(void);
+typedef int (*DcmiGetCardNumListFunc)(int *card_num, int *card_list, int list_len);
+typedef int (*DcmiGetDeviceNumInCardFunc)(int card_id, int *device_num);
+typedef int (*DcmiGetDeviceLogicIdFunc)(int *device_logic_id, int card_id, int device_id);
+typedef int (*DcmiGetDeviceChipInfoFunc)(int card_id, int device_id, struct dcmi_chip_info *chip_info);
+typedef int (*DcmiGetDeviceUtilizationRateFunc)(int card_id, int device_id, int input_type, unsigned int *rate);
+typedef int (*DcmiGetDeviceHbmInfoFunc)(int card_id, int device_id, struct dcmi_hbm_info *hbm_info);
+typedef int (*DcmiGetDeviceTemperatureFunc)(int card_id, int device_id, int *temperature);
+typedef int (*DcmiGetDevicePowerInfoFunc)(int card_id, int device_id, int *power);
+typedef int (*DcmiGetDeviceHealthFunc)(int card_id, int device_id, unsigned int *health);
+typedef int (*DcmiGetDeviceDieV2Func)(int card_id, int device_id, int input_type, struct dcmi_die_id *die_id);
+typedef int (*DcmiGetDriverVersionFunc)(char *driver_ver, unsigned int len);
+typedef int (*DcmiGetDeviceResourceInfoFunc)(int card_id, int device_id, struct dcmi_proc_mem_info *proc_info,
+    int *proc_num);
+
+void *dcmiHandle;
+DcmiInitFunc dcmiInitFunc = NULL;
+DcmiGetCardNumListFunc dcmiGetCardNumListFunc = NULL;
+DcmiGetDeviceNumInCardFunc dcmiGetDeviceNumInCardFunc = NULL;
+DcmiGetDeviceLogicIdFunc dcmiGetDeviceLogicIdFunc = NULL;
+DcmiGetDeviceChipInfoFunc dcmiGetDeviceChipInfoFunc = NULL;
+DcmiGetDeviceUtilizationRateFunc dcmiGetDeviceUtilizationRateFunc = NULL;
+DcmiGetDeviceHbmInfoFunc dcmiGetDeviceHbmInfoFunc = NULL;
+DcmiGetDeviceTemperatureFunc dcmiGetDeviceTemperatureFunc = NULL;
+DcmiGetDevicePowerInfoFunc dcmiGetDevicePowerInfoFunc = NULL;
+DcmiGetDeviceHealthFunc dcmiGetDeviceHealthFunc = NULL;
+DcmiGetDeviceDieV2Func dcmiGetDeviceDieV2Func = NULL;
+DcmiGetDriverVersionFunc dcmiGetDriverVersionFunc = NULL;
+DcmiGetDeviceResourceInfoFunc dcmiGetDeviceResourceInfoFunc = NULL;
+
+int dcmiInit(void) {
+    return (dcmiInitFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND : dcmiInitFunc();
+}
+
+int dcmiGetCardNumList(int *card_num, int *card_list, int list_len) {
+    return (dcmiGetCardNumListFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetCardNumListFunc(card_num, card_list, list_len);
+}
+
+int dcmiGetDeviceNumInCard(int card_id, int *device_num) {
+    return (dcmiGetDeviceNumInCardFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetDeviceNumInCardFunc(card_id, device_num);
+}
+
+int dcmiGetDeviceLogicId(int *device_logic_id, int card_id, int device_id) {
+    return (dcmiGetDeviceLogicIdFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetDeviceLogicIdFunc(device_logic_id, card_id, device_id);
+}
+
+int dcmiGetDeviceChipInfo(int card_id, int device_id, struct dcmi_chip_info *chip_info) {
+    return (dcmiGetDeviceChipInfoFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetDeviceChipInfoFunc(card_id, device_id, chip_info);
+}
+
+int dcmiGetDeviceUtilizationRate(int card_id, int device_id, int input_type, unsigned int *rate) {
+    return (dcmiGetDeviceUtilizationRateFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetDeviceUtilizationRateFunc(card_id, device_id, input_type, rate);
+}
+
+int dcmiGetDeviceHbmInfo(int card_id, int device_id, struct dcmi_hbm_info *hbm_info) {
+    return (dcmiGetDeviceHbmInfoFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetDeviceHbmInfoFunc(card_id, device_id, hbm_info);
+}
+
+int dcmiGetDeviceTemperature(int card_id, int device_id, int *temperature) {
+    return (dcmiGetDeviceTemperatureFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetDeviceTemperatureFunc(card_id, device_id, temperature);
+}
+
+int dcmiGetDevicePowerInfo(int card_id, int device_id, int *power) {
+    return (dcmiGetDevicePowerInfoFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetDevicePowerInfoFunc(card_id, device_id, power);
+}
+
+int dcmiGetDeviceHealth(int card_id, int device_id, unsigned int *health) {
+    return (dcmiGetDeviceHealthFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetDeviceHealthFunc(card_id, device_id, health);
+}
+
+int dcmiGetDeviceDieV2(int card_id, int device_id, int input_type, struct dcmi_die_id *die_id) {
+    return (dcmiGetDeviceDieV2Func == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetDeviceDieV2Func(card_id, device_id, input_type, die_id);
+}
+
+int dcmiGetDriverVersion(char *driver_ver, unsigned int len) {
+    return (dcmiGetDriverVersionFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetDriverVersionFunc(driver_ver, len);
+}
+
+int dcmiGetDeviceResourceInfo(int card_id, int device_id, struct dcmi_proc_mem_info *proc_info, int *proc_num) {
+    return (dcmiGetDeviceResourceInfoFunc == NULL) ? DCMI_ERR_FUNCTION_NOT_FOUND :
+        dcmiGetDeviceResourceInfoFunc(card_id, device_id, proc_info, proc_num);
+}
+
+static void loadSymbol(const char *symbolName, void **symbolPtr) {
+    *symbolPtr = dlsym(dcmiHandle, symbolName);
+    if (!*symbolPtr) {
+        fprintf(stderr, "Failed to load symbol %s\n", symbolName);
+    }
+}
+
+// Loads libdcmi.so from the library path or the default driver dir and all required symbols.
+int loadDcmiLibrary(const char *libPath) {
+    dcmiHandle = dlopen(libPath, RTLD_LAZY);
+    if (dcmiHandle == NULL) {
+        fprintf(stderr, "Failed to load %s: %s\n", libPath, dlerror());
+        return DCMI_ERR_LIBRARY_NOT_FOUND;
+    }
+
+    loadSymbol("dcmi_init", (void**)(&dcmiInitFunc));
+    loadSymbol("dcmi_get_card_num_list", (void**)(&dcmiGetCardNumListFunc));
+    loadSymbol("dcmi_get_device_num_in_card", (void**)(&dcmiGetDeviceNumInCardFunc));
+    loadSymbol("dcmi_get_device_logic_id", (void**)(&dcmiGetDeviceLogicIdFunc));
+    loadSymbol("dcmi_get_device_chip_info", (void**)(&dcmiGetDeviceChipInfoFunc));
+    loadSymbol("dcmi_get_device_utilization_rate", (void**)(&dcmiGetDeviceUtilizationRateFunc));
+    loadSymbol("dcmi_get_device_hbm_info", (void**)(&dcmiGetDeviceHbmInfoFunc));
+    loadSymbol("dcmi_get_device_temperature", (void**)(&dcmiGetDeviceTemperatureFunc));
+    loadSymbol("dcmi_get_device_power_info", (void**)(&dcmiGetDevicePowerInfoFunc));
+    loadSymbol("dcmi_get_device_health", (void**)(&dcmiGetDeviceHealthFunc));
+    loadSymbol("dcmi_get_device_die_v2", (void**)(&dcmiGetDeviceDieV2Func));
+    loadSymbol("dcmi_get_driver_version", (void**)(&dcmiGetDriverVersionFunc));
+    loadSymbol("dcmi_get_device_resource_info", (void**)(&dcmiGetDeviceResourceInfoFunc));
+    return DCMI_OK;
+}
+
+int unloadDcmiLibrary(void) {
+    if (dcmiHandle == NULL) {
+        return DCMI_OK;
+    }
+    if (dlclose(dcmiHandle) != 0) {
+        return DCMI_ERR_LIBRARY_NOT_FOUND;
+    }
+    dcmiHandle = NULL;
+    return DCMI_OK;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"huawei.com/vxpu-device-plugin/pkg/log"
+)
+
+const (
+	// DefaultDcmiLibPath path of libdcmi.so installed by the Ascend driver
+	DefaultDcmiLibPath = "/usr/local/Ascend/driver/lib64/driver/libdcmi.so"
+
+	dcmiUtilizationAICore = 2
+	dcmiDieTypeVDie       = 1
+	dcmiHealthOk          = 0
+	dcmiVersionLen        = 64
+	// the power reported by dcmi is in 0.1W
+	milliwattsPerDcmiPower = 100
+)
+
+// dcmiChip identifies a chip by the card id and the device id in the card
+type dcmiChip struct {
+	cardId   int32
+	deviceId int32
+}
+
+func dcmiError(api string, ret C.int) error {
+	if ret == C.DCMI_OK {
+		return nil
+	}
+	return fmt.Errorf("dcmi %s failed: %d", api, int(ret))
+}
+
+func dcmiLoad(libPath string) error {
+	cPath := C.CString(libPath)
+	defer C.free(unsafe.Pointer(cPath))
+	if err := dcmiError("dlopen", C.loadDcmiLibrary(cPath)); err != nil {
+		return err
+	}
+	if err := dcmiError("init", C.dcmiInit()); err != nil {
+		if unloadErr := dcmiUnload(); unloadErr != nil {
+			log.Warningf("unload dcmi library error: %v", unloadErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func dcmiUnload() error {
+	return dcmiError("dlclose", C.unloadDcmiLibrary())
+}
+
+// dcmiCount bounds a count returned by dcmi to the length of the list it filled
+func dcmiCount(n C.int, listLen int) int {
+	if n < 0 {
+		return 0
+	}
+	if int(n) > listLen {
+		return listLen
+	}
+	return int(n)
+}
+
+func dcmiChips() ([]dcmiChip, error) {
+	var cardNum C.int
+	cardList := make([]C.int, C.DCMI_MAX_CARD_NUM)
+	ret := C.dcmiGetCardNumList(&cardNum, &cardList[0], C.DCMI_MAX_CARD_NUM)
+	if err := dcmiError("get card num list", ret); err != nil {
+		return nil, err
+	}
+	var chips []dcmiChip
+	for _, cardId := range cardList[:dcmiCount(cardNum, len(cardList))] {
+		var deviceNum C.int
+		if err := dcmiError("get device num in card", C.dcmiGetDeviceNumInCard(cardId, &deviceNum)); err != nil {
+			return nil, err
+		}
+		for deviceId := 0; deviceId < int(deviceNum); deviceId++ {
+			chips = append(chips, dcmiChip{cardId: int32(cardId), deviceId: int32(deviceId)})
+		}
+	}
+	return chips, nil
+}
+
+func (c dcmiChip) logicId() (int32, error) {
+	var logicId C.int
+	ret := C.dcmiGetDeviceLogicId(&logicId, C.int(c.cardId), C.int(c.deviceId))
+	return int32(logicId), dcmiError("get device logic id", ret)
+}
+
+// vdieId formats the vdie id of the chip, it is unique on the node and used as the chip id
+func (c dcmiChip) vdieId() (string, error) {
+	var dieId C.struct_dcmi_die_id
+	ret := C.dcmiGetDeviceDieV2(C.int(c.cardId), C.int(c.deviceId), dcmiDieTypeVDie, &dieId)
+	if err := dcmiError("get device die", ret); err != nil {
+		return "", err
+	}
+	parts := make([]string, 0, C.DCMI_DIE_ID_LEN)
+	for _, v := range dieId.soc_die {
+		parts = append(parts, fmt.Sprintf("%08X", uint32(v)))
+	}
+	return strings.Join(parts, ""), nil
+}
+
+func (c dcmiChip) name() (string, error) {
+	var info C.struct_dcmi_chip_info
+	ret := C.dcmiGetDeviceChipInfo(C.int(c.cardId), C.int(c.deviceId), &info)
+	if err := dcmiError("get device chip info", ret); err != nil {
+		return "", err
+	}
+	return C.GoString((*C.char)(unsafe.Pointer(&info.chip_name[0]))), nil
+}
+
+func (c dcmiChip) aiCoreUtilization() (uint32, error) {
+	var rate C.uint
+	ret := C.dcmiGetDeviceUtilizationRate(C.int(c.cardId), C.int(c.deviceId), dcmiUtilizationAICore, &rate)
+	return uint32(rate), dcmiError("get device utilization rate", ret)
+}
+
+// hbm returns the total and used hbm in MB
+func (c dcmiChip) hbm() (uint64, uint64, error) {
+	var info C.struct_dcmi_hbm_info
+	ret := C.dcmiGetDeviceHbmInfo(C.int(c.cardId), C.int(c.deviceId), &info)
+	return uint64(info.memory_size), uint64(info.memory_usage), dcmiError("get device hbm info", ret)
+}
+
+func (c dcmiChip) temperature() (int, error) {
+	var temperature C.int
+	ret := C.dcmiGetDeviceTemperature(C.int(c.cardId), C.int(c.deviceId), &temperature)
+	return int(temperature), dcmiError("get device temperature", ret)
+}
+
+// power returns the power usage in milliwatts, like the gpu power usage
+func (c dcmiChip) power() (int, error) {
+	var power C.int
+	ret := C.dcmiGetDevicePowerInfo(C.int(c.cardId), C.int(c.deviceId), &power)
+	return int(power) * milliwattsPerDcmiPower, dcmiError("get device power info", ret)
+}
+
+func (c dcmiChip) healthy() (bool, error) {
+	var health C.uint
+	ret := C.dcmiGetDeviceHealth(C.int(c.cardId), C.int(c.deviceId), &health)
+	return health == dcmiHealthOk, dcmiError("get device health", ret)
+}
+
+// processMemory returns the memory in bytes used by each process on the chip
+func (c dcmiChip) processMemory() (map[uint32]uint64, error) {
+	procs := make([]C.struct_dcmi_proc_mem_info, C.DCMI_MAX_PROC_NUM)
+	procNum := C.int(C.DCMI_MAX_PROC_NUM)
+	ret := C.dcmiGetDeviceResourceInfo(C.int(c.cardId), C.int(c.deviceId), &procs[0], &procNum)
+	if err := dcmiError("get device resource info", ret); err != nil {
+		return nil, err
+	}
+	procs = procs[:dcmiCount(procNum, len(procs))]
+	procMem := make(map[uint32]uint64, len(procs))
+	for _, p := range procs {
+		procMem[uint32(p.proc_id)] = uint64(p.proc_mem_usage)
+	}
+	return procMem, nil
+}
+
+func dcmiDriverVersion() (string, error) {
+	version := make([]C.char, dcmiVersionLen)
+	ret := C.dcmiGetDriverVersion(&version[0], dcmiVersionLen)
+	if err := dcmiError("get driver version", ret); err != nil {
+		return "", err
+	}
+	return C.GoString(&version[0]), nil
+}
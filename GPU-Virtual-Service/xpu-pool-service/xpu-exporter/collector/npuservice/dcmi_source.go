@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package npuservice
+
+import (
+	"os"
+
+	"huawei.com/vxpu-device-plugin/pkg/log"
+
+	"huawei.com/xpu-exporter/common/utils"
+)
+
+// DcmiSource reads the npu chips through libdcmi and the vnpus of the containers from the vxpu config dir
+type DcmiSource struct {
+	libPath       string
+	configDir     string
+	nodeName      string
+	nodeIp        string
+	driverVersion string
+}
+
+// NewDcmiSource create a source loading libdcmi.so from libPath
+func NewDcmiSource(libPath, configDir string) *DcmiSource {
+	return &DcmiSource{
+		libPath:   libPath,
+		configDir: configDir,
+		nodeName:  os.Getenv("NODE_NAME"),
+		nodeIp:    os.Getenv("HOST_IP"),
+	}
+}
+
+// Init loads and initializes libdcmi
+func (s *DcmiSource) Init() error {
+	if err := dcmiLoad(s.libPath); err != nil {
+		return err
+	}
+	version, err := dcmiDriverVersion()
+	if err != nil {
+		log.Warningf("get npu driver version error: %v", err)
+	}
+	s.driverVersion = version
+	return nil
+}
+
+// Shutdown unloads libdcmi
+func (s *DcmiSource) Shutdown() error {
+	return dcmiUnload()
+}
+
+// VnpuCoreUtilization returns false, dcmi only reports the memory used by each process so the ai core
+// utilization of a vnpu cannot be told apart from the one of the chip, only the vnpu memory is supported
+func (s *DcmiSource) VnpuCoreUtilization() bool {
+	return false
+}
+
+// Devices queries every chip through libdcmi, a chip failing to report is skipped
+func (s *DcmiSource) Devices() (map[string]*utils.XPUDevice, error) {
+	chips, err := dcmiChips()
+	if err != nil {
+		return nil, err
+	}
+	devices := make(map[string]*utils.XPUDevice, len(chips))
+	procMem := make(map[string]map[uint32]uint64, len(chips))
+	for _, chip := range chips {
+		dev, err := s.device(chip)
+		if err != nil {
+			log.Warningf("get npu card %d device %d error: %v", chip.cardId, chip.deviceId, err)
+			continue
+		}
+		devices[dev.Id] = dev
+		if procMem[dev.Id], err = chip.processMemory(); err != nil {
+			log.Warningf("get npu %s process memory error: %v", dev.Id, err)
+		}
+	}
+	if err := setContainerVnpus(s.configDir, devices, procMem); err != nil && !os.IsNotExist(err) {
+		log.Warningf("set container vnpus error: %v", err)
+	}
+	return devices, nil
+}
+
+func (s *DcmiSource) device(chip dcmiChip) (*utils.XPUDevice, error) {
+	dev := &utils.XPUDevice{NodeName: s.nodeName, NodeIp: s.nodeIp, DriverVersion: s.driverVersion,
+		VxpuDeviceList: utils.VxpuDevices{}}
+	var err error
+	if dev.Index, err = chip.logicId(); err != nil {
+		return nil, err
+	}
+	if dev.Id, err = chip.vdieId(); err != nil {
+		return nil, err
+	}
+	if dev.Type, err = chip.name(); err != nil {
+		return nil, err
+	}
+	if dev.Health, err = chip.healthy(); err != nil {
+		return nil, err
+	}
+	utilization, err := chip.aiCoreUtilization()
+	if err != nil {
+		return nil, err
+	}
+	dev.XpuUtilization = float64(utilization)
+	if dev.MemoryTotal, dev.MemoryUsed, err = chip.hbm(); err != nil {
+		return nil, err
+	}
+	if dev.MemoryTotal != 0 {
+		dev.MemoryUtilization = float64(dev.MemoryUsed*percentage) / float64(dev.MemoryTotal)
+	}
+	if dev.Temperature, err = chip.temperature(); err != nil {
+		return nil, err
+	}
+	if dev.PowerUsage, err = chip.power(); err != nil {
+		return nil, err
+	}
+	return dev, nil
+}
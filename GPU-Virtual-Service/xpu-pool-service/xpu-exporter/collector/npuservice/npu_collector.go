@@ -6,14 +6,38 @@
 package npuservice
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"huawei.com/vxpu-device-plugin/pkg/log"
 
+	"huawei.com/xpu-exporter/collector"
 	"huawei.com/xpu-exporter/common/cache"
+	"huawei.com/xpu-exporter/common/utils"
 	"huawei.com/xpu-exporter/versions"
 )
 
+const (
+	cntrName      = "container_name"
+	npuId         = "npu_id"
+	podUid        = "pod_uuid"
+	vnpuId        = "vnpu_id"
+	vnpuCoreLimit = "vnpu_core_limit"
+	vnpuMemLimit  = "vnpu_mem_limit"
+	nodeName      = "node_name"
+	nodeIp        = "node_ip"
+	npuIndex      = "npu_index"
+	model         = "model"
+	driverVersion = "driver_version"
+)
+
+var (
+	vnpuLabel = []string{npuId, nodeName, nodeIp, podUid, cntrName, vnpuId, vnpuCoreLimit, vnpuMemLimit}
+	npuLabel  = []string{npuId, nodeName, nodeIp, npuIndex, model, driverVersion}
+	nodeLabel = []string{nodeName, nodeIp}
+)
+
 var (
 	versionInfoDesc = prometheus.NewDesc(
 		"npu_exporter_version_info",
@@ -21,16 +45,50 @@ var (
 		[]string{"exporterVersion"},
 		nil,
 	)
+	xpuNpuUtilizationDesc = prometheus.NewDesc("xpu_npu_util",
+		"the utilization rate of ai core for a single npu", npuLabel, nil)
+	xpuNpuMemoryUtilizationDesc = prometheus.NewDesc("xpu_npu_mem_util",
+		"the utilization rate of hbm for a single npu", npuLabel, nil)
+	xpuNpuStatusDesc = prometheus.NewDesc("xpu_npu_status",
+		"npu chip health status.", npuLabel, nil)
+	xpuNpuNumberDesc = prometheus.NewDesc("xpu_npu_num",
+		"number of npus", nodeLabel, nil)
+	xpuNpuMemoryDesc = prometheus.NewDesc("xpu_npu_mem_bytes",
+		"hbm size of npu, the unit is bytes", npuLabel, nil)
+	xpuNpuMemoryUsedDesc = prometheus.NewDesc("xpu_npu_mem_used_bytes",
+		"used hbm of npu, the unit is bytes", npuLabel, nil)
+	xpuNpuPowerUsageDesc = prometheus.NewDesc("xpu_npu_power_usage",
+		"power usage of npu, the unit is milliwatts", npuLabel, nil)
+	xpuNpuTemperatureDesc = prometheus.NewDesc("xpu_npu_temperature",
+		"temperature of npu, the unit is Celsius", npuLabel, nil)
+	xpuVnpuUtilizationDesc = prometheus.NewDesc("xpu_vnpu_util",
+		"the utilization rate of ai core for vnpu", vnpuLabel, nil)
+	xpuVnpuMemoryUtilizationDesc = prometheus.NewDesc("xpu_vnpu_mem_util",
+		"the utilization rate of hbm for vnpu", vnpuLabel, nil)
+	xpuVnpuMemoryUsedDesc = prometheus.NewDesc("xpu_vnpu_mem_used_bytes",
+		"used hbm of vnpu, the unit is bytes", vnpuLabel, nil)
+	xpuVnpuNumberDesc = prometheus.NewDesc("xpu_vnpu_num",
+		"real time quantity of vnpu", []string{nodeName, nodeIp, npuId}, nil)
+	xpuVnpuPodNumberDesc = prometheus.NewDesc("xpu_vnpu_pod_num",
+		"real time quantity of vnpu pods", []string{nodeName, nodeIp, npuId}, nil)
+
+	descriptions = []*prometheus.Desc{versionInfoDesc, xpuNpuUtilizationDesc, xpuNpuMemoryUtilizationDesc,
+		xpuNpuStatusDesc, xpuNpuNumberDesc, xpuNpuMemoryDesc, xpuNpuMemoryUsedDesc, xpuNpuPowerUsageDesc,
+		xpuNpuTemperatureDesc, xpuVnpuUtilizationDesc, xpuVnpuMemoryUtilizationDesc, xpuVnpuMemoryUsedDesc,
+		xpuVnpuNumberDesc, xpuVnpuPodNumberDesc}
 )
 
 const (
-	cacheSize = 128
+	cacheSize    = 128
+	noExpiration = -1
 )
 
 type npuCollector struct {
 	cache      *cache.ConcurrencyLRUCache
+	source     DeviceSource
 	updateTime time.Duration
 	cacheTime  time.Duration
+	refresher  *collector.Refresher
 }
 
 // Describe implement the prometheus.Collector
@@ -38,10 +96,104 @@ func (n *npuCollector) Describe(ch chan<- *prometheus.Desc) {
 	if ch == nil {
 		return
 	}
-	ch <- versionInfoDesc
+	for _, desc := range descriptions {
+		ch <- desc
+	}
+	n.refresher.Describe(ch)
 }
 
 // Collect implement the prometheus.Collector
 func (n *npuCollector) Collect(ch chan<- prometheus.Metric) {
+	if ch == nil {
+		log.Warningln("Invalid param in function Collect")
+		return
+	}
 	ch <- prometheus.MustNewConstMetric(versionInfoDesc, prometheus.GaugeValue, 1, []string{versions.BuildVersion}...)
+
+	npuDeviceMap := n.getNpuInfoInCache()
+	var nodeName string
+	var nodeIp string
+	for _, npuDevice := range npuDeviceMap {
+		nodeName = npuDevice.NodeName
+		nodeIp = npuDevice.NodeIp
+		updateNpuDeviceInfo(ch, npuDevice)
+		if len(npuDevice.VxpuDeviceList) > 0 {
+			updateVnpuDeviceInfo(ch, npuDevice, n.source.VnpuCoreUtilization())
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(xpuNpuNumberDesc, prometheus.GaugeValue, float64(len(npuDeviceMap)),
+		[]string{nodeName, nodeIp}...)
+	n.refresher.Collect(ch)
+}
+
+// updateCache query the source and store the npu devices in the cache, the cached devices never expire
+// so that the last ones are served while the source fails
+func (n *npuCollector) updateCache() error {
+	devices, err := n.source.Devices()
+	if err != nil {
+		return err
+	}
+	return n.cache.Set(npuInfoCacheKey, devices, noExpiration)
+}
+
+// getNpuInfoInCache returns the devices stored by the background refresh, nil before the first success
+func (n *npuCollector) getNpuInfoInCache() map[string]*utils.XPUDevice {
+	if n.cache == nil {
+		return nil
+	}
+	obj, err := n.cache.Get(npuInfoCacheKey)
+	if err != nil || obj == nil {
+		return nil
+	}
+	devices, ok := obj.(map[string]*utils.XPUDevice)
+	if !ok {
+		log.Errorln("Error npu info cache convert failed")
+		return nil
+	}
+	return devices
+}
+
+func npuLabelValues(npu *utils.XPUDevice) []string {
+	return []string{npu.Id, npu.NodeName, npu.NodeIp, strconv.Itoa(int(npu.Index)), npu.Type, npu.DriverVersion}
+}
+
+func updateNpuDeviceInfo(ch chan<- prometheus.Metric, npu *utils.XPUDevice) {
+	labels := npuLabelValues(npu)
+	ch <- prometheus.MustNewConstMetric(xpuNpuUtilizationDesc, prometheus.GaugeValue, npu.XpuUtilization, labels...)
+	ch <- prometheus.MustNewConstMetric(xpuNpuMemoryUtilizationDesc, prometheus.GaugeValue, npu.MemoryUtilization,
+		labels...)
+	var npuStatus = 0
+	if npu.Health {
+		npuStatus = 1
+	}
+	ch <- prometheus.MustNewConstMetric(xpuNpuStatusDesc, prometheus.GaugeValue, float64(npuStatus), labels...)
+	ch <- prometheus.MustNewConstMetric(xpuNpuMemoryDesc, prometheus.GaugeValue,
+		float64(npu.MemoryTotal)*bytesPerMB, labels...)
+	ch <- prometheus.MustNewConstMetric(xpuNpuMemoryUsedDesc, prometheus.GaugeValue,
+		float64(npu.MemoryUsed)*bytesPerMB, labels...)
+	ch <- prometheus.MustNewConstMetric(xpuNpuPowerUsageDesc, prometheus.GaugeValue, float64(npu.PowerUsage),
+		labels...)
+	ch <- prometheus.MustNewConstMetric(xpuNpuTemperatureDesc, prometheus.GaugeValue, float64(npu.Temperature),
+		labels...)
+	ch <- prometheus.MustNewConstMetric(xpuVnpuNumberDesc, prometheus.GaugeValue, float64(len(npu.VxpuDeviceList)),
+		[]string{npu.NodeName, npu.NodeIp, npu.Id}...)
+}
+
+func updateVnpuDeviceInfo(ch chan<- prometheus.Metric, npu *utils.XPUDevice, coreUtilization bool) {
+	vnpuPods := make(map[string]struct{})
+	for _, vnpu := range npu.VxpuDeviceList {
+		labels := []string{npu.Id, npu.NodeName, npu.NodeIp, vnpu.PodUID, vnpu.ContainerName, vnpu.Id,
+			strconv.Itoa(int(vnpu.VxpuCoreLimit)), strconv.Itoa(int(vnpu.VxpuMemoryLimit))}
+		if coreUtilization {
+			ch <- prometheus.MustNewConstMetric(xpuVnpuUtilizationDesc, prometheus.GaugeValue,
+				vnpu.VxpuCoreUtilization, labels...)
+		}
+		ch <- prometheus.MustNewConstMetric(xpuVnpuMemoryUtilizationDesc, prometheus.GaugeValue,
+			vnpu.VxpuMemoryUtilization, labels...)
+		ch <- prometheus.MustNewConstMetric(xpuVnpuMemoryUsedDesc, prometheus.GaugeValue,
+			float64(vnpu.VxpuMemoryUsed)*bytesPerMB, labels...)
+		vnpuPods[vnpu.PodUID] = struct{}{}
+	}
+	ch <- prometheus.MustNewConstMetric(xpuVnpuPodNumberDesc, prometheus.GaugeValue, float64(len(vnpuPods)),
+		[]string{npu.NodeName, npu.NodeIp, npu.Id}...)
 }
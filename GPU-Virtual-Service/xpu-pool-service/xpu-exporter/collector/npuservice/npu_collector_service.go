@@ -2,7 +2,7 @@
  * Copyright (c) Huawei Technologies Co., Ltd. 2024-2025. All rights reserved.
  */
 
-// Package npuservice implement the gpu collector service.
+// Package npuservice implement the npu collector service.
 package npuservice
 
 import (
@@ -10,24 +10,33 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"huawei.com/vxpu-device-plugin/pkg/log"
 
 	"huawei.com/xpu-exporter/collector"
 	"huawei.com/xpu-exporter/common/cache"
+	"huawei.com/xpu-exporter/common/utils"
 )
 
 const (
 	// CollectorName for npu collector
-	CollectorName = "npu"
+	CollectorName   = "npu"
+	npuInfoCacheKey = "xpu-exporter-npu-info"
 )
 
 type npuCollectorService struct {
 	serviceName string
+	source      DeviceSource
 	collector   npuCollector
 }
 
-// New create one npu collector service instance
+// New create one npu collector service instance reading the npus through libdcmi
 func New(name string) collector.ICollectorService {
-	return &npuCollectorService{serviceName: name}
+	return NewWithSource(name, NewDcmiSource(DefaultDcmiLibPath, DefaultConfigDir))
+}
+
+// NewWithSource create one npu collector service instance reading the npus from source
+func NewWithSource(name string, source DeviceSource) collector.ICollectorService {
+	return &npuCollectorService{serviceName: name, source: source}
 }
 
 // GetName obtains the service name.
@@ -39,12 +48,30 @@ func (s *npuCollectorService) GetName() string {
 func (s *npuCollectorService) CreateCollector(cacheTime time.Duration, updateTime time.Duration) prometheus.Collector {
 	s.collector = npuCollector{
 		cache:      cache.New(cacheSize),
+		source:     s.source,
 		cacheTime:  cacheTime,
 		updateTime: updateTime,
 	}
+	s.collector.refresher = collector.NewRefresher(s.serviceName, s.collector.updateCache, updateTime, cacheTime)
 	return &s.collector
 }
 
+// Devices returns the cached npu devices with the nested vnpus
+func (s *npuCollectorService) Devices() map[string]*utils.XPUDevice {
+	return s.collector.getNpuInfoInCache()
+}
+
 // Start start collect npu monitoring data
 func (s *npuCollectorService) Start(ctx context.Context, fn context.CancelFunc) {
+	if err := s.source.Init(); err != nil {
+		log.Errorf("init npu source error: %v", err)
+		return
+	}
+	defer func() {
+		if err := s.source.Shutdown(); err != nil {
+			log.Errorf("shutdown npu source error: %v", err)
+		}
+	}()
+
+	s.collector.refresher.Run(ctx)
 }
@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package npuservice implement the npu collector service.
+package npuservice
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"huawei.com/xpu-exporter/common/utils"
+)
+
+const (
+	testSourceFile = "testdata/npus.json"
+	testCacheTime  = time.Minute
+	testUpdateTime = 10 * time.Millisecond
+)
+
+// newTestCollector returns a collector whose cache is refreshed once, as the background refresher does
+func newTestCollector(t *testing.T, path string) prometheus.Collector {
+	s := NewWithSource(CollectorName, NewFileSource(path))
+	c := s.CreateCollector(testCacheTime, testUpdateTime)
+	assert.NotNil(t, c)
+	_ = c.(*npuCollector).refresher.Refresh()
+	return c
+}
+
+func TestFileSourceDevices(t *testing.T) {
+	source := NewFileSource(testSourceFile)
+	assert.NoError(t, source.Init())
+	devices, err := source.Devices()
+	assert.NoError(t, err)
+	assert.Len(t, devices, 2)
+	assert.Equal(t, "Ascend910B", devices["0000A1B2"].Type)
+	assert.Len(t, devices["0000A1B2"].VxpuDeviceList, 1)
+
+	assert.Error(t, NewFileSource("testdata/missing.json").Init())
+}
+
+func TestCollectNpuMetrics(t *testing.T) {
+	c := newTestCollector(t, testSourceFile)
+	counts := map[string]int{
+		"npu_exporter_version_info": 1,
+		"xpu_npu_util":              2,
+		"xpu_npu_mem_bytes":         2,
+		"xpu_npu_mem_used_bytes":    2,
+		"xpu_npu_status":            2,
+		"xpu_npu_num":               1,
+		"xpu_vnpu_util":             1,
+		"xpu_vnpu_mem_used_bytes":   1,
+		"xpu_vnpu_pod_num":          1,
+		"xpu_vnpu_num":              2,
+	}
+	for name, count := range counts {
+		assert.Equal(t, count, testutil.CollectAndCount(c, name), name)
+	}
+
+	expected := `
+# HELP xpu_npu_status npu chip health status.
+# TYPE xpu_npu_status gauge
+xpu_npu_status{driver_version="24.1.rc2",model="Ascend910B",node_ip="192.168.0.1",node_name="node1",npu_id="0000A1B2",npu_index="0"} 1
+xpu_npu_status{driver_version="24.1.rc2",model="Ascend910B",node_ip="192.168.0.1",node_name="node1",npu_id="0000C3D4",npu_index="1"} 0
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "xpu_npu_status"))
+
+	expected = `
+# HELP xpu_npu_mem_used_bytes used hbm of npu, the unit is bytes
+# TYPE xpu_npu_mem_used_bytes gauge
+xpu_npu_mem_used_bytes{driver_version="24.1.rc2",model="Ascend910B",node_ip="192.168.0.1",node_name="node1",npu_id="0000A1B2",npu_index="0"} 1.7179869184e+10
+xpu_npu_mem_used_bytes{driver_version="24.1.rc2",model="Ascend910B",node_ip="192.168.0.1",node_name="node1",npu_id="0000C3D4",npu_index="1"} 0
+# HELP xpu_npu_power_usage power usage of npu, the unit is milliwatts
+# TYPE xpu_npu_power_usage gauge
+xpu_npu_power_usage{driver_version="24.1.rc2",model="Ascend910B",node_ip="192.168.0.1",node_name="node1",npu_id="0000A1B2",npu_index="0"} 120000
+xpu_npu_power_usage{driver_version="24.1.rc2",model="Ascend910B",node_ip="192.168.0.1",node_name="node1",npu_id="0000C3D4",npu_index="1"} 0
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "xpu_npu_mem_used_bytes",
+		"xpu_npu_power_usage"))
+}
+
+func TestCollectBeforeRefresh(t *testing.T) {
+	s := NewWithSource(CollectorName, NewFileSource(testSourceFile))
+	c := s.CreateCollector(testCacheTime, testUpdateTime)
+	// the source is only queried by the background refresher, never in Collect
+	assert.Equal(t, 0, testutil.CollectAndCount(c, "xpu_npu_util"))
+	assert.Nil(t, s.(*npuCollectorService).Devices())
+}
+
+func TestCollectWithoutSource(t *testing.T) {
+	c := newTestCollector(t, "testdata/missing.json")
+	assert.Equal(t, 1, testutil.CollectAndCount(c, "npu_exporter_version_info"))
+	assert.Equal(t, 0, testutil.CollectAndCount(c, "xpu_npu_util"))
+}
+
+func TestStartUpdatesCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "npus.json")
+	data, err := os.ReadFile(testSourceFile)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, data, 0600))
+
+	s := NewWithSource(CollectorName, NewFileSource(path))
+	c := s.CreateCollector(testCacheTime, testUpdateTime)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx, cancel)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return testutil.CollectAndCount(c, "xpu_npu_util") == 2 },
+		time.Second, testUpdateTime)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{}`), 0600))
+	assert.Eventually(t, func() bool { return testutil.CollectAndCount(c, "xpu_npu_util") == 0 },
+		time.Second, testUpdateTime)
+	cancel()
+	<-done
+}
+
+func TestSetContainerVnpus(t *testing.T) {
+	dir := t.TempDir()
+	containerDir := filepath.Join(dir, "pod-1", "train")
+	assert.NoError(t, os.MkdirAll(containerDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(containerDir, vxpuConfigFileName),
+		[]byte("UsedMem:16384\nUsedCores:50\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(containerDir, vxpuIdsConfigFileName),
+		[]byte("0000A1B2-0\nunknown-1\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(containerDir, pidsConfigFileName),
+		[]byte("100 1\n200 2\n"), 0644))
+	// a container without processes has no pids.config yet
+	idleDir := filepath.Join(dir, "pod-2", "idle")
+	assert.NoError(t, os.MkdirAll(idleDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(idleDir, vxpuConfigFileName),
+		[]byte("UsedMem:8192\nUsedCores:25\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(idleDir, vxpuIdsConfigFileName), []byte("0000A1B2-1\n"), 0644))
+
+	chips := map[string]*utils.XPUDevice{"0000A1B2": {Id: "0000A1B2", MemoryTotal: 65536}}
+	procMem := map[string]map[uint32]uint64{"0000A1B2": {100: 4096 * bytesPerMB, 200: 4096 * bytesPerMB,
+		300: 1024 * bytesPerMB}}
+	assert.NoError(t, setContainerVnpus(dir, chips, procMem))
+
+	vnpus := chips["0000A1B2"].VxpuDeviceList
+	assert.Len(t, vnpus, 2)
+	assert.Equal(t, utils.VxpuDevice{Id: "0000A1B2-0", GpuId: "0000A1B2", PodUID: "pod-1", ContainerName: "train",
+		VxpuMemoryUsed: 8192, VxpuMemoryUtilization: 12.5, VxpuMemoryLimit: 16384, VxpuCoreLimit: 50}, vnpus[0])
+	assert.Equal(t, uint64(0), vnpus[1].VxpuMemoryUsed)
+	assert.Equal(t, "pod-2", vnpus[1].PodUID)
+}
+
+// memoryOnlySource is a file source that cannot measure the ai core utilization of the vnpus, as dcmi
+type memoryOnlySource struct {
+	*FileSource
+}
+
+func (s memoryOnlySource) VnpuCoreUtilization() bool {
+	return false
+}
+
+func TestCollectWithoutVnpuCoreUtilization(t *testing.T) {
+	s := NewWithSource(CollectorName, memoryOnlySource{NewFileSource(testSourceFile)})
+	c := s.CreateCollector(testCacheTime, testUpdateTime)
+	_ = c.(*npuCollector).refresher.Refresh()
+	assert.Equal(t, 0, testutil.CollectAndCount(c, "xpu_vnpu_util"))
+	assert.Equal(t, 1, testutil.CollectAndCount(c, "xpu_vnpu_mem_used_bytes"))
+	assert.False(t, NewDcmiSource(DefaultDcmiLibPath, DefaultConfigDir).VnpuCoreUtilization())
+}
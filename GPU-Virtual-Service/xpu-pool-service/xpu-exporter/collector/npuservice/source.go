@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package npuservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"huawei.com/xpu-exporter/common/utils"
+)
+
+// DeviceSource provides the metrics of the npu chips on the node and the vnpus running on them
+type DeviceSource interface {
+	// Init prepares the source before the first call of Devices
+	Init() error
+	// Shutdown releases the resources held by the source
+	Shutdown() error
+	// Devices returns the npu chips keyed by chip id, the vnpus of the containers are in VxpuDeviceList
+	Devices() (map[string]*utils.XPUDevice, error)
+	// VnpuCoreUtilization reports whether the source fills the ai core utilization of the vnpus,
+	// xpu_vnpu_util is not exported for the sources that cannot measure it
+	VnpuCoreUtilization() bool
+}
+
+// FileSource reads the npu chips from a json file with the same layout as the GetAllVxpuInfo response,
+// it is used in tests and on nodes without the dcmi library
+type FileSource struct {
+	path string
+}
+
+// NewFileSource create a source reading the json file at path
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: filepath.Clean(path)}
+}
+
+// Init checks that the file exists
+func (s *FileSource) Init() error {
+	_, err := os.Stat(s.path)
+	return err
+}
+
+// Shutdown does nothing for the file source
+func (s *FileSource) Shutdown() error {
+	return nil
+}
+
+// VnpuCoreUtilization returns true, the file carries the utilization of the vnpus
+func (s *FileSource) VnpuCoreUtilization() bool {
+	return true
+}
+
+// Devices decodes the file on every call so that it can be changed while the exporter runs
+func (s *FileSource) Devices() (map[string]*utils.XPUDevice, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	var devices map[string]*utils.XPUDevice
+	if err := json.Unmarshal(data, &devices); err != nil {
+		return nil, fmt.Errorf("decode %s error: %v", s.path, err)
+	}
+	return devices, nil
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package npuservice
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"huawei.com/vxpu-device-plugin/pkg/log"
+
+	"huawei.com/xpu-exporter/common/utils"
+)
+
+const (
+	// DefaultConfigDir base dir of the vxpu configs written by the device plugin
+	DefaultConfigDir      = "/etc/xpu"
+	vxpuConfigFileName    = "vgpu.config"
+	vxpuIdsConfigFileName = "vgpu-ids.config"
+	pidsConfigFileName    = "pids.config"
+	usedMemKey            = "UsedMem"
+	usedCoresKey          = "UsedCores"
+	configKeyValueCount   = 2
+	bytesPerMB            = 1024 * 1024
+	percentage            = 100
+)
+
+// vnpuConfig limits and vnpu ids of one container in the config dir
+type vnpuConfig struct {
+	usedMem   int64
+	usedCores int64
+	vnpuIds   []string
+	pids      []uint32
+}
+
+// readVnpuConfig decodes the vgpu.config, vgpu-ids.config and pids.config of a container dir
+func readVnpuConfig(dir string) (*vnpuConfig, error) {
+	conf := &vnpuConfig{}
+	err := scanLines(filepath.Join(dir, vxpuConfigFileName), func(line string) {
+		items := strings.SplitN(line, ":", configKeyValueCount)
+		if len(items) != configKeyValueCount {
+			return
+		}
+		value, err := strconv.ParseInt(strings.TrimSpace(items[1]), 10, 64)
+		if err != nil {
+			return
+		}
+		switch items[0] {
+		case usedMemKey:
+			conf.usedMem = value
+		case usedCoresKey:
+			conf.usedCores = value
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scanLines(filepath.Join(dir, vxpuIdsConfigFileName), func(line string) {
+		// each line is formatted as "<chip id>-<vid>"
+		if strings.LastIndex(line, "-") > 0 {
+			conf.vnpuIds = append(conf.vnpuIds, line)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	// pids.config is only written after the first process registers
+	err = scanLines(filepath.Join(dir, pidsConfigFileName), func(line string) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			return
+		}
+		if pid, err := strconv.ParseUint(fields[0], 10, 32); err == nil {
+			conf.pids = append(conf.pids, uint32(pid))
+		}
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return conf, nil
+}
+
+func scanLines(path string, fn func(line string)) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			fn(line)
+		}
+	}
+	return scanner.Err()
+}
+
+// setContainerVnpus walks the <pod uid>/<container name> dirs in configDir and appends a vnpu per vnpu id to
+// its chip, the memory used by a vnpu is the sum of the memory of the container processes on the chip in
+// procMem, which maps chip id to host pid to bytes
+func setContainerVnpus(configDir string, chips map[string]*utils.XPUDevice,
+	procMem map[string]map[uint32]uint64) error {
+	podDirs, err := os.ReadDir(configDir)
+	if err != nil {
+		return err
+	}
+	for _, podDir := range podDirs {
+		if !podDir.IsDir() {
+			continue
+		}
+		containerDirs, err := os.ReadDir(filepath.Join(configDir, podDir.Name()))
+		if err != nil {
+			log.Warningf("read pod config dir error: %v", err)
+			continue
+		}
+		for _, containerDir := range containerDirs {
+			if !containerDir.IsDir() {
+				continue
+			}
+			dir := filepath.Join(configDir, podDir.Name(), containerDir.Name())
+			conf, err := readVnpuConfig(dir)
+			if err != nil {
+				log.Warningf("read vnpu config error: %v, dir: %s", err, dir)
+				continue
+			}
+			appendVnpus(chips, procMem, podDir.Name(), containerDir.Name(), conf)
+		}
+	}
+	return nil
+}
+
+func appendVnpus(chips map[string]*utils.XPUDevice, procMem map[string]map[uint32]uint64,
+	podUID, containerName string, conf *vnpuConfig) {
+	for _, vnpuId := range conf.vnpuIds {
+		chipId := vnpuId[:strings.LastIndex(vnpuId, "-")]
+		chip, ok := chips[chipId]
+		if !ok {
+			continue
+		}
+		vnpu := utils.VxpuDevice{
+			Id:              vnpuId,
+			GpuId:           chipId,
+			PodUID:          podUID,
+			ContainerName:   containerName,
+			VxpuMemoryLimit: conf.usedMem,
+			VxpuCoreLimit:   conf.usedCores,
+		}
+		for _, pid := range conf.pids {
+			vnpu.VxpuMemoryUsed += procMem[chipId][pid]
+		}
+		vnpu.VxpuMemoryUsed /= bytesPerMB
+		if chip.MemoryTotal != 0 {
+			util := float64(vnpu.VxpuMemoryUsed*percentage) / float64(chip.MemoryTotal)
+			vnpu.VxpuMemoryUtilization, _ = strconv.ParseFloat(fmt.Sprintf("%.2f", util), 64)
+		}
+		chip.VxpuDeviceList = append(chip.VxpuDeviceList, vnpu)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"huawei.com/vxpu-device-plugin/pkg/log"
+)
+
+const (
+	collectorLabel = "collector"
+	minBackoff     = time.Second
+)
+
+var (
+	lastRefreshDesc = prometheus.NewDesc("xpu_exporter_cache_last_refresh_timestamp_seconds",
+		"unix time of the last successful refresh of the collector cache", []string{collectorLabel}, nil)
+	refreshDurationDesc = prometheus.NewDesc("xpu_exporter_cache_refresh_duration_seconds",
+		"duration of the last refresh of the collector cache", []string{collectorLabel}, nil)
+	refreshErrorsDesc = prometheus.NewDesc("xpu_exporter_cache_refresh_errors_total",
+		"number of the failed refreshes of the collector cache", []string{collectorLabel}, nil)
+	cacheAgeDesc = prometheus.NewDesc("xpu_exporter_cache_age_seconds",
+		"seconds since the last successful refresh of the collector cache", []string{collectorLabel}, nil)
+	cacheStaleDesc = prometheus.NewDesc("xpu_exporter_cache_stale",
+		"whether the collector serves data older than the cache time, 1 is stale", []string{collectorLabel}, nil)
+)
+
+// Refresher refreshes the cache of a collector in the background, retries the failures with an exponential
+// backoff and exports its own state as metrics. The cached data is kept after a failure and marked stale
+// once it is older than staleAfter.
+type Refresher struct {
+	name       string
+	refresh    func() error
+	interval   time.Duration
+	staleAfter time.Duration
+	now        func() time.Time
+
+	mu           sync.Mutex
+	lastSuccess  time.Time
+	lastDuration time.Duration
+	errors       uint64
+}
+
+// NewRefresher create a refresher calling refresh every interval
+func NewRefresher(name string, refresh func() error, interval, staleAfter time.Duration) *Refresher {
+	return &Refresher{name: name, refresh: refresh, interval: interval, staleAfter: staleAfter, now: time.Now}
+}
+
+// Run refreshes until ctx is done, the first refresh runs immediately
+func (r *Refresher) Run(ctx context.Context) {
+	backoff := minBackoff
+	for {
+		wait := r.interval
+		if err := r.Refresh(); err != nil {
+			log.Errorf("refresh %s cache error: %v, retry in %v", r.name, err, backoff)
+			wait = backoff
+			backoff = nextBackoff(backoff, r.interval)
+		} else {
+			backoff = minBackoff
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func nextBackoff(backoff, max time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > max {
+		return max
+	}
+	return backoff
+}
+
+// Refresh refreshes the cache once and records the result
+func (r *Refresher) Refresh() error {
+	start := r.now()
+	err := r.refresh()
+	end := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastDuration = end.Sub(start)
+	if err != nil {
+		r.errors++
+		return err
+	}
+	r.lastSuccess = end
+	return nil
+}
+
+// Stale returns whether the cache was never refreshed or its data is older than staleAfter
+func (r *Refresher) Stale() bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stale()
+}
+
+func (r *Refresher) stale() bool {
+	return r.lastSuccess.IsZero() || r.now().Sub(r.lastSuccess) > r.staleAfter
+}
+
+// Describe implements prometheus.Collector
+func (r *Refresher) Describe(ch chan<- *prometheus.Desc) {
+	if r == nil {
+		return
+	}
+	ch <- lastRefreshDesc
+	ch <- refreshDurationDesc
+	ch <- refreshErrorsDesc
+	ch <- cacheAgeDesc
+	ch <- cacheStaleDesc
+}
+
+// Collect implements prometheus.Collector, the age and timestamp are only exported after the first success
+func (r *Refresher) Collect(ch chan<- prometheus.Metric) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch <- prometheus.MustNewConstMetric(refreshDurationDesc, prometheus.GaugeValue, r.lastDuration.Seconds(), r.name)
+	ch <- prometheus.MustNewConstMetric(refreshErrorsDesc, prometheus.CounterValue, float64(r.errors), r.name)
+	var stale float64
+	if r.stale() {
+		stale = 1
+	}
+	ch <- prometheus.MustNewConstMetric(cacheStaleDesc, prometheus.GaugeValue, stale, r.name)
+	if r.lastSuccess.IsZero() {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(lastRefreshDesc, prometheus.GaugeValue,
+		float64(r.lastSuccess.UnixNano())/float64(time.Second), r.name)
+	ch <- prometheus.MustNewConstMetric(cacheAgeDesc, prometheus.GaugeValue,
+		r.now().Sub(r.lastSuccess).Seconds(), r.name)
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefresherMetrics(t *testing.T) {
+	var fail bool
+	now := time.Unix(1000, 0)
+	r := NewRefresher("gpu", func() error {
+		now = now.Add(time.Second)
+		if fail {
+			return errors.New("unavailable")
+		}
+		return nil
+	}, time.Second, time.Minute)
+	r.now = func() time.Time { return now }
+
+	assert.True(t, r.Stale())
+	assert.Equal(t, 3, testutil.CollectAndCount(r))
+
+	assert.NoError(t, r.Refresh())
+	assert.False(t, r.Stale())
+	fail = true
+	assert.Error(t, r.Refresh())
+	// the data of the last success is served until it is older than staleAfter
+	assert.False(t, r.Stale())
+	expected := `
+# HELP xpu_exporter_cache_age_seconds seconds since the last successful refresh of the collector cache
+# TYPE xpu_exporter_cache_age_seconds gauge
+xpu_exporter_cache_age_seconds{collector="gpu"} 1
+# HELP xpu_exporter_cache_last_refresh_timestamp_seconds unix time of the last successful refresh of the collector cache
+# TYPE xpu_exporter_cache_last_refresh_timestamp_seconds gauge
+xpu_exporter_cache_last_refresh_timestamp_seconds{collector="gpu"} 1001
+# HELP xpu_exporter_cache_refresh_duration_seconds duration of the last refresh of the collector cache
+# TYPE xpu_exporter_cache_refresh_duration_seconds gauge
+xpu_exporter_cache_refresh_duration_seconds{collector="gpu"} 1
+# HELP xpu_exporter_cache_refresh_errors_total number of the failed refreshes of the collector cache
+# TYPE xpu_exporter_cache_refresh_errors_total counter
+xpu_exporter_cache_refresh_errors_total{collector="gpu"} 1
+# HELP xpu_exporter_cache_stale whether the collector serves data older than the cache time, 1 is stale
+# TYPE xpu_exporter_cache_stale gauge
+xpu_exporter_cache_stale{collector="gpu"} 0
+`
+	assert.NoError(t, testutil.CollectAndCompare(r, strings.NewReader(expected)))
+
+	now = now.Add(time.Minute)
+	assert.True(t, r.Stale())
+}
+
+func TestRefresherRunRetries(t *testing.T) {
+	var calls int32
+	r := NewRefresher("npu", func() error {
+		if atomic.AddInt32(&calls, 1) < 2 {
+			return errors.New("unavailable")
+		}
+		return nil
+	}, time.Hour, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	// the failure is retried after the backoff instead of the refresh interval
+	assert.Eventually(t, func() bool { return !r.Stale() }, 3*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+	assert.Equal(t, time.Second*2, nextBackoff(time.Second, time.Hour))
+	assert.Equal(t, time.Hour, nextBackoff(time.Hour, time.Hour))
+}
@@ -40,9 +40,9 @@ func TestDelete(t *testing.T) {
 
 func TestIncreaseAndDecrease(t *testing.T) {
 	c := &lruCache{}
-	_, err := c.Increment("test", time.Minute)
+	_, err := c.increment("test", time.Minute)
 	common.AssertEquals(notInitErr, err, t)
-	_, err = c.Decrement("test", time.Minute)
+	_, err = c.decrement("test", time.Minute)
 	common.AssertEquals(notInitErr, err, t)
 
 	cache := New(1)
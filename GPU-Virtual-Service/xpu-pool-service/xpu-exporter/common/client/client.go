@@ -7,38 +7,151 @@ package client
 
 import (
 	"context"
-	"net"
+	"errors"
+	"strconv"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"huawei.com/vxpu-device-plugin/pkg/log"
+
 	"huawei.com/xpu-exporter/common/service"
 )
 
 const (
-	pidsSockPath = "/var/lib/xpu/pids.sock"
-	dialTimeout  = 5
-	megabyte     = 1024 * 1024
+	// DefaultSockPath the socket of the pids service of the device plugin
+	DefaultSockPath = "/var/lib/xpu/pids.sock"
+	// DefaultPeriod the default sample window of the sm utilization, in seconds
+	DefaultPeriod = 60
+	// DefaultCallTimeout the default deadline of one call to the pids service
+	DefaultCallTimeout = 10 * time.Second
+	// MinPeriod and MaxPeriod the sample window range accepted by the pids service
+	MinPeriod = 1
+	MaxPeriod = 86400
+
+	dialTimeout       = 5 * time.Second
+	maxReconnectDelay = 5 * time.Second
 )
 
-// GetAllVxpuInfo Obtain vgpu information through grpc interface
-func GetAllVxpuInfo() (string, error) {
-	conn, err := grpc.Dial(pidsSockPath,
-		grpc.WithInsecure(),
-		grpc.WithBlock(),
-		grpc.WithTimeout(dialTimeout*time.Second),
-		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
-			return net.DialTimeout("unix", addr, timeout)
-		}),
+// Config of the pids service client
+type Config struct {
+	// SockPath the unix socket of the pids service
+	SockPath string
+	// Period the sample window of the sm utilization, in seconds
+	Period int
+	// CallTimeout the deadline of one call, including the wait for the connection to be ready
+	CallTimeout time.Duration
+}
+
+// Client a long-lived connection to the pids service, grpc reconnects with backoff after the connection is lost,
+// so the client follows the device plugin restarting and recreating the socket.
+type Client struct {
+	conf   Config
+	mu     sync.Mutex
+	conn   *grpc.ClientConn
+	client service.PidsServiceClient
+}
+
+// New create a client, the connection is created on the first use
+func New(conf Config) *Client {
+	if conf.SockPath == "" {
+		conf.SockPath = DefaultSockPath
+	}
+	if conf.Period == 0 {
+		conf.Period = DefaultPeriod
+	}
+	if conf.CallTimeout <= 0 {
+		conf.CallTimeout = DefaultCallTimeout
+	}
+	return &Client{conf: conf}
+}
+
+// Validate check the config is valid
+func (c Config) Validate() error {
+	if c.Period < MinPeriod || c.Period > MaxPeriod {
+		return errors.New("the sample period is invalid, range[1-86400]")
+	}
+	if c.CallTimeout <= 0 {
+		return errors.New("the call timeout must be positive")
+	}
+	return nil
+}
+
+// connect returns the connection, dialing does not block and the connection is established in the background
+func (c *Client) connect() (*grpc.ClientConn, service.PidsServiceClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		return c.conn, c.client, nil
+	}
+	backoffConfig := backoff.DefaultConfig
+	backoffConfig.MaxDelay = maxReconnectDelay
+	conn, err := grpc.Dial("unix://"+c.conf.SockPath,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoffConfig, MinConnectTimeout: dialTimeout}),
 	)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
-	defer conn.Close()
+	c.conn = conn
+	c.client = service.NewPidsServiceClient(conn)
+	return c.conn, c.client, nil
+}
 
-	client := service.NewPidsServiceClient(conn)
-	getAllVgpuInfoResponse, err := client.GetAllVxpuInfo(context.Background(), &service.GetAllVxpuInfoRequest{Period: "60"})
+// GetAllVxpuInfo Obtain vgpu information through grpc interface, waiting for the connection to be ready
+// until the call deadline
+func (c *Client) GetAllVxpuInfo(ctx context.Context) (string, error) {
+	_, client, err := c.connect()
 	if err != nil {
 		return "", err
 	}
-	return getAllVgpuInfoResponse.VxpuInfos, nil
+	ctx, cancel := context.WithTimeout(ctx, c.conf.CallTimeout)
+	defer cancel()
+	resp, err := client.GetAllVxpuInfo(ctx, &service.GetAllVxpuInfoRequest{Period: strconv.Itoa(c.conf.Period)},
+		grpc.WaitForReady(true))
+	if err != nil {
+		return "", err
+	}
+	return resp.VxpuInfos, nil
+}
+
+// Watch logs the state changes of the connection and reconnects once it is idle, until ctx is done
+func (c *Client) Watch(ctx context.Context) {
+	conn, _, err := c.connect()
+	if err != nil {
+		log.Errorf("connect to pids service %s error: %v", c.conf.SockPath, err)
+		return
+	}
+	state := conn.GetState()
+	for {
+		if state == connectivity.Idle {
+			conn.Connect()
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return
+		}
+		newState := conn.GetState()
+		if newState == connectivity.TransientFailure {
+			log.Warningf("pids service connection state changed from %s to %s", state, newState)
+		} else {
+			log.Infof("pids service connection state changed from %s to %s", state, newState)
+		}
+		state = newState
+	}
+}
+
+// Close closes the connection, the client reconnects if it is used again
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	c.client = nil
+	return err
 }
@@ -7,51 +7,84 @@ package client
 
 import (
 	"context"
-	"fmt"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
-	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+
 	"huawei.com/xpu-exporter/common/service"
 )
 
-type mockClient struct {
-	retVal       error
-	resp         *service.GetPidsResponse
-	vxpuInfoResp *service.GetAllVxpuInfoResponse
+type mockPidsServer struct {
+	service.UnimplementedPidsServiceServer
+	period chan string
+}
+
+func (m *mockPidsServer) GetAllVxpuInfo(_ context.Context,
+	req *service.GetAllVxpuInfoRequest) (*service.GetAllVxpuInfoResponse, error) {
+	m.period <- req.Period
+	return &service.GetAllVxpuInfoResponse{VxpuInfos: "{}"}, nil
 }
 
-func (mc *mockClient) GetPids(ctx context.Context, req *service.GetPidsRequest, opts ...grpc.CallOption) (*service.GetPidsResponse, error) {
-	return mc.resp, mc.retVal
+func startPidsServer(t *testing.T, sockPath string, m *mockPidsServer) *grpc.Server {
+	_ = os.Remove(sockPath)
+	ls, err := net.Listen("unix", sockPath)
+	assert.NoError(t, err)
+	s := grpc.NewServer()
+	service.RegisterPidsServiceServer(s, m)
+	go func() {
+		_ = s.Serve(ls)
+	}()
+	return s
 }
 
-func (mc *mockClient) GetAllVxpuInfo(ctx context.Context, req *service.GetAllVxpuInfoRequest, opts ...grpc.CallOption) (*service.GetAllVxpuInfoResponse, error) {
-	return mc.vxpuInfoResp, mc.retVal
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{Period: DefaultPeriod, CallTimeout: time.Second}.Validate())
+	assert.Error(t, Config{Period: 0, CallTimeout: time.Second}.Validate())
+	assert.Error(t, Config{Period: MaxPeriod + 1, CallTimeout: time.Second}.Validate())
+	assert.Error(t, Config{Period: DefaultPeriod}.Validate())
 }
 
 func TestGetAllVxpuInfo(t *testing.T) {
-	patchDial := gomonkey.ApplyFunc(grpc.Dial, func(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
-		return &grpc.ClientConn{}, nil
-	})
-
-	patchNewPidsServiceClient := gomonkey.ApplyFunc(service.NewPidsServiceClient,
-		func(c grpc.ClientConnInterface) service.PidsServiceClient {
-			return &mockClient{retVal: fmt.Errorf("test error from GetAllVxpuInfo()")}
-		})
-
-	var c *grpc.ClientConn
-	patchClose := gomonkey.ApplyMethod(c, "Close", func(_ *grpc.ClientConn) error {
-		return nil
-	})
-
-	defer patchDial.Reset()
-	defer patchNewPidsServiceClient.Reset()
-	defer patchClose.Reset()
-
-	_, err := GetAllVxpuInfo()
-	if err == nil {
-		t.Error("error in test GetAllVxpuInfo")
-	} else {
-		t.Log("test GetAllVxpuInfo succeed")
-	}
+	sockPath := filepath.Join(t.TempDir(), "pids.sock")
+	m := &mockPidsServer{period: make(chan string, 1)}
+	s := startPidsServer(t, sockPath, m)
+
+	c := New(Config{SockPath: sockPath, Period: 30, CallTimeout: time.Second})
+	defer c.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Watch(ctx)
+
+	info, err := c.GetAllVxpuInfo(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", info)
+	assert.Equal(t, "30", <-m.period)
+
+	// the device plugin restarts and recreates the socket
+	s.Stop()
+	_, err = c.GetAllVxpuInfo(context.Background())
+	assert.Error(t, err)
+	s = startPidsServer(t, sockPath, m)
+	defer s.Stop()
+	assert.Eventually(t, func() bool {
+		_, err := c.GetAllVxpuInfo(context.Background())
+		if err == nil {
+			<-m.period
+		}
+		return err == nil
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestGetAllVxpuInfoTimeout(t *testing.T) {
+	c := New(Config{SockPath: filepath.Join(t.TempDir(), "missing.sock"), CallTimeout: 100 * time.Millisecond})
+	defer c.Close()
+	start := time.Now()
+	_, err := c.GetAllVxpuInfo(context.Background())
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
 }
@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package config loads the yaml configuration file of the exporter and watches it for changes
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"huawei.com/xpu-exporter/common/relabel"
+)
+
+const (
+	maxConfigSize = 1024 * 1024
+	// CollectorsKey the key of the collectors enabled or disabled at runtime
+	CollectorsKey = "collectors"
+	// MetricsKey the key of the metric filtering and relabeling rules
+	MetricsKey = "metrics"
+	// LogLevelKey the key of the log level, its command line flag is log-level
+	LogLevelKey = "logLevel"
+	// LogFormatKey the key of the log format, its command line flag is log-format
+	LogFormatKey = "logFormat"
+)
+
+// reloadableKeys the settings applied at runtime without restarting the exporter
+var reloadableKeys = map[string]bool{
+	"concurrency":    true,
+	"limitIPReq":     true,
+	"trustedProxies": true,
+	"allowCIDRs":     true,
+	"denyCIDRs":      true,
+	CollectorsKey:    true,
+	MetricsKey:       true,
+	LogLevelKey:      true,
+}
+
+// Config the settings of the configuration file, the keys are the names of the command line flags and
+// the value types are the same as the flags
+type Config struct {
+	Type            string `yaml:"type"`
+	UpdateTime      int    `yaml:"updateTime"`
+	CacheTime       int    `yaml:"cacheTime"`
+	LogDir          string `yaml:"logDir"`
+	LogLevel        string `yaml:"logLevel"`
+	LogFormat       string `yaml:"logFormat"`
+	IP              string `yaml:"ip"`
+	Port            int    `yaml:"port"`
+	Concurrency     int    `yaml:"concurrency"`
+	LimitIPConn     int    `yaml:"limitIPConn"`
+	LimitTotalConn  int    `yaml:"limitTotalConn"`
+	LimitIPReq      string `yaml:"limitIPReq"`
+	TrustedProxies  string `yaml:"trustedProxies"`
+	AllowCIDRs      string `yaml:"allowCIDRs"`
+	DenyCIDRs       string `yaml:"denyCIDRs"`
+	HTTPS           bool   `yaml:"https"`
+	TLSCertFile     string `yaml:"tlsCertFile"`
+	TLSKeyFile      string `yaml:"tlsKeyFile"`
+	TLSClientCAFile string `yaml:"tlsClientCAFile"`
+	DcmiLibPath     string `yaml:"dcmiLibPath"`
+	NpuSourceFile   string `yaml:"npuSourceFile"`
+	PidsSock        string `yaml:"pidsSock"`
+	SamplePeriod    int    `yaml:"samplePeriod"`
+	PidsTimeout     int    `yaml:"pidsTimeout"`
+	PodMetadata     bool   `yaml:"podMetadata"`
+	NodeName        string `yaml:"nodeName"`
+	PodLabels       string `yaml:"podLabels"`
+	PodAnnotations  string `yaml:"podAnnotations"`
+	OtlpEndpoint    string `yaml:"otlpEndpoint"`
+	OtlpProtocol    string `yaml:"otlpProtocol"`
+	OtlpHeaders     string `yaml:"otlpHeaders"`
+	OtlpInsecure    bool   `yaml:"otlpInsecure"`
+	OtlpCAFile      string `yaml:"otlpCAFile"`
+	OtlpInterval    int    `yaml:"otlpInterval"`
+	OtlpAttributes  string `yaml:"otlpAttributes"`
+	Cluster         string `yaml:"cluster"`
+	// Collectors enables or disables the registered collectors by name, the absent ones are enabled
+	Collectors map[string]bool `yaml:"collectors"`
+	// Metrics filters the metric families and relabels the metrics of the collectors
+	Metrics *relabel.Config `yaml:"metrics"`
+
+	// values the scalar settings present in the file, formatted as the flag values
+	values map[string]string
+}
+
+// Load reads the file, the unknown keys and the values of wrong types are rejected
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxConfigSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxConfigSize {
+		return nil, errors.New("the config file is too large")
+	}
+	return Parse(data)
+}
+
+// Parse parses the yaml content of the configuration file
+func Parse(data []byte) (*Config, error) {
+	conf := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(conf); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("parse config error: %v", err)
+	}
+	if err := conf.Metrics.Validate(); err != nil {
+		return nil, fmt.Errorf("the metrics of config is invalid: %v", err)
+	}
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse config error: %v", err)
+	}
+	conf.values = make(map[string]string, len(raw))
+	for key, value := range raw {
+		if key == CollectorsKey || key == MetricsKey || value == nil {
+			continue
+		}
+		conf.values[key] = fmt.Sprint(value)
+	}
+	return conf, nil
+}
+
+// Values returns the scalar settings present in the file keyed by the flag name,
+// the values are formatted to be set to the flags
+func (c *Config) Values() map[string]string {
+	values := make(map[string]string, len(c.values))
+	for key, value := range c.values {
+		values[key] = value
+	}
+	return values
+}
+
+// Has returns whether the key is present in the file
+func (c *Config) Has(key string) bool {
+	switch key {
+	case CollectorsKey:
+		return c.Collectors != nil
+	case MetricsKey:
+		return c.Metrics != nil
+	}
+	_, ok := c.values[key]
+	return ok
+}
+
+// RestartRequired returns the sorted keys changed between the two files which only take effect after restart
+func RestartRequired(old, new *Config) []string {
+	var keys []string
+	for key, value := range new.values {
+		if !reloadableKeys[key] && old.values[key] != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range old.values {
+		if _, ok := new.values[key]; !ok && !reloadableKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
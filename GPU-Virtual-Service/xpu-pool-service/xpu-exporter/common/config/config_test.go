@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package config loads the yaml configuration file of the exporter and watches it for changes
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"huawei.com/xpu-exporter/common/filewatch"
+)
+
+const testConfig = `
+type: gpu
+updateTime: 10
+port: 8083
+https: true
+limitIPReq: 10/1
+logLevel: debug
+logFormat: json
+collectors:
+  npu: false
+metrics:
+  disable: [xpu_vgpu_*]
+  staticLabels:
+    zone: z1
+`
+
+func TestParse(t *testing.T) {
+	conf, err := Parse([]byte(testConfig))
+	assert.NoError(t, err)
+	assert.Equal(t, "gpu", conf.Type)
+	assert.Equal(t, 10, conf.UpdateTime)
+	assert.True(t, conf.HTTPS)
+	assert.Equal(t, map[string]bool{"npu": false}, conf.Collectors)
+	assert.Equal(t, map[string]string{"type": "gpu", "updateTime": "10", "port": "8083", "https": "true",
+		"limitIPReq": "10/1", "logLevel": "debug", "logFormat": "json"}, conf.Values())
+	assert.True(t, conf.Has(CollectorsKey))
+	assert.True(t, conf.Has(MetricsKey))
+	assert.Equal(t, []string{"xpu_vgpu_*"}, conf.Metrics.Disable)
+	assert.Equal(t, map[string]string{"zone": "z1"}, conf.Metrics.StaticLabels)
+	assert.True(t, conf.Has("port"))
+	assert.False(t, conf.Has("ip"))
+
+	conf, err = Parse(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, conf.Values())
+	assert.False(t, conf.Has(CollectorsKey))
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse([]byte("unknownKey: 1"))
+	assert.Error(t, err)
+	_, err = Parse([]byte("port: abc"))
+	assert.Error(t, err)
+	_, err = Parse([]byte("collectors: [gpu]"))
+	assert.Error(t, err)
+	_, err = Parse([]byte("metrics:\n  enable: ['[']"))
+	assert.Error(t, err)
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestRestartRequired(t *testing.T) {
+	old, err := Parse([]byte(testConfig))
+	assert.NoError(t, err)
+	conf, err := Parse([]byte(`
+type: gpu
+updateTime: 5
+https: true
+limitIPReq: 20/1
+concurrency: 10
+ip: 127.0.0.1
+collectors:
+  npu: true
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ip", "logFormat", "port", "updateTime"}, RestartRequired(old, conf))
+	assert.Empty(t, RestartRequired(old, old))
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(testConfig), 0600))
+	changed := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go Watch(ctx, path, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	// a configmap update replaces the file
+	assert.Eventually(t, func() bool {
+		tmp := path + ".tmp"
+		assert.NoError(t, os.WriteFile(tmp, []byte("port: 8084"), 0600))
+		assert.NoError(t, os.Rename(tmp, path))
+		select {
+		case <-changed:
+			return true
+		case <-time.After(2 * filewatch.Delay):
+			return false
+		}
+	}, 10*time.Second, 10*time.Millisecond)
+	conf, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 8084, conf.Port)
+}
@@ -0,0 +1,20 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package config
+
+import (
+	"context"
+
+	"huawei.com/vxpu-device-plugin/pkg/log"
+
+	"huawei.com/xpu-exporter/common/filewatch"
+)
+
+// Watch calls onChange after the file changes until ctx is cancelled
+func Watch(ctx context.Context, path string, onChange func()) {
+	if err := filewatch.Watch(ctx, onChange, path); err != nil {
+		log.Errorf("watch config file %s error: %v, the config will not be reloaded", path, err)
+	}
+}
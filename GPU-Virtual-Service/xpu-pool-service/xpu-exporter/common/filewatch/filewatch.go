@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package filewatch calls back when the watched files change
+package filewatch
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"huawei.com/vxpu-device-plugin/pkg/log"
+)
+
+const (
+	// Delay merges the burst of events of one file update into one call
+	Delay = 500 * time.Millisecond
+	// atomicWriterData the symlink swapped by the kubelet when a mounted configmap or secret is updated
+	atomicWriterData = "..data"
+)
+
+// Watch calls onChange after any of the files changes until ctx is cancelled, it only returns early with
+// the error of setting up the watch.
+// The directories are watched instead of the files, because the mounted configmaps and secrets replace
+// the files by swapping symlinks, which removes the watch of the old file. The events of the other files
+// in the directories are ignored, except the swap of the kubelet's ..data symlink.
+func Watch(ctx context.Context, onChange func(), files ...string) error {
+	watched := make(map[string]map[string]bool)
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		dir := filepath.Dir(file)
+		if watched[dir] == nil {
+			watched[dir] = map[string]bool{atomicWriterData: true}
+		}
+		watched[dir][filepath.Base(file)] = true
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher error: %v", err)
+	}
+	defer watcher.Close()
+	for dir := range watched {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("watch dir %s error: %v", dir, err)
+		}
+	}
+
+	timer := time.NewTimer(Delay)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			if watched[filepath.Dir(event.Name)][filepath.Base(event.Name)] {
+				timer.Reset(Delay)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warningf("file watcher error: %v", err)
+		case <-timer.C:
+			onChange()
+		}
+	}
+}
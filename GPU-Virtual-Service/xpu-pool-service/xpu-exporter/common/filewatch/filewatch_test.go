@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package filewatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startWatch watches the files in the background and returns the number of onChange calls
+func startWatch(t *testing.T, files ...string) *int32 {
+	var calls int32
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, func() { atomic.AddInt32(&calls, 1) }, files...)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+	// let the watch be set up before the files are changed
+	time.Sleep(100 * time.Millisecond)
+	return &calls
+}
+
+func TestWatchDebounces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("a"), 0600))
+	calls := startWatch(t, path)
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, os.WriteFile(path, []byte{byte('b' + i)}, 0600))
+	}
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(calls) == 1 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(2 * Delay)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls), "the burst of writes should be merged into one call")
+}
+
+func TestWatchIgnoresOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	calls := startWatch(t, certFile, keyFile, "")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "other.log"), []byte("x"), 0600))
+	time.Sleep(2 * Delay)
+	assert.Equal(t, int32(0), atomic.LoadInt32(calls), "the other files in the directory should be ignored")
+
+	assert.NoError(t, os.WriteFile(keyFile, []byte("key"), 0600))
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(calls) == 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWatchAtomicWriterSwap(t *testing.T) {
+	// the layout of a mounted configmap, config.yaml -> ..data/config.yaml -> ..v1/config.yaml
+	dir := t.TempDir()
+	for _, version := range []string{"..v1", "..v2"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, version), 0700))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, version, "config.yaml"), []byte(version), 0600))
+	}
+	assert.NoError(t, os.Symlink("..v1", filepath.Join(dir, atomicWriterData)))
+	path := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.Symlink(filepath.Join(atomicWriterData, "config.yaml"), path))
+	calls := startWatch(t, path)
+
+	tmp := filepath.Join(dir, "..data_tmp")
+	assert.NoError(t, os.Symlink("..v2", tmp))
+	assert.NoError(t, os.Rename(tmp, filepath.Join(dir, atomicWriterData)))
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(calls) == 1 }, 5*time.Second, 10*time.Millisecond)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "..v2", string(data))
+}
+
+func TestWatchMissingDir(t *testing.T) {
+	err := Watch(context.Background(), func() {}, filepath.Join(t.TempDir(), "missing", "config.yaml"))
+	assert.Error(t, err)
+}
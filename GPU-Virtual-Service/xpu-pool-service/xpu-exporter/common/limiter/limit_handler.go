@@ -8,17 +8,10 @@ package limiter
 import (
 	"context"
 	"errors"
-	"fmt"
-	"math"
+	"net"
 	"net/http"
-	"regexp"
-	"strconv"
-	"strings"
-	"sync"
-	"syscall"
 	"time"
 
-	"huawei.com/xpu-exporter/common/cache"
 	"huawei.com/xpu-exporter/common/utils"
 )
 
@@ -33,25 +26,19 @@ const (
 	DefaultCacheSize = 1024 * 100
 	arrLen           = 2
 	IPReqLimitReg    = "^[1-9]\\d{0,2}/[1-9]\\d{0,2}$"
-	// MaxIPRequestsPerMinute defines the maximum number of requests allowed per IP per minute
-	MaxIPRequestsPerMinute = 20
 )
 
 type limitHandler struct {
-	concurrency       chan struct{}
-	httpHandler       http.Handler
-	log               bool
-	method            string
-	limitBytes        int64
-	ipExpiredTime     time.Duration
-	ipCache           *cache.ConcurrencyLRUCache
-	ipRequestCounters map[string]*ipRequestCounter
-	mu                sync.Mutex
-}
-
-type ipRequestCounter struct {
-	requests int
-	timer    *time.Timer
+	concurrency    chan struct{}
+	httpHandler    http.Handler
+	log            bool
+	method         string
+	limitBytes     int64
+	rateLimiter    *ipRateLimiter
+	trustedProxies []*net.IPNet
+	allowCIDRs     []*net.IPNet
+	denyCIDRs      []*net.IPNet
+	metrics        *Metrics
 }
 
 type HandlerConfig struct {
@@ -67,6 +54,14 @@ type HandlerConfig struct {
 	IPConCurrency string
 	// CacheSize set the local cache size
 	CacheSize int
+	// TrustedProxies the proxies whose X-Forwarded-For and X-Real-Ip headers are used to get the client IP
+	TrustedProxies []*net.IPNet
+	// AllowCIDRs only the client IPs in them are allowed when not empty
+	AllowCIDRs []*net.IPNet
+	// DenyCIDRs the client IPs in them are denied, checked before AllowCIDRs
+	DenyCIDRs []*net.IPNet
+	// Metrics counts the handled requests when not nil
+	Metrics *Metrics
 }
 
 type StatusResponseWriter struct {
@@ -80,48 +75,38 @@ func (w *StatusResponseWriter) WriteHeader(status int) {
 	w.Status = status
 }
 
-func (h *limitHandler) checkIPRequestLimit(clientIP, path string, req *http.Request) bool {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	counter, exists := h.ipRequestCounters[clientIP]
-	if !exists {
-		counter = &ipRequestCounter{
-			requests: 0,
-			timer: time.AfterFunc(time.Minute, func() {
-				h.mu.Lock()
-				delete(h.ipRequestCounters, clientIP)
-				h.mu.Unlock()
-			}),
-		}
-		h.ipRequestCounters[clientIP] = counter
+// checkIPAllowed denies the ip in the denylist, and the ip out of the allowlist when the allowlist is set
+func (h *limitHandler) checkIPAllowed(clientIP string) bool {
+	if utils.ContainsIP(h.denyCIDRs, clientIP) {
+		return false
 	}
+	return len(h.allowCIDRs) == 0 || utils.ContainsIP(h.allowCIDRs, clientIP)
+}
 
-	if counter.requests >= MaxIPRequestsPerMinute {
-		return false
+// checkIPRequestLimit takes a token from the bucket of the ip
+func (h *limitHandler) checkIPRequestLimit(clientIP string) bool {
+	if h.rateLimiter == nil || clientIP == "" {
+		return true
 	}
-	counter.requests++
-	return true
+	return h.rateLimiter.allow(clientIP, time.Now())
 }
 
 func (h *limitHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	req.Body = http.MaxBytesReader(w, req.Body, h.limitBytes)
 	ctx := initContext(req)
-	path := req.URL.Path
-	clientUserAgent := req.UserAgent()
-	clientIP := utils.ClientIP(req)
+	clientIP := utils.ClientIP(req, h.trustedProxies)
 
-	// Check if the IP has exceeded the limit of 20 requests per minute
-	if !h.checkIPRequestLimit(clientIP, path, req) {
-		http.Error(w, "429 Too Many Requests", http.StatusTooManyRequests)
+	if !h.checkIPAllowed(clientIP) {
+		h.metrics.request(resultDenied)
+		http.Error(w, "403 Forbidden", http.StatusForbidden)
 		return
 	}
 
-	if clientIP != "" && h.ipCache != nil {
-		if !h.ipCache.SetIfNotExist(fmt.Sprintf("key-%s", clientIP), "v", h.ipExpiredTime) {
-			http.Error(w, "503 too busy", http.StatusServiceUnavailable)
-			return
-		}
+	// Check if the IP has exceeded the configured request limit
+	if !h.checkIPRequestLimit(clientIP) {
+		h.metrics.request(resultRateLimited)
+		http.Error(w, "429 Too Many Requests", http.StatusTooManyRequests)
+		return
 	}
 
 	select {
@@ -135,6 +120,7 @@ func (h *limitHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			h.concurrency <- struct{}{} // recover token to the bucket
 			return
 		}
+		h.metrics.request(resultAllowed)
 		cancCtx, cancelFunc := context.WithCancel(ctx)
 		start := time.Now()
 		go returnToken(cancCtx, h.concurrency)
@@ -146,6 +132,7 @@ func (h *limitHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			h.concurrency <- struct{}{}
 		}
 	default:
+		h.metrics.request(resultBusy)
 		http.Error(w, "503 too busy", http.StatusServiceUnavailable)
 	}
 }
@@ -191,13 +178,11 @@ func returnToken(ctx context.Context, concurrency chan struct{}) {
 
 func createHandler(ch chan struct{}, handler http.Handler, printLog bool, httpMethod string, bodySizeLimit int64) *limitHandler {
 	h := &limitHandler{
-		concurrency:       ch,
-		httpHandler:       handler,
-		log:               printLog,
-		method:            httpMethod,
-		limitBytes:        bodySizeLimit,
-		ipExpiredTime:     time.Duration(-1),
-		ipRequestCounters: make(map[string]*ipRequestCounter),
+		concurrency: ch,
+		httpHandler: handler,
+		log:         printLog,
+		method:      httpMethod,
+		limitBytes:  bodySizeLimit,
 	}
 	for i := 0; i < cap(ch); i++ {
 		h.concurrency <- struct{}{}
@@ -219,29 +204,18 @@ func NewLimitHandler(handler http.Handler, conf *HandlerConfig) (http.Handler, e
 		conf.CacheSize = DefaultCacheSize
 	}
 
-	// To verify the validity of the configuration of the number of concurrent requests sent from a single IP address per second.
-	reg := regexp.MustCompile(IPReqLimitReg)
-	if !reg.Match([]byte(conf.IPConCurrency)) {
-		return nil, errors.New("IPConCurrency parameter error")
+	// To verify the validity of the configuration of the number of requests sent from a single IP address per second.
+	rateLimiter, err := newIPRateLimiter(conf.IPConCurrency, conf.CacheSize)
+	if err != nil {
+		return nil, err
 	}
 
 	conChan := make(chan struct{}, conf.TotalConCurrency)
 	h := createHandler(conChan, handler, conf.PrintLog, conf.Method, conf.LimitBytes)
-
-	arr := strings.Split(conf.IPConCurrency, "/")
-	if len(arr) != arrLen || arr[0] == "0" {
-		return nil, errors.New("IPConCurrency parameter error")
-	}
-
-	arr1, err := strconv.ParseInt(arr[1], 0, 0)
-	if err != nil {
-		return nil, fmt.Errorf("IPConCurrency parameter(%s) error, parse to int failed: %v", arr[1], err)
-	}
-	arr0, err := strconv.ParseInt(arr[0], 0, 0)
-	if err != nil || arr0 == 0 {
-		return nil, fmt.Errorf("IPConCurrency parameter(%s) error, parse to int failed: %v", arr[0], err)
-	}
-	h.ipExpiredTime = time.Duration(arr1 * int64(time.Second) / arr0)
-
+	h.rateLimiter = rateLimiter
+	h.trustedProxies = conf.TrustedProxies
+	h.allowCIDRs = conf.AllowCIDRs
+	h.denyCIDRs = conf.DenyCIDRs
+	h.metrics = conf.Metrics
 	return h, nil
 }
@@ -52,10 +52,7 @@ func TestServeHTTP(t *testing.T) {
 		return
 	})
 	defer mock.Reset()
-	ok := <-h.concurrency
-	if !ok {
-		return
-	}
+	<-h.concurrency
 	h.ServeHTTP(w.ResponseWriter, r)
 	common.AssertEquals(0, len(h.concurrency), t)
 }
@@ -80,14 +77,14 @@ func TestNewLimitHandler(t *testing.T) {
 		PrintLog:         false,
 		Method:           "",
 		LimitBytes:       DefaultDataLimit,
-		TotalConcurrency: defaultMaxConcurrency,
-		IPConcurrency:    "2/1",
+		TotalConCurrency: defaultMaxConcurrency,
+		IPConCurrency:    "2/1",
 		CacheSize:        DefaultCacheSize,
 	}
 	_, err := NewLimitHandler(http.DefaultServeMux, conf)
 	common.AssertIsNil(err, t)
 
-	conf.IPConcurrency = "2021/1"
+	conf.IPConCurrency = "2021/1"
 	_, err = NewLimitHandler(http.DefaultServeMux, conf)
 	t.Log("", err)
 	common.AssertNotNil(err, t)
@@ -102,7 +99,7 @@ func TestNewLimitHandler(t *testing.T) {
 	t.Log("", err)
 	common.AssertNotNil(err, t)
 
-	conf.TotalConcurrency = 0
+	conf.TotalConCurrency = 0
 	_, err = NewLimitHandler(http.DefaultServeMux, conf)
 	t.Log("", err)
 	common.AssertNotNil(err, t)
@@ -22,6 +22,12 @@ const (
 
 // LimitListener returns a Listener that accepts at most n connections at the same time
 func LimitListener(l net.Listener, totalConnLimit, IPConnLimit, cacheSize int) (net.Listener, error) {
+	return LimitListenerWithMetrics(l, totalConnLimit, IPConnLimit, cacheSize, nil)
+}
+
+// LimitListenerWithMetrics returns a LimitListener counting the rejected connections in m
+func LimitListenerWithMetrics(l net.Listener, totalConnLimit, IPConnLimit, cacheSize int,
+	m *Metrics) (net.Listener, error) {
 	if totalConnLimit < 0 || totalConnLimit > maxConnection {
 		return nil, errors.New("the parameter totalConnLimit is illegal")
 	}
@@ -34,6 +40,7 @@ func LimitListener(l net.Listener, totalConnLimit, IPConnLimit, cacheSize int) (
 		Listener:    l,
 		buckets:     bucket,
 		ipConnLimit: int64(IPConnLimit),
+		metrics:     m,
 	}
 	if cacheSize > 0 {
 		ll.ipCache = cache.New(cacheSize)
@@ -47,6 +54,7 @@ type localLimitListener struct {
 	closeOnce   sync.Once
 	ipCache     *cache.ConcurrencyLRUCache
 	ipConnLimit int64
+	metrics     *Metrics
 }
 
 // acquire acquires the limiting semaphore. Returns true if successfully acquired, false if the listener is closed or reach the max limit
@@ -72,6 +80,7 @@ func (l *localLimitListener) Accept() (net.Conn, error) {
 	ip, cacheKey := getIpAndKey(c)
 	if ip != "" && l.ipCache != nil {
 		if counts, err := l.ipCache.IncreaseOne(cacheKey, -1); err == nil && counts > l.ipConnLimit {
+			l.metrics.rejectConnection(reasonIPLimit)
 			return closeImmediately(c, l.ipCache), nil
 		}
 	}
@@ -80,6 +89,7 @@ func (l *localLimitListener) Accept() (net.Conn, error) {
 	if l.acquire() {
 		return &limitListenerConn{Conn: c, release: l.release, ipCache: l.ipCache}, nil
 	}
+	l.metrics.rejectConnection(reasonTotalLimit)
 	return closeImmediately(c, l.ipCache), nil
 }
 
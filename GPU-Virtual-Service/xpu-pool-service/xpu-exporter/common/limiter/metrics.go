@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package limiter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	resultAllowed     = "allowed"
+	resultRateLimited = "rate_limited"
+	resultDenied      = "denied"
+	resultBusy        = "busy"
+
+	reasonIPLimit    = "ip_limit"
+	reasonTotalLimit = "total_limit"
+)
+
+// Metrics counts the requests and connections handled by the limiter, a nil Metrics counts nothing
+type Metrics struct {
+	requests            *prometheus.CounterVec
+	rejectedConnections *prometheus.CounterVec
+}
+
+// NewMetrics creates the limiter counters and registers them to reg
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "xpu_exporter_limiter_requests_total",
+			Help: "The http requests handled by the limiter, by result allowed, rate_limited, denied or busy",
+		}, []string{"result"}),
+		rejectedConnections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "xpu_exporter_limiter_rejected_connections_total",
+			Help: "The tcp connections closed by the limiter, by reason ip_limit or total_limit",
+		}, []string{"reason"}),
+	}
+	for _, c := range []prometheus.Collector{m.requests, m.rejectedConnections} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Metrics) request(result string) {
+	if m != nil {
+		m.requests.WithLabelValues(result).Inc()
+	}
+}
+
+func (m *Metrics) rejectConnection(reason string) {
+	if m != nil {
+		m.rejectedConnections.WithLabelValues(reason).Inc()
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package limiter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"huawei.com/xpu-exporter/common/cache"
+)
+
+// tokenBucket holds up to burst tokens and is refilled at rate tokens per second
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) take(now time.Time, rate, burst float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * rate
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// ipRateLimiter limits the requests of each IP with a token bucket.
+// The buckets are kept in the LRU cache, a bucket idle for a whole period is full again and expires,
+// so the memory is bounded by the cache size even when the client addresses are many.
+type ipRateLimiter struct {
+	rate    float64
+	burst   float64
+	period  time.Duration
+	buckets *cache.ConcurrencyLRUCache
+}
+
+// newIPRateLimiter creates the limiter of the "requests/seconds" limit, e.g. "20/1" allows
+// 20 requests in 1 second for each IP with a burst of 20
+func newIPRateLimiter(limit string, cacheSize int) (*ipRateLimiter, error) {
+	reg := regexp.MustCompile(IPReqLimitReg)
+	if !reg.Match([]byte(limit)) {
+		return nil, errors.New("IPConCurrency parameter error")
+	}
+	arr := strings.Split(limit, "/")
+	if len(arr) != arrLen {
+		return nil, errors.New("IPConCurrency parameter error")
+	}
+	requests, err := strconv.ParseInt(arr[0], 0, 0)
+	if err != nil || requests == 0 {
+		return nil, fmt.Errorf("IPConCurrency parameter(%s) error, parse to int failed: %v", arr[0], err)
+	}
+	seconds, err := strconv.ParseInt(arr[1], 0, 0)
+	if err != nil || seconds == 0 {
+		return nil, fmt.Errorf("IPConCurrency parameter(%s) error, parse to int failed: %v", arr[1], err)
+	}
+	return &ipRateLimiter{
+		rate:    float64(requests) / float64(seconds),
+		burst:   float64(requests),
+		period:  time.Duration(seconds) * time.Second,
+		buckets: cache.New(cacheSize),
+	}, nil
+}
+
+func (l *ipRateLimiter) allow(ip string, now time.Time) bool {
+	key := fmt.Sprintf("key-rate-%s", ip)
+	l.buckets.SetIfNotExist(key, &tokenBucket{tokens: l.burst, last: now}, l.period)
+	value, err := l.buckets.Get(key)
+	if err != nil {
+		return true
+	}
+	bucket, ok := value.(*tokenBucket)
+	if !ok {
+		return true
+	}
+	// keep the bucket of an active ip, otherwise it would be refilled on expiry
+	_ = l.buckets.Set(key, bucket, l.period)
+	return bucket.take(now, l.rate, l.burst)
+}
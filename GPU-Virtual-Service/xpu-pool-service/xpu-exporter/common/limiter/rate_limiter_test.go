@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package limiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"huawei.com/xpu-exporter/common/utils"
+)
+
+func TestIPRateLimiter(t *testing.T) {
+	_, err := newIPRateLimiter("0/1", DefaultCacheSize)
+	assert.Error(t, err)
+	_, err = newIPRateLimiter("2", DefaultCacheSize)
+	assert.Error(t, err)
+
+	l, err := newIPRateLimiter("2/1", DefaultCacheSize)
+	assert.NoError(t, err)
+	now := time.Now()
+	assert.True(t, l.allow("10.0.0.1", now))
+	assert.True(t, l.allow("10.0.0.1", now))
+	assert.False(t, l.allow("10.0.0.1", now))
+	// the other ip has its own bucket
+	assert.True(t, l.allow("10.0.0.2", now))
+	// one token is refilled every half second
+	assert.True(t, l.allow("10.0.0.1", now.Add(500*time.Millisecond)))
+	assert.False(t, l.allow("10.0.0.1", now.Add(500*time.Millisecond)))
+	// the refill never exceeds the burst
+	assert.True(t, l.allow("10.0.0.1", now.Add(10*time.Second)))
+	assert.True(t, l.allow("10.0.0.1", now.Add(10*time.Second)))
+	assert.False(t, l.allow("10.0.0.1", now.Add(10*time.Second)))
+}
+
+func newTestHandler(t *testing.T, conf *HandlerConfig) (http.Handler, *Metrics) {
+	m, err := NewMetrics(prometheus.NewRegistry())
+	assert.NoError(t, err)
+	conf.Metrics = m
+	conf.TotalConCurrency = 1
+	conf.LimitBytes = DefaultDataLimit
+	h, err := NewLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), conf)
+	assert.NoError(t, err)
+	return h, m
+}
+
+func serve(h http.Handler, remoteAddr, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestLimitHandlerRateLimit(t *testing.T) {
+	proxies, err := utils.ParseCIDRs("10.0.0.0/8")
+	assert.NoError(t, err)
+	h, m := newTestHandler(t, &HandlerConfig{IPConCurrency: "1/60", TrustedProxies: proxies})
+
+	assert.Equal(t, http.StatusOK, serve(h, "192.168.0.1:1234", ""))
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, "192.168.0.1:1234", ""))
+	// an untrusted client can not bypass the limit with X-Forwarded-For
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, "192.168.0.1:1234", "172.16.0.1"))
+	// the clients behind a trusted proxy are limited separately
+	assert.Equal(t, http.StatusOK, serve(h, "10.0.0.1:1234", "172.16.0.1"))
+	assert.Equal(t, http.StatusOK, serve(h, "10.0.0.1:1234", "172.16.0.2"))
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, "10.0.0.1:1234", "172.16.0.2"))
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.requests.WithLabelValues(resultAllowed)))
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.requests.WithLabelValues(resultRateLimited)))
+}
+
+func TestLimitHandlerAllowDeny(t *testing.T) {
+	allow, err := utils.ParseCIDRs("192.168.0.0/16")
+	assert.NoError(t, err)
+	deny, err := utils.ParseCIDRs("192.168.1.1")
+	assert.NoError(t, err)
+	h, m := newTestHandler(t, &HandlerConfig{IPConCurrency: "100/1", AllowCIDRs: allow, DenyCIDRs: deny})
+
+	assert.Equal(t, http.StatusOK, serve(h, "192.168.0.1:1234", ""))
+	assert.Equal(t, http.StatusForbidden, serve(h, "192.168.1.1:1234", ""))
+	assert.Equal(t, http.StatusForbidden, serve(h, "10.0.0.1:1234", ""))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues(resultDenied)))
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package metadata resolves the kubernetes metadata of the pods on the node for the vxpu metrics
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"huawei.com/vxpu-device-plugin/pkg/log"
+)
+
+const (
+	// NamespaceLabel label name of the pod namespace
+	NamespaceLabel = "namespace"
+	// PodNameLabel label name of the pod name
+	PodNameLabel = "pod_name"
+
+	podLabelPrefix      = "label_"
+	podAnnotationPrefix = "annotation_"
+	uidIndex            = "uid"
+	resyncPeriod        = 10 * time.Minute
+	maxAllowlistSize    = 16
+)
+
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// Config selects the pods and the pod labels and annotations added to the metrics
+type Config struct {
+	// NodeName scopes the informer to the pods on this node
+	NodeName string
+	// Labels allowlist of the pod label keys added as label_<key>
+	Labels []string
+	// Annotations allowlist of the pod annotation keys added as annotation_<key>
+	Annotations []string
+}
+
+// Resolver resolves the pod metadata by pod uid from an informer cache of the pods on the node
+type Resolver struct {
+	conf     Config
+	informer cache.SharedIndexInformer
+	names    []string
+}
+
+// NewResolver create a resolver watching the pods on conf.NodeName
+func NewResolver(client kubernetes.Interface, conf Config) (*Resolver, error) {
+	if client == nil {
+		return nil, errors.New("kubernetes client is nil")
+	}
+	if conf.NodeName == "" {
+		return nil, errors.New("node name is empty")
+	}
+	if len(conf.Labels)+len(conf.Annotations) > maxAllowlistSize {
+		return nil, fmt.Errorf("at most %d pod labels and annotations can be added", maxAllowlistSize)
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(client, resyncPeriod,
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", conf.NodeName).String()
+		}))
+	informer := factory.Core().V1().Pods().Informer()
+	err := informer.AddIndexers(cache.Indexers{uidIndex: func(obj any) ([]string, error) {
+		pod, ok := obj.(*v1.Pod)
+		if !ok {
+			return nil, nil
+		}
+		return []string{string(pod.UID)}, nil
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{NamespaceLabel, PodNameLabel}
+	seen := map[string]bool{NamespaceLabel: true, PodNameLabel: true}
+	for _, name := range append(prefixedNames(podLabelPrefix, conf.Labels),
+		prefixedNames(podAnnotationPrefix, conf.Annotations)...) {
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate metric label %s", name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return &Resolver{conf: conf, informer: informer, names: names}, nil
+}
+
+// prefixedNames converts the keys to valid metric label names
+func prefixedNames(prefix string, keys []string) []string {
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		names = append(names, prefix+invalidLabelChars.ReplaceAllString(key, "_"))
+	}
+	return names
+}
+
+// Run runs the informer until ctx is done
+func (r *Resolver) Run(ctx context.Context) {
+	log.Infof("pod metadata resolver starting on node %s", r.conf.NodeName)
+	go r.informer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), r.informer.HasSynced) {
+		log.Warningln("pod metadata resolver stopped before the cache synced")
+		return
+	}
+	log.Infoln("pod metadata resolver cache synced")
+	<-ctx.Done()
+}
+
+// LabelNames returns the metric label names added by the resolver
+func (r *Resolver) LabelNames() []string {
+	if r == nil {
+		return nil
+	}
+	return r.names
+}
+
+// LabelValues returns the values of LabelNames for the pod, the values are empty if the pod is unknown
+func (r *Resolver) LabelValues(podUID string) []string {
+	if r == nil {
+		return nil
+	}
+	values := make([]string, len(r.names))
+	pod := r.pod(podUID)
+	if pod == nil {
+		return values
+	}
+	values[0] = pod.Namespace
+	values[1] = pod.Name
+	i := 2
+	for _, key := range r.conf.Labels {
+		values[i] = pod.Labels[key]
+		i++
+	}
+	for _, key := range r.conf.Annotations {
+		values[i] = pod.Annotations[key]
+		i++
+	}
+	return values
+}
+
+// PodName returns the namespace and name of the pod, they are empty if the pod is unknown
+func (r *Resolver) PodName(podUID string) (string, string) {
+	if r == nil {
+		return "", ""
+	}
+	pod := r.pod(podUID)
+	if pod == nil {
+		return "", ""
+	}
+	return pod.Namespace, pod.Name
+}
+
+func (r *Resolver) pod(podUID string) *v1.Pod {
+	objs, err := r.informer.GetIndexer().ByIndex(uidIndex, podUID)
+	if err != nil || len(objs) == 0 {
+		return nil
+	}
+	pod, ok := objs[0].(*v1.Pod)
+	if !ok {
+		return nil
+	}
+	return pod
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package metadata resolves the kubernetes metadata of the pods on the node for the vxpu metrics
+package metadata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newPod(uid, name, node string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:         types.UID(uid),
+			Name:        name,
+			Namespace:   "default",
+			Labels:      map[string]string{"app.kubernetes.io/name": "train", "team": "ai"},
+			Annotations: map[string]string{"owner": "alice"},
+		},
+		Spec: v1.PodSpec{NodeName: node},
+	}
+}
+
+func TestNewResolverValidation(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	_, err := NewResolver(nil, Config{NodeName: "node1"})
+	assert.Error(t, err)
+	_, err = NewResolver(client, Config{})
+	assert.Error(t, err)
+	_, err = NewResolver(client, Config{NodeName: "node1", Labels: []string{"a.b", "a_b"}})
+	assert.Error(t, err)
+	_, err = NewResolver(client, Config{NodeName: "node1", Labels: make([]string, maxAllowlistSize+1)})
+	assert.Error(t, err)
+}
+
+func TestResolverLabels(t *testing.T) {
+	client := fake.NewSimpleClientset(newPod("uid-1", "pod-1", "node1"))
+	r, err := NewResolver(client, Config{NodeName: "node1", Labels: []string{"app.kubernetes.io/name", "missing"},
+		Annotations: []string{"owner"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{NamespaceLabel, PodNameLabel, "label_app_kubernetes_io_name", "label_missing",
+		"annotation_owner"}, r.LabelNames())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+	assert.Eventually(t, r.informer.HasSynced, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []string{"default", "pod-1", "train", "", "alice"}, r.LabelValues("uid-1"))
+	assert.Equal(t, []string{"", "", "", "", ""}, r.LabelValues("unknown"))
+	namespace, name := r.PodName("uid-1")
+	assert.Equal(t, "default", namespace)
+	assert.Equal(t, "pod-1", name)
+
+	_, err = client.CoreV1().Pods("default").Create(ctx, newPod("uid-2", "pod-2", "node1"), metav1.CreateOptions{})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return r.LabelValues("uid-2")[1] == "pod-2" }, time.Second,
+		10*time.Millisecond)
+}
+
+func TestNilResolver(t *testing.T) {
+	var r *Resolver
+	assert.Nil(t, r.LabelNames())
+	assert.Nil(t, r.LabelValues("uid-1"))
+	namespace, name := r.PodName("uid-1")
+	assert.Empty(t, namespace)
+	assert.Empty(t, name)
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package otlp
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+const scopeName = "huawei.com/xpu-exporter"
+
+// toResourceMetrics converts the gathered prometheus metric families to one otlp resource metrics.
+// Counters are exported as cumulative monotonic sums starting at startTime, gauges and untyped
+// metrics as gauges, histograms and summaries keep their prometheus shape.
+func toResourceMetrics(families []*dto.MetricFamily, resource map[string]string, version string,
+	startTime, now time.Time) *metricspb.ResourceMetrics {
+	metrics := make([]*metricspb.Metric, 0, len(families))
+	for _, family := range families {
+		if m := toMetric(family, uint64(startTime.UnixNano()), now); m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	return &metricspb.ResourceMetrics{
+		Resource: &resourcepb.Resource{Attributes: toAttributes(resource)},
+		ScopeMetrics: []*metricspb.ScopeMetrics{{
+			Scope:   &commonpb.InstrumentationScope{Name: scopeName, Version: version},
+			Metrics: metrics,
+		}},
+	}
+}
+
+func toMetric(family *dto.MetricFamily, startNano uint64, now time.Time) *metricspb.Metric {
+	m := &metricspb.Metric{Name: family.GetName(), Description: family.GetHelp()}
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		points := make([]*metricspb.NumberDataPoint, 0, len(family.GetMetric()))
+		for _, metric := range family.GetMetric() {
+			points = append(points, numberPoint(metric, metric.GetCounter().GetValue(), startNano, now))
+		}
+		m.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			DataPoints:             points,
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+		}}
+	case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+		points := make([]*metricspb.NumberDataPoint, 0, len(family.GetMetric()))
+		for _, metric := range family.GetMetric() {
+			value := metric.GetGauge().GetValue()
+			if family.GetType() == dto.MetricType_UNTYPED {
+				value = metric.GetUntyped().GetValue()
+			}
+			points = append(points, numberPoint(metric, value, 0, now))
+		}
+		m.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: points}}
+	case dto.MetricType_HISTOGRAM:
+		points := make([]*metricspb.HistogramDataPoint, 0, len(family.GetMetric()))
+		for _, metric := range family.GetMetric() {
+			points = append(points, histogramPoint(metric, startNano, now))
+		}
+		m.Data = &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+			DataPoints:             points,
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		}}
+	case dto.MetricType_SUMMARY:
+		points := make([]*metricspb.SummaryDataPoint, 0, len(family.GetMetric()))
+		for _, metric := range family.GetMetric() {
+			points = append(points, summaryPoint(metric, startNano, now))
+		}
+		m.Data = &metricspb.Metric_Summary{Summary: &metricspb.Summary{DataPoints: points}}
+	default:
+		return nil
+	}
+	return m
+}
+
+func numberPoint(metric *dto.Metric, value float64, startNano uint64, now time.Time) *metricspb.NumberDataPoint {
+	return &metricspb.NumberDataPoint{
+		Attributes:        labelAttributes(metric.GetLabel()),
+		StartTimeUnixNano: startNano,
+		TimeUnixNano:      timestamp(metric, now),
+		Value:             &metricspb.NumberDataPoint_AsDouble{AsDouble: value},
+	}
+}
+
+// histogramPoint converts the cumulative prometheus buckets to the per bucket counts of otlp,
+// the +Inf bucket of prometheus is the implicit last bucket of otlp.
+func histogramPoint(metric *dto.Metric, startNano uint64, now time.Time) *metricspb.HistogramDataPoint {
+	h := metric.GetHistogram()
+	sum := h.GetSampleSum()
+	point := &metricspb.HistogramDataPoint{
+		Attributes:        labelAttributes(metric.GetLabel()),
+		StartTimeUnixNano: startNano,
+		TimeUnixNano:      timestamp(metric, now),
+		Count:             h.GetSampleCount(),
+		Sum:               &sum,
+	}
+	var cumulative uint64
+	for _, bucket := range h.GetBucket() {
+		if math.IsInf(bucket.GetUpperBound(), 1) {
+			continue
+		}
+		point.ExplicitBounds = append(point.ExplicitBounds, bucket.GetUpperBound())
+		point.BucketCounts = append(point.BucketCounts, bucket.GetCumulativeCount()-cumulative)
+		cumulative = bucket.GetCumulativeCount()
+	}
+	point.BucketCounts = append(point.BucketCounts, h.GetSampleCount()-cumulative)
+	return point
+}
+
+func summaryPoint(metric *dto.Metric, startNano uint64, now time.Time) *metricspb.SummaryDataPoint {
+	s := metric.GetSummary()
+	point := &metricspb.SummaryDataPoint{
+		Attributes:        labelAttributes(metric.GetLabel()),
+		StartTimeUnixNano: startNano,
+		TimeUnixNano:      timestamp(metric, now),
+		Count:             s.GetSampleCount(),
+		Sum:               s.GetSampleSum(),
+	}
+	for _, q := range s.GetQuantile() {
+		point.QuantileValues = append(point.QuantileValues, &metricspb.SummaryDataPoint_ValueAtQuantile{
+			Quantile: q.GetQuantile(),
+			Value:    q.GetValue(),
+		})
+	}
+	return point
+}
+
+func timestamp(metric *dto.Metric, now time.Time) uint64 {
+	if metric.TimestampMs != nil {
+		return uint64(time.UnixMilli(metric.GetTimestampMs()).UnixNano())
+	}
+	return uint64(now.UnixNano())
+}
+
+func labelAttributes(labels []*dto.LabelPair) []*commonpb.KeyValue {
+	attrs := make([]*commonpb.KeyValue, 0, len(labels))
+	for _, label := range labels {
+		attrs = append(attrs, stringAttribute(label.GetName(), label.GetValue()))
+	}
+	return attrs
+}
+
+// toAttributes converts the map to attributes sorted by key so that the output is stable
+func toAttributes(values map[string]string) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := make([]*commonpb.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, stringAttribute(key, values[key]))
+	}
+	return attrs
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
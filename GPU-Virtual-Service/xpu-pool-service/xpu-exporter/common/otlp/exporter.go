@@ -0,0 +1,187 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package otlp pushes the xpu metrics gathered from the prometheus registry to an OpenTelemetry receiver
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+
+	"huawei.com/vxpu-device-plugin/pkg/log"
+)
+
+const (
+	// ProtocolGRPC exports with OTLP/gRPC
+	ProtocolGRPC = "grpc"
+	// ProtocolHTTP exports with OTLP/HTTP in binary protobuf
+	ProtocolHTTP = "http"
+
+	serviceName        = "xpu-exporter"
+	attrServiceName    = "service.name"
+	attrServiceVersion = "service.version"
+	attrNodeName       = "k8s.node.name"
+	attrNodeIP         = "host.ip"
+	attrCluster        = "k8s.cluster.name"
+
+	minInterval    = time.Second
+	defaultTimeout = 10 * time.Second
+)
+
+// Config configures the receiver and the resource of the exported metrics
+type Config struct {
+	// Endpoint host:port of the receiver, the http protocol also accepts a full url
+	Endpoint string
+	// Protocol grpc or http
+	Protocol string
+	// Headers sent with every export request, e.g. the authorization of the receiver
+	Headers map[string]string
+	// Insecure disables TLS
+	Insecure bool
+	// CAFile verifies the receiver certificate instead of the system roots when set
+	CAFile string
+	// Interval between two exports
+	Interval time.Duration
+	// Timeout of one export request, defaults to 10s
+	Timeout time.Duration
+	// NodeName, NodeIP and Cluster are set as the k8s.node.name, host.ip and k8s.cluster.name resource attributes
+	NodeName string
+	NodeIP   string
+	Cluster  string
+	// Attributes extra resource attributes, they override the attributes above
+	Attributes map[string]string
+	// Version service.version resource attribute and the scope version
+	Version string
+}
+
+// Exporter periodically gathers the registry and pushes the metrics to the receiver
+type Exporter struct {
+	conf      Config
+	gatherer  prometheus.Gatherer
+	sender    sender
+	resource  map[string]string
+	startTime time.Time
+}
+
+// NewExporter create an exporter pushing the metrics of gatherer according to conf
+func NewExporter(gatherer prometheus.Gatherer, conf Config) (*Exporter, error) {
+	if gatherer == nil {
+		return nil, errors.New("gatherer is nil")
+	}
+	if conf.Endpoint == "" {
+		return nil, errors.New("otlp endpoint is empty")
+	}
+	if conf.Interval < minInterval {
+		return nil, fmt.Errorf("otlp interval %v is less than %v", conf.Interval, minInterval)
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = defaultTimeout
+	}
+	var s sender
+	var err error
+	switch conf.Protocol {
+	case ProtocolGRPC:
+		s, err = newGRPCSender(conf)
+	case ProtocolHTTP:
+		s, err = newHTTPSender(conf)
+	default:
+		return nil, fmt.Errorf("otlp protocol %q is invalid, range[%s,%s]", conf.Protocol, ProtocolGRPC, ProtocolHTTP)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Exporter{
+		conf:      conf,
+		gatherer:  gatherer,
+		sender:    s,
+		resource:  newResource(conf),
+		startTime: time.Now(),
+	}, nil
+}
+
+func newResource(conf Config) map[string]string {
+	resource := map[string]string{attrServiceName: serviceName}
+	optional := map[string]string{
+		attrServiceVersion: conf.Version,
+		attrNodeName:       conf.NodeName,
+		attrNodeIP:         conf.NodeIP,
+		attrCluster:        conf.Cluster,
+	}
+	for key, value := range optional {
+		if value != "" {
+			resource[key] = value
+		}
+	}
+	for key, value := range conf.Attributes {
+		resource[key] = value
+	}
+	return resource
+}
+
+// Run exports every interval until ctx is cancelled, a failed export is logged and retried at the next interval
+func (e *Exporter) Run(ctx context.Context) {
+	defer func() {
+		if err := e.sender.close(); err != nil {
+			log.Warningf("close otlp exporter error: %v", err)
+		}
+	}()
+	log.Infof("otlp exporter pushing to %s over %s every %v", e.conf.Endpoint, e.conf.Protocol, e.conf.Interval)
+	ticker := time.NewTicker(e.conf.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Export(ctx); err != nil {
+				log.Warningf("otlp export error: %v", err)
+			}
+		}
+	}
+}
+
+// Export gathers the registry once and pushes the metrics to the receiver
+func (e *Exporter) Export(ctx context.Context) error {
+	families, err := e.gatherer.Gather()
+	if err != nil {
+		// the registry returns the metrics gathered successfully together with the error
+		log.Warningf("gather metrics for otlp error: %v", err)
+	}
+	if len(families) == 0 {
+		return err
+	}
+	req := &collectorpb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{
+			toResourceMetrics(families, e.resource, e.conf.Version, e.startTime, time.Now()),
+		},
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, e.conf.Timeout)
+	defer cancel()
+	return e.sender.send(sendCtx, req)
+}
+
+// ParseKeyValues parses a comma separated key=value list such as the otlp headers or resource attributes
+func ParseKeyValues(list string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%q is not in the key=value format", item)
+		}
+		values[key] = strings.TrimSpace(value)
+	}
+	return values, nil
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package otlp pushes the xpu metrics gathered from the prometheus registry to an OpenTelemetry receiver
+package otlp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const testInterval = time.Second
+
+func newTestRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	util := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "xpu_gpu_util", Help: "gpu util"},
+		[]string{"gpu_index"})
+	util.WithLabelValues("0").Set(42)
+	exceeded := prometheus.NewCounter(prometheus.CounterOpts{Name: "xpu_limit_exceeded_total", Help: "exceeded"})
+	exceeded.Add(3)
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "xpu_collect_seconds", Help: "collect", Buckets: []float64{0.1, 1},
+	})
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(5)
+	reg.MustRegister(util, exceeded, latency)
+	return reg
+}
+
+func findMetric(rm *metricspb.ResourceMetrics, name string) *metricspb.Metric {
+	for _, m := range rm.GetScopeMetrics()[0].GetMetrics() {
+		if m.GetName() == name {
+			return m
+		}
+	}
+	return nil
+}
+
+func resourceAttr(rm *metricspb.ResourceMetrics, key string) string {
+	for _, kv := range rm.GetResource().GetAttributes() {
+		if kv.GetKey() == key {
+			return kv.GetValue().GetStringValue()
+		}
+	}
+	return ""
+}
+
+func assertExported(t *testing.T, req *collectorpb.ExportMetricsServiceRequest) {
+	if !assert.Len(t, req.GetResourceMetrics(), 1) {
+		return
+	}
+	rm := req.GetResourceMetrics()[0]
+	assert.Equal(t, "node1", resourceAttr(rm, attrNodeName))
+	assert.Equal(t, "192.168.0.1", resourceAttr(rm, attrNodeIP))
+	assert.Equal(t, "prod", resourceAttr(rm, attrCluster))
+	assert.Equal(t, serviceName, resourceAttr(rm, attrServiceName))
+	assert.Equal(t, "east", resourceAttr(rm, "region"))
+
+	util := findMetric(rm, "xpu_gpu_util")
+	if assert.NotNil(t, util) && assert.NotNil(t, util.GetGauge()) {
+		point := util.GetGauge().GetDataPoints()[0]
+		assert.Equal(t, float64(42), point.GetAsDouble())
+		assert.Equal(t, "gpu_index", point.GetAttributes()[0].GetKey())
+		assert.Equal(t, "0", point.GetAttributes()[0].GetValue().GetStringValue())
+	}
+	exceeded := findMetric(rm, "xpu_limit_exceeded_total")
+	if assert.NotNil(t, exceeded) && assert.NotNil(t, exceeded.GetSum()) {
+		assert.True(t, exceeded.GetSum().GetIsMonotonic())
+		assert.Equal(t, float64(3), exceeded.GetSum().GetDataPoints()[0].GetAsDouble())
+	}
+	latency := findMetric(rm, "xpu_collect_seconds")
+	if assert.NotNil(t, latency) && assert.NotNil(t, latency.GetHistogram()) {
+		point := latency.GetHistogram().GetDataPoints()[0]
+		assert.Equal(t, []float64{0.1, 1}, point.GetExplicitBounds())
+		assert.Equal(t, []uint64{1, 1, 1}, point.GetBucketCounts())
+		assert.Equal(t, uint64(3), point.GetCount())
+	}
+}
+
+func testConfig(endpoint, protocol string) Config {
+	return Config{
+		Endpoint:   endpoint,
+		Protocol:   protocol,
+		Headers:    map[string]string{"authorization": "Bearer token"},
+		Insecure:   true,
+		Interval:   testInterval,
+		NodeName:   "node1",
+		NodeIP:     "192.168.0.1",
+		Cluster:    "prod",
+		Attributes: map[string]string{"region": "east"},
+	}
+}
+
+type receiverStub struct {
+	collectorpb.UnimplementedMetricsServiceServer
+	requests chan *collectorpb.ExportMetricsServiceRequest
+	auth     chan string
+}
+
+func (r *receiverStub) Export(ctx context.Context,
+	req *collectorpb.ExportMetricsServiceRequest) (*collectorpb.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	r.auth <- md.Get("authorization")[0]
+	r.requests <- req
+	return &collectorpb.ExportMetricsServiceResponse{}, nil
+}
+
+func TestExportGRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	stub := &receiverStub{
+		requests: make(chan *collectorpb.ExportMetricsServiceRequest, 1),
+		auth:     make(chan string, 1),
+	}
+	server := grpc.NewServer()
+	collectorpb.RegisterMetricsServiceServer(server, stub)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	exporter, err := NewExporter(newTestRegistry(), testConfig(lis.Addr().String(), ProtocolGRPC))
+	assert.NoError(t, err)
+	defer exporter.sender.close()
+	assert.NoError(t, exporter.Export(context.Background()))
+	assert.Equal(t, "Bearer token", <-stub.auth)
+	assertExported(t, <-stub.requests)
+}
+
+func TestExportHTTP(t *testing.T) {
+	requests := make(chan *collectorpb.ExportMetricsServiceRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, defaultHTTPPath, r.URL.Path)
+		assert.Equal(t, protobufType, r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("authorization"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		req := &collectorpb.ExportMetricsServiceRequest{}
+		assert.NoError(t, proto.Unmarshal(body, req))
+		requests <- req
+	}))
+	defer server.Close()
+
+	exporter, err := NewExporter(newTestRegistry(), testConfig(server.Listener.Addr().String(), ProtocolHTTP))
+	assert.NoError(t, err)
+	assert.NoError(t, exporter.Export(context.Background()))
+	assertExported(t, <-requests)
+}
+
+func TestExportHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	exporter, err := NewExporter(newTestRegistry(), testConfig(server.URL, ProtocolHTTP))
+	assert.NoError(t, err)
+	err = exporter.Export(context.Background())
+	assert.ErrorContains(t, err, "quota exceeded")
+}
+
+func TestNewExporterValidation(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := NewExporter(nil, testConfig("127.0.0.1:4317", ProtocolGRPC))
+	assert.Error(t, err)
+	_, err = NewExporter(reg, testConfig("", ProtocolGRPC))
+	assert.Error(t, err)
+	_, err = NewExporter(reg, testConfig("127.0.0.1:4317", "udp"))
+	assert.Error(t, err)
+	conf := testConfig("127.0.0.1:4317", ProtocolGRPC)
+	conf.Interval = 0
+	_, err = NewExporter(reg, conf)
+	assert.Error(t, err)
+}
+
+func TestHTTPURL(t *testing.T) {
+	u, err := httpURL("collector:4318", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://collector:4318/v1/metrics", u)
+	u, err = httpURL("collector:4318", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://collector:4318/v1/metrics", u)
+	u, err = httpURL("https://collector/otlp/v1/metrics", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://collector/otlp/v1/metrics", u)
+	_, err = httpURL("ftp://collector", true)
+	assert.Error(t, err)
+}
+
+func TestParseKeyValues(t *testing.T) {
+	values, err := ParseKeyValues(" a=1, b = x=y ,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "x=y"}, values)
+	_, err = ParseKeyValues("a")
+	assert.Error(t, err)
+	_, err = ParseKeyValues("=1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultHTTPPath   = "/v1/metrics"
+	protobufType      = "application/x-protobuf"
+	maxErrorBodyBytes = 1024
+)
+
+// sender sends one export request to the otlp receiver
+type sender interface {
+	send(ctx context.Context, req *collectorpb.ExportMetricsServiceRequest) error
+	close() error
+}
+
+func newTLSConfig(caFile string) (*tls.Config, error) {
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return conf, nil
+	}
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read otlp ca file error: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificate found in otlp ca file %s", caFile)
+	}
+	conf.RootCAs = pool
+	return conf, nil
+}
+
+type grpcSender struct {
+	conn    *grpc.ClientConn
+	client  collectorpb.MetricsServiceClient
+	headers metadata.MD
+}
+
+// newGRPCSender dials the receiver lazily, grpc reconnects by itself when the receiver restarts
+func newGRPCSender(conf Config) (*grpcSender, error) {
+	creds := insecure.NewCredentials()
+	if !conf.Insecure {
+		tlsConf, err := newTLSConfig(conf.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConf)
+	}
+	conn, err := grpc.Dial(conf.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("dial otlp receiver %s error: %v", conf.Endpoint, err)
+	}
+	return &grpcSender{
+		conn:    conn,
+		client:  collectorpb.NewMetricsServiceClient(conn),
+		headers: metadata.New(conf.Headers),
+	}, nil
+}
+
+func (s *grpcSender) send(ctx context.Context, req *collectorpb.ExportMetricsServiceRequest) error {
+	if len(s.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, s.headers)
+	}
+	resp, err := s.client.Export(ctx, req)
+	if err != nil {
+		return err
+	}
+	if rejected := resp.GetPartialSuccess().GetRejectedDataPoints(); rejected > 0 {
+		return fmt.Errorf("otlp receiver rejected %d data points: %s",
+			rejected, resp.GetPartialSuccess().GetErrorMessage())
+	}
+	return nil
+}
+
+func (s *grpcSender) close() error {
+	return s.conn.Close()
+}
+
+type httpSender struct {
+	url     string
+	client  *http.Client
+	headers map[string]string
+}
+
+func newHTTPSender(conf Config) (*httpSender, error) {
+	endpoint, err := httpURL(conf.Endpoint, conf.Insecure)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if strings.HasPrefix(endpoint, "https://") {
+		if transport.TLSClientConfig, err = newTLSConfig(conf.CAFile); err != nil {
+			return nil, err
+		}
+	}
+	return &httpSender{
+		url:     endpoint,
+		client:  &http.Client{Transport: transport},
+		headers: conf.Headers,
+	}, nil
+}
+
+// httpURL completes the endpoint to a url, the scheme follows insecure and the path defaults to /v1/metrics
+func httpURL(endpoint string, insecure bool) (string, error) {
+	if !strings.Contains(endpoint, "://") {
+		scheme := "https://"
+		if insecure {
+			scheme = "http://"
+		}
+		endpoint = scheme + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid otlp http endpoint %s: %v", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid otlp http endpoint scheme %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", errors.New("otlp http endpoint host is empty")
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = defaultHTTPPath
+	}
+	return u.String(), nil
+}
+
+func (s *httpSender) send(ctx context.Context, req *collectorpb.ExportMetricsServiceRequest) error {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", protobufType)
+	for key, value := range s.headers {
+		httpReq.Header.Set(key, value)
+	}
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return fmt.Errorf("otlp receiver returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (s *httpSender) close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
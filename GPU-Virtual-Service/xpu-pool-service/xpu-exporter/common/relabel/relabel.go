@@ -0,0 +1,279 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package relabel filters the metric families and rewrites the labels of the gathered metrics.
+//
+// The rules are applied to the families returned by the registry rather than in a wrapping collector:
+// the collectors keep describing and sending the metrics with their own descriptors, so the registry still
+// checks them and a rule reloaded at runtime never has to re-register a collector. The metrics merged by the
+// rules are dropped after gathering, the first one of the same labels is kept.
+package relabel
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"huawei.com/vxpu-device-plugin/pkg/log"
+)
+
+var labelNameReg = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+// Config the filtering and relabeling rules, the families are metric names or path.Match patterns
+type Config struct {
+	// Enable only the matched families are exported when not empty
+	Enable []string `yaml:"enable"`
+	// Disable the matched families are not exported, checked after Enable
+	Disable []string `yaml:"disable"`
+	// Labels the label rules applied in order
+	Labels []LabelRule `yaml:"labels"`
+	// StaticLabels added to all the metrics which do not have the label
+	StaticLabels map[string]string `yaml:"staticLabels"`
+}
+
+// LabelRule drops and renames the labels of the matched families
+type LabelRule struct {
+	// Families the rule applies to, all families when empty
+	Families []string `yaml:"families"`
+	// Drop the labels removed from the metrics
+	Drop []string `yaml:"drop"`
+	// Rename the labels renamed from the key to the value, a label is not renamed onto one which the
+	// metric keeps after the rule, the renamed label is dropped instead
+	Rename map[string]string `yaml:"rename"`
+}
+
+// Validate check the patterns and the label names are valid
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if err := validatePatterns(c.Enable); err != nil {
+		return fmt.Errorf("enable is invalid: %v", err)
+	}
+	if err := validatePatterns(c.Disable); err != nil {
+		return fmt.Errorf("disable is invalid: %v", err)
+	}
+	for i, rule := range c.Labels {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("labels[%d] is invalid: %v", i, err)
+		}
+	}
+	for name := range c.StaticLabels {
+		if err := validateLabelName(name); err != nil {
+			return fmt.Errorf("staticLabels is invalid: %v", err)
+		}
+	}
+	return nil
+}
+
+func (r *LabelRule) validate() error {
+	if err := validatePatterns(r.Families); err != nil {
+		return err
+	}
+	dropped := make(map[string]bool, len(r.Drop))
+	for _, name := range r.Drop {
+		if err := validateLabelName(name); err != nil {
+			return err
+		}
+		dropped[name] = true
+	}
+	targets := make(map[string]bool, len(r.Rename))
+	for from, to := range r.Rename {
+		if err := validateLabelName(from); err != nil {
+			return err
+		}
+		if err := validateLabelName(to); err != nil {
+			return err
+		}
+		if dropped[from] {
+			return fmt.Errorf("label %s is both dropped and renamed", from)
+		}
+		if targets[to] {
+			return fmt.Errorf("more than one label is renamed to %s", to)
+		}
+		targets[to] = true
+	}
+	return nil
+}
+
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return errors.New("empty family")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("family pattern %s: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+func validateLabelName(name string) error {
+	if !labelNameReg.MatchString(name) || strings.HasPrefix(name, "__") {
+		return fmt.Errorf("label name %q is invalid", name)
+	}
+	return nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) empty() bool {
+	return len(c.Enable) == 0 && len(c.Disable) == 0 && len(c.Labels) == 0 && len(c.StaticLabels) == 0
+}
+
+func (c *Config) familyEnabled(name string) bool {
+	if len(c.Enable) > 0 && !matchAny(c.Enable, name) {
+		return false
+	}
+	return !matchAny(c.Disable, name)
+}
+
+// relabel returns the labels after the rules and whether they are changed
+func (c *Config) relabel(family string, labels []*dto.LabelPair) ([]*dto.LabelPair, bool) {
+	changed := false
+	for _, rule := range c.Labels {
+		if len(rule.Families) > 0 && !matchAny(rule.Families, family) {
+			continue
+		}
+		result := make([]*dto.LabelPair, 0, len(labels))
+		var renamed []*dto.LabelPair
+		for _, label := range labels {
+			name := label.GetName()
+			if slices.Contains(rule.Drop, name) {
+				changed = true
+				continue
+			}
+			if to, ok := rule.Rename[name]; ok {
+				changed = true
+				renamed = append(renamed, &dto.LabelPair{Name: &to, Value: label.Value})
+				continue
+			}
+			result = append(result, label)
+		}
+		for _, label := range renamed {
+			// a duplicated label name makes the metric invalid, the label kept by the rule wins
+			if hasLabel(result, label.GetName()) {
+				log.Debugf("label %s of metric %s already exists, the renamed one is dropped", label.GetName(),
+					family)
+				continue
+			}
+			result = append(result, label)
+		}
+		labels = result
+	}
+	for name, value := range c.StaticLabels {
+		if hasLabel(labels, name) {
+			continue
+		}
+		name, value := name, value
+		labels = append(labels, &dto.LabelPair{Name: &name, Value: &value})
+		changed = true
+	}
+	return labels, changed
+}
+
+func hasLabel(labels []*dto.LabelPair, name string) bool {
+	for _, label := range labels {
+		if label.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Relabeler applies the latest rules to the gathered metric families, the rules can be updated at runtime
+type Relabeler struct {
+	conf atomic.Pointer[Config]
+}
+
+// NewRelabeler create a relabeler with the valid conf, nil conf keeps the metrics unchanged
+func NewRelabeler(conf *Config) (*Relabeler, error) {
+	r := &Relabeler{}
+	if err := r.Update(conf); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Update replaces the rules when conf is valid, the next gather uses the new rules
+func (r *Relabeler) Update(conf *Config) error {
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+	if conf == nil {
+		conf = &Config{}
+	}
+	r.conf.Store(conf)
+	return nil
+}
+
+// Gatherer returns a gatherer applying the rules to the metric families of g.
+// The rules are applied after gathering so that the collectors registered in g stay checked.
+func (r *Relabeler) Gatherer(g prometheus.Gatherer) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := g.Gather()
+		conf := r.conf.Load()
+		if conf == nil || conf.empty() {
+			return families, err
+		}
+		return conf.apply(families), err
+	})
+}
+
+// apply returns the enabled families with the relabeled metrics
+func (c *Config) apply(families []*dto.MetricFamily) []*dto.MetricFamily {
+	result := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		if !c.familyEnabled(family.GetName()) {
+			continue
+		}
+		metrics := make([]*dto.Metric, 0, len(family.Metric))
+		// dropping labels may merge series, the first one is kept
+		seen := make(map[string]bool, len(family.Metric))
+		for _, m := range family.Metric {
+			labels, changed := c.relabel(family.GetName(), m.GetLabel())
+			if changed {
+				sort.Slice(labels, func(i, j int) bool { return labels[i].GetName() < labels[j].GetName() })
+				m = &dto.Metric{Label: labels, Gauge: m.Gauge, Counter: m.Counter, Summary: m.Summary,
+					Untyped: m.Untyped, Histogram: m.Histogram, TimestampMs: m.TimestampMs}
+			}
+			key := labelsKey(m.GetLabel())
+			if seen[key] {
+				log.Debugf("metric %s{%s} duplicated after relabeling, dropped", family.GetName(), key)
+				continue
+			}
+			seen[key] = true
+			metrics = append(metrics, m)
+		}
+		sort.SliceStable(metrics, func(i, j int) bool {
+			return labelsKey(metrics[i].GetLabel()) < labelsKey(metrics[j].GetLabel())
+		})
+		result = append(result, &dto.MetricFamily{Name: family.Name, Help: family.Help, Type: family.Type,
+			Metric: metrics})
+	}
+	return result
+}
+
+func labelsKey(labels []*dto.LabelPair) string {
+	pairs := make([]string, 0, len(labels))
+	for _, label := range labels {
+		pairs = append(pairs, label.GetName()+"="+strconv.Quote(label.GetValue()))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package relabel filters the metric families and rewrites the labels of the gathered metrics
+package relabel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	memDesc = prometheus.NewDesc("xpu_memory_usage", "the memory usage",
+		[]string{"id", "uuid", "pod"}, nil)
+	utilDesc = prometheus.NewDesc("xpu_utilization", "the utilization",
+		[]string{"id", "uuid"}, nil)
+	latencyDesc = prometheus.NewDesc("xpu_latency_seconds", "the latency",
+		[]string{"id"}, nil)
+)
+
+type testCollector struct{}
+
+func (testCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- memDesc
+	ch <- utilDesc
+	ch <- latencyDesc
+}
+
+func (testCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(memDesc, prometheus.GaugeValue, 10, "0", "GPU-0", "pod-a")
+	ch <- prometheus.MustNewConstMetric(memDesc, prometheus.GaugeValue, 20, "0", "GPU-0", "pod-b")
+	ch <- prometheus.MustNewConstMetric(utilDesc, prometheus.GaugeValue, 50, "0", "GPU-0")
+	ch <- prometheus.MustNewConstHistogram(latencyDesc, 2, 0.3, map[float64]uint64{0.1: 1, 1: 2}, "0")
+}
+
+// gatherer returns r applied to a registry of the test collector
+func gatherer(t *testing.T, r *Relabeler) prometheus.Gatherer {
+	reg := prometheus.NewRegistry()
+	assert.NoError(t, reg.Register(testCollector{}))
+	return r.Gatherer(reg)
+}
+
+func count(t *testing.T, g prometheus.Gatherer) int {
+	n, err := testutil.GatherAndCount(g)
+	assert.NoError(t, err)
+	return n
+}
+
+func TestValidate(t *testing.T) {
+	var conf *Config
+	assert.NoError(t, conf.Validate())
+	assert.NoError(t, (&Config{Enable: []string{"xpu_*"}, StaticLabels: map[string]string{"cluster": "a"}}).Validate())
+	assert.Error(t, (&Config{Enable: []string{"xpu_["}}).Validate())
+	assert.Error(t, (&Config{Disable: []string{""}}).Validate())
+	assert.Error(t, (&Config{StaticLabels: map[string]string{"__name__": "a"}}).Validate())
+	assert.Error(t, (&Config{Labels: []LabelRule{{Drop: []string{"a-b"}}}}).Validate())
+	assert.Error(t, (&Config{Labels: []LabelRule{{Drop: []string{"id"}, Rename: map[string]string{"id": "gpu"}}}}).Validate())
+	assert.Error(t, (&Config{Labels: []LabelRule{{Rename: map[string]string{"id": "gpu", "uuid": "gpu"}}}}).Validate())
+
+	_, err := NewRelabeler(&Config{Enable: []string{"["}})
+	assert.Error(t, err)
+}
+
+func TestNoRules(t *testing.T) {
+	r, err := NewRelabeler(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count(t, gatherer(t, r)))
+}
+
+func TestFilterFamilies(t *testing.T) {
+	r, err := NewRelabeler(&Config{Enable: []string{"xpu_*"}, Disable: []string{"xpu_latency_*", "xpu_utilization"}})
+	assert.NoError(t, err)
+	expected := `
+# HELP xpu_memory_usage the memory usage
+# TYPE xpu_memory_usage gauge
+xpu_memory_usage{id="0",pod="pod-a",uuid="GPU-0"} 10
+xpu_memory_usage{id="0",pod="pod-b",uuid="GPU-0"} 20
+`
+	assert.NoError(t, testutil.GatherAndCompare(gatherer(t, r), strings.NewReader(expected)))
+
+	r, err = NewRelabeler(&Config{Enable: []string{"xpu_utilization"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count(t, gatherer(t, r)))
+}
+
+func TestRelabel(t *testing.T) {
+	r, err := NewRelabeler(&Config{
+		Labels: []LabelRule{
+			{Families: []string{"xpu_memory_*"}, Rename: map[string]string{"uuid": "gpu_uuid"}},
+			{Drop: []string{"id"}},
+		},
+		StaticLabels: map[string]string{"cluster": "c1", "pod": "ignored"},
+	})
+	assert.NoError(t, err)
+	expected := `
+# HELP xpu_latency_seconds the latency
+# TYPE xpu_latency_seconds histogram
+xpu_latency_seconds_bucket{cluster="c1",pod="ignored",le="0.1"} 1
+xpu_latency_seconds_bucket{cluster="c1",pod="ignored",le="1"} 2
+xpu_latency_seconds_bucket{cluster="c1",pod="ignored",le="+Inf"} 2
+xpu_latency_seconds_sum{cluster="c1",pod="ignored"} 0.3
+xpu_latency_seconds_count{cluster="c1",pod="ignored"} 2
+# HELP xpu_memory_usage the memory usage
+# TYPE xpu_memory_usage gauge
+xpu_memory_usage{cluster="c1",gpu_uuid="GPU-0",pod="pod-a"} 10
+xpu_memory_usage{cluster="c1",gpu_uuid="GPU-0",pod="pod-b"} 20
+# HELP xpu_utilization the utilization
+# TYPE xpu_utilization gauge
+xpu_utilization{cluster="c1",pod="ignored",uuid="GPU-0"} 50
+`
+	assert.NoError(t, testutil.GatherAndCompare(gatherer(t, r), strings.NewReader(expected)))
+}
+
+func TestRelabelDuplicated(t *testing.T) {
+	r, err := NewRelabeler(&Config{Labels: []LabelRule{{Families: []string{"xpu_memory_usage"}, Drop: []string{"pod"}}}})
+	assert.NoError(t, err)
+	expected := `
+# HELP xpu_memory_usage the memory usage
+# TYPE xpu_memory_usage gauge
+xpu_memory_usage{id="0",uuid="GPU-0"} 10
+`
+	assert.NoError(t, testutil.GatherAndCompare(gatherer(t, r), strings.NewReader(expected),
+		"xpu_memory_usage"))
+}
+
+func TestRelabelRenameCollision(t *testing.T) {
+	r, err := NewRelabeler(&Config{Labels: []LabelRule{
+		{Families: []string{"xpu_memory_usage"}, Rename: map[string]string{"uuid": "pod"}},
+		{Families: []string{"xpu_utilization"}, Rename: map[string]string{"uuid": "id", "id": "uuid"}},
+	}})
+	assert.NoError(t, err)
+	expected := `
+# HELP xpu_memory_usage the memory usage
+# TYPE xpu_memory_usage gauge
+xpu_memory_usage{id="0",pod="pod-a"} 10
+xpu_memory_usage{id="0",pod="pod-b"} 20
+# HELP xpu_utilization the utilization
+# TYPE xpu_utilization gauge
+xpu_utilization{id="GPU-0",uuid="0"} 50
+`
+	assert.NoError(t, testutil.GatherAndCompare(gatherer(t, r), strings.NewReader(expected),
+		"xpu_memory_usage", "xpu_utilization"))
+}
+
+func TestUpdate(t *testing.T) {
+	r, err := NewRelabeler(&Config{Disable: []string{"xpu_memory_usage"}})
+	assert.NoError(t, err)
+	g := gatherer(t, r)
+	assert.Equal(t, 2, count(t, g))
+
+	assert.Error(t, r.Update(&Config{Disable: []string{"["}}))
+	assert.Equal(t, 2, count(t, g))
+
+	assert.NoError(t, r.Update(nil))
+	assert.Equal(t, 4, count(t, g))
+}
+
+func TestGatherFailureKeepsFamilies(t *testing.T) {
+	r, err := NewRelabeler(&Config{StaticLabels: map[string]string{"cluster": "c1"}})
+	assert.NoError(t, err)
+	reg := prometheus.NewRegistry()
+	assert.NoError(t, reg.Register(testCollector{}))
+	failing := prometheus.Gatherers{reg, prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return nil, errors.New("gather error")
+	})}
+	families, err := r.Gatherer(failing).Gather()
+	assert.Error(t, err)
+	assert.Len(t, families, 3)
+	for _, family := range families {
+		for _, m := range family.Metric {
+			assert.True(t, hasLabel(m.Label, "cluster"), family.GetName())
+		}
+	}
+}
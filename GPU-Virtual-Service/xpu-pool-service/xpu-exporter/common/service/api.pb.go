@@ -16,7 +16,9 @@ import (
 )
 
 const (
+	// Verify that this generated code is sufficiently up-to-date.
 	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
@@ -45,7 +47,6 @@ func (*GetPidsRequest) ProtoMessage() {}
 
 func (x *GetPidsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_msgTypes[0]
-	// check request
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -93,7 +94,6 @@ func (*GetPidsResponse) ProtoMessage() {}
 
 func (x *GetPidsResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_msgTypes[1]
-	// check response
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -141,7 +141,6 @@ func (*GetAllVxpuInfoRequest) ProtoMessage() {}
 
 func (x *GetAllVxpuInfoRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_msgTypes[2]
-	// check request
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -189,7 +188,6 @@ func (*GetAllVxpuInfoResponse) ProtoMessage() {}
 
 func (x *GetAllVxpuInfoResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_msgTypes[3]
-	// check response
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -211,35 +209,33 @@ func (x *GetAllVxpuInfoResponse) GetVxpuInfos() string {
 	}
 	return ""
 }
-	
+
 var File_api_proto protoreflect.FileDescriptor
-	
+
 var file_api_proto_rawDesc = []byte{
 	0x0a, 0x09, 0x61, 0x70, 0x69, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x30, 0x0a, 0x0e, 0x47,
 	0x65, 0x74, 0x50, 0x69, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a,
 	0x0a, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x50, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x0a, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x50, 0x61, 0x74, 0x68, 0x22, 0x33, 0x0a,
 	0x0f, 0x47, 0x65, 0x74, 0x50, 0x69, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x21, 0x0a, 0x0b, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x50, 0x69, 0x64, 0x73, 0x18,
+	0x12, 0x20, 0x0a, 0x0b, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x50, 0x69, 0x64, 0x73, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x50, 0x69,
-	0x64, 0x73, 0x22, 0x32, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70, 0x75,
-	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x06, 0x50,
+	0x64, 0x73, 0x22, 0x2f, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70, 0x75,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x50,
 	0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x50, 0x65, 0x72,
-	0x69, 0x6f, 0x64, 0x22, 0x35, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70,
-	0x75, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a,
+	0x69, 0x6f, 0x64, 0x22, 0x36, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70,
+	0x75, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a,
 	0x09, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x73, 0x32, 0x8a, 0x01, 0x0a, 0x0b,
-	0x50, 0x69, 0x64, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x46, 0x0a, 0x0a, 0x47,
-	0x65, 0x74, 0x50, 0x69, 0x64, 0x73, 0x12, 0x10, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x2e, 0x47, 0x65, 0x74, 0x50, 0x69, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x11, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x69, 0x64,
-	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x52, 0x0a, 0x10, 0x47,
-	0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1a, 0x2e,
-	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78,
-	0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e,
-	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78,
+	0x52, 0x09, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x73, 0x32, 0x82, 0x01, 0x0a, 0x0b,
+	0x50, 0x69, 0x64, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x47,
+	0x65, 0x74, 0x50, 0x69, 0x64, 0x73, 0x12, 0x0f, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x69, 0x64, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x69, 0x64,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x0e, 0x47,
+	0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x16, 0x2e,
+	0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78, 0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x56, 0x78,
 	0x70, 0x75, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
-	0x42, 0x0c, 0x5a, 0x05, 0x2e, 0x2f, 0x3b, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x62, 0x06, 
+	0x42, 0x0c, 0x5a, 0x0a, 0x2e, 0x2f, 0x3b, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x62, 0x06,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
@@ -257,22 +253,21 @@ func file_api_proto_rawDescGZIP() []byte {
 
 var file_api_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_api_proto_goTypes = []any{
-	(*GetPidsRequest)(nil),       // 0: GetPidsRequest
-	(*GetPidsResponse)(nil),      // 1: GetPidsResponse
-	(*GetAllVxpuInfoRequest)(nil), // 2: GetAllVxpuInfoRequest
+	(*GetPidsRequest)(nil),         // 0: GetPidsRequest
+	(*GetPidsResponse)(nil),        // 1: GetPidsResponse
+	(*GetAllVxpuInfoRequest)(nil),  // 2: GetAllVxpuInfoRequest
 	(*GetAllVxpuInfoResponse)(nil), // 3: GetAllVxpuInfoResponse
 }
-
 var file_api_proto_depIdxs = []int32{
-	0,
-	2,
-	1,
-	3,
-	2,
-	0,
-	0,
-	0,
-	0,
+	0, // 0: PidsService.GetPids:input_type -> GetPidsRequest
+	2, // 1: PidsService.GetAllVxpuInfo:input_type -> GetAllVxpuInfoRequest
+	1, // 2: PidsService.GetPids:output_type -> GetPidsResponse
+	3, // 3: PidsService.GetAllVxpuInfo:output_type -> GetAllVxpuInfoResponse
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_init() }
@@ -282,49 +277,49 @@ func file_api_proto_init() {
 	}
 	if !protoimpl.UnsafeEnabled {
 		file_api_proto_msgTypes[0].Exporter = func(v any, i int) any {
-			switch val := v.(*GetPidsRequest); i {
+			switch v := v.(*GetPidsRequest); i {
 			case 0:
-				return &val.state
+				return &v.state
 			case 1:
-				return &val.sizeCache
+				return &v.sizeCache
 			case 2:
-				return &val.unknownFields
+				return &v.unknownFields
 			default:
 				return nil
 			}
 		}
 		file_api_proto_msgTypes[1].Exporter = func(v any, i int) any {
-			switch val := v.(*GetPidsResponse); i {
+			switch v := v.(*GetPidsResponse); i {
 			case 0:
-				return &val.state
+				return &v.state
 			case 1:
-				return &val.sizeCache
+				return &v.sizeCache
 			case 2:
-				return &val.unknownFields
+				return &v.unknownFields
 			default:
 				return nil
 			}
 		}
 		file_api_proto_msgTypes[2].Exporter = func(v any, i int) any {
-			switch val := v.(*GetAllVxpuInfoRequest); i {
+			switch v := v.(*GetAllVxpuInfoRequest); i {
 			case 0:
-				return &val.state
+				return &v.state
 			case 1:
-				return &val.sizeCache
+				return &v.sizeCache
 			case 2:
-				return &val.unknownFields
+				return &v.unknownFields
 			default:
 				return nil
 			}
 		}
 		file_api_proto_msgTypes[3].Exporter = func(v any, i int) any {
-			switch val := v.(*GetAllVxpuInfoResponse); i {
+			switch v := v.(*GetAllVxpuInfoResponse); i {
 			case 0:
-				return &val.state
+				return &v.state
 			case 1:
-				return &val.sizeCache
+				return &v.sizeCache
 			case 2:
-				return &val.unknownFields
+				return &v.unknownFields
 			default:
 				return nil
 			}
@@ -345,7 +340,7 @@ func file_api_proto_init() {
 		MessageInfos:      file_api_proto_msgTypes,
 	}.Build()
 	File_api_proto = out.File
+	file_api_proto_rawDesc = nil
 	file_api_proto_goTypes = nil
 	file_api_proto_depIdxs = nil
-	file_api_proto_rawDesc = nil
-}
\ No newline at end of file
+}
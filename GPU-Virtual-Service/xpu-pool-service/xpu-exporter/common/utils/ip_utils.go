@@ -6,33 +6,90 @@
 package utils
 
 import (
+	"fmt"
 	"net"
 	"net/http"
 	"strings"
 )
 
-// ClientIP try to get the clientIP
-func ClientIP(r *http.Request) string {
+// ParseCIDRs parses a comma separated list of CIDRs, a single IP is taken as the CIDR of the IP only
+func ParseCIDRs(list string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip %q", item)
+			}
+			bits := net.IPv6len * 8
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), net.IPv4len*8
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %q: %v", item, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// ContainsIP reports whether the ip is in any of the nets
+func ContainsIP(nets []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range nets {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP try to get the clientIP.
+// The X-Forwarded-For and X-Real-Ip headers are only used when the request comes from a trusted proxy,
+// X-Forwarded-For is walked from the nearest hop and the first address not in trustedProxies is the client.
+func ClientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	remoteIP, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		return ""
+	}
+	if !ContainsIP(trustedProxies, remoteIP) {
+		return remoteIP
+	}
+
 	// get forwarded ip firstly
-	var ip string
-	xForwardedFor := r.Header.Get("X-Forwarded-For")
-	forwardSlice := strings.Split(xForwardedFor, ",")
-	if len(forwardSlice) >= 1 {
-		if ip = strings.TrimSpace(forwardSlice[0]); ip != "" {
+	forwardSlice := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwardSlice) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwardSlice[i])
+		if ip == "" {
+			continue
+		}
+		if net.ParseIP(ip) == nil {
+			// a malformed hop can not be trusted, take the last valid one
+			break
+		}
+		remoteIP = ip
+		if !ContainsIP(trustedProxies, ip) {
 			return ip
 		}
 	}
-
-	// try get ip from "X-Real-Ip"
-	ip = strings.TrimSpace(r.Header.Get("X-Real-Ip"))
-	if ip != "" {
-		return ip
+	if r.Header.Get("X-Forwarded-For") != "" {
+		return remoteIP
 	}
 
-	var err error
-	if ip, _, err = net.SplitHostPort(strings.TrimSpace(r.RemoteAddr)); err == nil {
+	// try get ip from "X-Real-Ip"
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-Ip")); net.ParseIP(ip) != nil {
 		return ip
 	}
-
-	return ""
+	return remoteIP
 }
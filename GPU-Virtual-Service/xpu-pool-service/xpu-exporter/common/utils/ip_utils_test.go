@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package utils offer some utils for certificate handling
+package utils
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCIDRs(t *testing.T) {
+	nets, err := ParseCIDRs(" 10.0.0.0/8, 192.168.0.1 ,::1,")
+	assert.NoError(t, err)
+	assert.Len(t, nets, 3)
+	assert.True(t, ContainsIP(nets, "10.1.2.3"))
+	assert.True(t, ContainsIP(nets, "192.168.0.1"))
+	assert.False(t, ContainsIP(nets, "192.168.0.2"))
+	assert.True(t, ContainsIP(nets, "::1"))
+	assert.False(t, ContainsIP(nets, "invalid"))
+
+	_, err = ParseCIDRs("10.0.0.0/33")
+	assert.Error(t, err)
+	_, err = ParseCIDRs("host")
+	assert.Error(t, err)
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseCIDRs("10.0.0.0/8")
+	assert.NoError(t, err)
+	newRequest := func(remote, forwardedFor, realIP string) *http.Request {
+		r := &http.Request{RemoteAddr: remote, Header: http.Header{}}
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		if realIP != "" {
+			r.Header.Set("X-Real-Ip", realIP)
+		}
+		return r
+	}
+
+	// the headers of an untrusted client are ignored
+	assert.Equal(t, "192.168.0.1", ClientIP(newRequest("192.168.0.1:80", "1.1.1.1", "2.2.2.2"), proxies))
+	assert.Equal(t, "192.168.0.1", ClientIP(newRequest("192.168.0.1:80", "1.1.1.1", ""), nil))
+	// the first untrusted hop from the right is the client, the spoofed left hops are ignored
+	assert.Equal(t, "1.1.1.1", ClientIP(newRequest("10.0.0.1:80", "3.3.3.3, 1.1.1.1, 10.0.0.2", ""), proxies))
+	// all hops trusted, the farthest one is the client
+	assert.Equal(t, "10.0.0.3", ClientIP(newRequest("10.0.0.1:80", "10.0.0.3,10.0.0.2", ""), proxies))
+	// a malformed hop stops the walk
+	assert.Equal(t, "10.0.0.2", ClientIP(newRequest("10.0.0.1:80", "1.1.1.1,bad,10.0.0.2", ""), proxies))
+	assert.Equal(t, "2.2.2.2", ClientIP(newRequest("10.0.0.1:80", "", "2.2.2.2"), proxies))
+	assert.Equal(t, "10.0.0.1", ClientIP(newRequest("10.0.0.1:80", "", ""), proxies))
+	assert.Equal(t, "", ClientIP(newRequest("invalid", "", ""), proxies))
+}
@@ -33,10 +33,14 @@ type VxpuDevice struct {
 	Id                    string
 	GpuId                 string
 	PodUID                string
+	PodName               string
+	PodNamespace          string
 	ContainerName         string
 	VxpuMemoryUsed        uint64
 	VxpuMemoryUtilization float64
 	VxpuCoreUtilization   float64
 	VxpuMemoryLimit       int64
 	VxpuCoreLimit         int64
+	MemoryLimitExceeded   bool
+	CoreLimitExceeded     bool
 }
@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"huawei.com/vxpu-device-plugin/pkg/log"
+
+	"huawei.com/xpu-exporter/collector"
+	"huawei.com/xpu-exporter/common/utils"
+)
+
+const (
+	apiVersion      = "v1"
+	devicesPath     = "/api/v1/devices"
+	vgpusPath       = "/api/v1/vgpus"
+	kindDeviceList  = "DeviceList"
+	kindDevice      = "Device"
+	kindVgpuList    = "VgpuList"
+	kindStatus      = "Status"
+	bytesPerMiB     = 1024 * 1024
+	namespaceFilter = "namespace"
+	podFilter       = "pod"
+	requestIDHeader = "X-Request-Id"
+)
+
+// apiVgpu a vxpu of the device with its limits and usage
+type apiVgpu struct {
+	ID                  string  `json:"id"`
+	DeviceID            string  `json:"deviceId"`
+	PodUID              string  `json:"podUid"`
+	Namespace           string  `json:"namespace,omitempty"`
+	Pod                 string  `json:"pod,omitempty"`
+	Container           string  `json:"container"`
+	MemoryUsedBytes     uint64  `json:"memoryUsedBytes"`
+	MemoryLimitBytes    int64   `json:"memoryLimitBytes"`
+	CoreLimit           int64   `json:"coreLimit"`
+	MemoryUtilization   float64 `json:"memoryUtilization"`
+	CoreUtilization     float64 `json:"coreUtilization"`
+	MemoryLimitExceeded bool    `json:"memoryLimitExceeded"`
+	CoreLimitExceeded   bool    `json:"coreLimitExceeded"`
+}
+
+// apiDevice a physical xpu with the nested vxpus
+type apiDevice struct {
+	ID                string    `json:"id"`
+	XpuType           string    `json:"xpuType"`
+	Index             int32     `json:"index"`
+	Model             string    `json:"model"`
+	Healthy           bool      `json:"healthy"`
+	NodeName          string    `json:"nodeName"`
+	NodeIP            string    `json:"nodeIp"`
+	DriverVersion     string    `json:"driverVersion"`
+	SplitCount        uint32    `json:"splitCount"`
+	MemoryTotalBytes  uint64    `json:"memoryTotalBytes"`
+	MemoryUsedBytes   uint64    `json:"memoryUsedBytes"`
+	MemoryUtilization float64   `json:"memoryUtilization"`
+	Utilization       float64   `json:"utilization"`
+	PowerUsage        int       `json:"powerUsage"`
+	Temperature       int       `json:"temperature"`
+	Vgpus             []apiVgpu `json:"vgpus"`
+}
+
+type apiResponse struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Items      any    `json:"items,omitempty"`
+	Item       any    `json:"item,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (s *ExporterServer) registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc(devicesPath, s.listDevicesHandler)
+	mux.HandleFunc(devicesPath+"/", s.getDeviceHandler)
+	mux.HandleFunc(vgpusPath, s.listVgpusHandler)
+}
+
+// devices returns the cached devices of all the collector services able to provide them,
+// sorted by xpu type and index
+func (s *ExporterServer) devices() []apiDevice {
+	var devices []apiDevice
+	for _, entry := range s.collectServices {
+		provider, ok := entry.service.(collector.IDeviceProvider)
+		if !ok {
+			continue
+		}
+		for _, device := range provider.Devices() {
+			if device != nil {
+				devices = append(devices, s.toAPIDevice(entry.service.GetName(), device))
+			}
+		}
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].XpuType != devices[j].XpuType {
+			return devices[i].XpuType < devices[j].XpuType
+		}
+		if devices[i].Index != devices[j].Index {
+			return devices[i].Index < devices[j].Index
+		}
+		return devices[i].ID < devices[j].ID
+	})
+	return devices
+}
+
+func (s *ExporterServer) toAPIDevice(xpuType string, device *utils.XPUDevice) apiDevice {
+	d := apiDevice{
+		ID:                device.Id,
+		XpuType:           xpuType,
+		Index:             device.Index,
+		Model:             device.Type,
+		Healthy:           device.Health,
+		NodeName:          device.NodeName,
+		NodeIP:            device.NodeIp,
+		DriverVersion:     device.DriverVersion,
+		SplitCount:        device.Count,
+		MemoryTotalBytes:  device.MemoryTotal * bytesPerMiB,
+		MemoryUsedBytes:   device.MemoryUsed * bytesPerMiB,
+		MemoryUtilization: device.MemoryUtilization,
+		Utilization:       device.XpuUtilization,
+		PowerUsage:        device.PowerUsage,
+		Temperature:       device.Temperature,
+		Vgpus:             make([]apiVgpu, 0, len(device.VxpuDeviceList)),
+	}
+	for _, v := range device.VxpuDeviceList {
+		namespace, pod := s.podName(v)
+		d.Vgpus = append(d.Vgpus, apiVgpu{
+			ID:                  v.Id,
+			DeviceID:            device.Id,
+			PodUID:              v.PodUID,
+			Namespace:           namespace,
+			Pod:                 pod,
+			Container:           v.ContainerName,
+			MemoryUsedBytes:     v.VxpuMemoryUsed * bytesPerMiB,
+			MemoryLimitBytes:    v.VxpuMemoryLimit * bytesPerMiB,
+			CoreLimit:           v.VxpuCoreLimit,
+			MemoryUtilization:   v.VxpuMemoryUtilization,
+			CoreUtilization:     v.VxpuCoreUtilization,
+			MemoryLimitExceeded: v.MemoryLimitExceeded,
+			CoreLimitExceeded:   v.CoreLimitExceeded,
+		})
+	}
+	return d
+}
+
+// podName returns the namespace and name of the pod of the vxpu from the resolver, or those reported by
+// the device plugin when the resolver is disabled or has not seen the pod
+func (s *ExporterServer) podName(v utils.VxpuDevice) (string, string) {
+	if namespace, pod := s.Resolver.PodName(v.PodUID); pod != "" {
+		return namespace, pod
+	}
+	return v.PodNamespace, v.PodName
+}
+
+func (s *ExporterServer) listDevicesHandler(w http.ResponseWriter, r *http.Request) {
+	devices := s.devices()
+	if devices == nil {
+		devices = []apiDevice{}
+	}
+	writeAPIResponse(w, r, http.StatusOK, apiResponse{APIVersion: apiVersion, Kind: kindDeviceList, Items: devices})
+}
+
+func (s *ExporterServer) getDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, devicesPath+"/")
+	if id == "" || strings.Contains(id, "/") {
+		writeAPIError(w, r, http.StatusNotFound, "device id is invalid")
+		return
+	}
+	for _, device := range s.devices() {
+		if device.ID == id {
+			writeAPIResponse(w, r, http.StatusOK, apiResponse{APIVersion: apiVersion, Kind: kindDevice, Item: device})
+			return
+		}
+	}
+	writeAPIError(w, r, http.StatusNotFound, "device "+id+" not found")
+}
+
+// listVgpusHandler lists the vxpus of all devices, the pod filter matches the pod name or uid
+func (s *ExporterServer) listVgpusHandler(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get(namespaceFilter)
+	pod := r.URL.Query().Get(podFilter)
+	vgpus := make([]apiVgpu, 0)
+	for _, device := range s.devices() {
+		for _, v := range device.Vgpus {
+			if namespace != "" && v.Namespace != namespace {
+				continue
+			}
+			if pod != "" && v.Pod != pod && v.PodUID != pod {
+				continue
+			}
+			vgpus = append(vgpus, v)
+		}
+	}
+	writeAPIResponse(w, r, http.StatusOK, apiResponse{APIVersion: apiVersion, Kind: kindVgpuList, Items: vgpus})
+}
+
+func writeAPIError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	writeAPIResponse(w, r, status, apiResponse{APIVersion: apiVersion, Kind: kindStatus, Error: msg})
+}
+
+// writeAPIResponse writes the response, the logs carry the request id set by the client or the proxy
+func writeAPIResponse(w http.ResponseWriter, r *http.Request, status int, resp apiResponse) {
+	logger := log.WithRequestID(r.Header.Get(requestIDHeader))
+	logger.Debugf("api request %s %s responds %d", r.Method, r.URL.Path, status)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Errorf("write api response error: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package server implement the HTTP service for Prometheus to obtain monitoring data
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"huawei.com/xpu-exporter/common/utils"
+)
+
+type mockDeviceService struct {
+	mockCollectorService
+	devices map[string]*utils.XPUDevice
+}
+
+func (m *mockDeviceService) GetName() string {
+	return "gpu"
+}
+
+func (m *mockDeviceService) Devices() map[string]*utils.XPUDevice {
+	return m.devices
+}
+
+type testResponse struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Items      []apiDevice `json:"items"`
+	Item       apiDevice   `json:"item"`
+	Error      string      `json:"error"`
+}
+
+type testVgpuResponse struct {
+	Kind  string    `json:"kind"`
+	Items []apiVgpu `json:"items"`
+}
+
+func newTestAPIServer(t *testing.T) *http.ServeMux {
+	s := &ExporterServer{}
+	assert.NoError(t, s.RegisterCollectorService(&mockCollectorService{}))
+	assert.NoError(t, s.RegisterCollectorService(&mockDeviceService{devices: map[string]*utils.XPUDevice{
+		"GPU-1": {Index: 1, Id: "GPU-1", Type: "A100", Health: true, Count: 4, MemoryTotal: 40960},
+		"GPU-0": {Index: 0, Id: "GPU-0", Type: "A100", Health: true, Count: 4, MemoryTotal: 40960,
+			VxpuDeviceList: utils.VxpuDevices{
+				{Id: "GPU-0-0", GpuId: "GPU-0", PodUID: "pod-1", PodName: "train-0", PodNamespace: "ml",
+					ContainerName: "train", VxpuMemoryUsed: 1024, VxpuMemoryLimit: 10240, VxpuCoreLimit: 30},
+				{Id: "GPU-0-1", GpuId: "GPU-0", PodUID: "pod-2", PodName: "infer-0", PodNamespace: "default",
+					ContainerName: "infer", VxpuMemoryLimit: 10240},
+				{Id: "GPU-0-2", GpuId: "GPU-0", PodUID: "pod-3", ContainerName: "legacy"},
+			}},
+	}}))
+	mux := http.NewServeMux()
+	s.registerAPI(mux)
+	return mux
+}
+
+func get(mux *http.ServeMux, url string, v any) int {
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	_ = json.Unmarshal(w.Body.Bytes(), v)
+	return w.Code
+}
+
+func TestListDevices(t *testing.T) {
+	mux := newTestAPIServer(t)
+	var resp testResponse
+	assert.Equal(t, http.StatusOK, get(mux, devicesPath, &resp))
+	assert.Equal(t, apiVersion, resp.APIVersion)
+	assert.Equal(t, kindDeviceList, resp.Kind)
+	assert.Len(t, resp.Items, 2)
+	assert.Equal(t, "GPU-0", resp.Items[0].ID)
+	assert.Equal(t, "gpu", resp.Items[0].XpuType)
+	assert.Equal(t, uint64(40960*bytesPerMiB), resp.Items[0].MemoryTotalBytes)
+	assert.Len(t, resp.Items[0].Vgpus, 3)
+	assert.Equal(t, int64(10240*bytesPerMiB), resp.Items[0].Vgpus[0].MemoryLimitBytes)
+	assert.NotNil(t, resp.Items[1].Vgpus)
+}
+
+func TestGetDevice(t *testing.T) {
+	mux := newTestAPIServer(t)
+	var resp testResponse
+	assert.Equal(t, http.StatusOK, get(mux, devicesPath+"/GPU-1", &resp))
+	assert.Equal(t, kindDevice, resp.Kind)
+	assert.Equal(t, "GPU-1", resp.Item.ID)
+
+	resp = testResponse{}
+	assert.Equal(t, http.StatusNotFound, get(mux, devicesPath+"/GPU-9", &resp))
+	assert.Equal(t, kindStatus, resp.Kind)
+	assert.NotEmpty(t, resp.Error)
+	assert.Equal(t, http.StatusNotFound, get(mux, devicesPath+"/GPU-0/vgpus", &resp))
+}
+
+func TestListVgpus(t *testing.T) {
+	mux := newTestAPIServer(t)
+	var resp testVgpuResponse
+	assert.Equal(t, http.StatusOK, get(mux, vgpusPath, &resp))
+	assert.Equal(t, kindVgpuList, resp.Kind)
+	assert.Len(t, resp.Items, 3)
+
+	resp = testVgpuResponse{}
+	assert.Equal(t, http.StatusOK, get(mux, vgpusPath+"?pod=pod-2", &resp))
+	assert.Len(t, resp.Items, 1)
+	assert.Equal(t, "GPU-0-1", resp.Items[0].ID)
+	assert.Equal(t, "GPU-0", resp.Items[0].DeviceID)
+
+	// without a resolver the pod names reported by the device plugin are used
+	resp = testVgpuResponse{}
+	assert.Equal(t, http.StatusOK, get(mux, vgpusPath+"?namespace=ml", &resp))
+	assert.Len(t, resp.Items, 1)
+	assert.Equal(t, "GPU-0-0", resp.Items[0].ID)
+	assert.Equal(t, "train-0", resp.Items[0].Pod)
+
+	resp = testVgpuResponse{}
+	assert.Equal(t, http.StatusOK, get(mux, vgpusPath+"?namespace=default&pod=infer-0", &resp))
+	assert.Len(t, resp.Items, 1)
+	assert.Equal(t, "GPU-0-1", resp.Items[0].ID)
+
+	resp = testVgpuResponse{}
+	assert.Equal(t, http.StatusOK, get(mux, vgpusPath+"?namespace=kube-system", &resp))
+	assert.NotNil(t, resp.Items)
+	assert.Len(t, resp.Items, 0)
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+
+	"huawei.com/vxpu-device-plugin/pkg/log"
+
+	"huawei.com/xpu-exporter/common/filewatch"
+)
+
+// certReloader keeps the serving certificate and the client CA pool loaded from the files,
+// and reloads them when the files change so that a rotated certificate is served without a restart.
+type certReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+}
+
+func newCertReloader(certFile, keyFile, clientCAFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload loads all the files, the loaded ones are kept when any of them is invalid
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("HTTPS key pair error: %v", err)
+	}
+	var clientCA *x509.CertPool
+	if r.clientCAFile != "" {
+		if clientCA, err = loadCertPool(r.clientCAFile); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCA = clientCA
+	return nil
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("HTTPS client ca error: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("HTTPS client ca error: no certificate found in %s", caFile)
+	}
+	return pool, nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.cert == nil {
+		return nil, fmt.Errorf("certificate not loaded")
+	}
+	return r.cert, nil
+}
+
+// tlsConfig returns the server tls config, the client certificate is required and verified
+// against the client CA when the client CA file is set
+func (r *certReloader) tlsConfig() *tls.Config {
+	conf := getTLSConfig()
+	conf.GetCertificate = r.getCertificate
+	if r.clientCAFile == "" {
+		return conf
+	}
+	conf.ClientAuth = tls.RequireAndVerifyClientCert
+	conf.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		clientConf := conf.Clone()
+		clientConf.GetConfigForClient = nil
+		clientConf.ClientCAs = r.clientCA
+		return clientConf, nil
+	}
+	return conf
+}
+
+// watch reloads the files on change until ctx is cancelled
+func (r *certReloader) watch(ctx context.Context) {
+	err := filewatch.Watch(ctx, func() {
+		if err := r.reload(); err != nil {
+			log.Errorf("reload certificate error: %v, keep serving the previous certificate", err)
+			return
+		}
+		log.Infof("certificate %s reloaded", r.certFile)
+	}, r.certFile, r.keyFile, r.clientCAFile)
+	if err != nil {
+		log.Errorf("watch certificate error: %v, the certificate will not be reloaded", err)
+	}
+}
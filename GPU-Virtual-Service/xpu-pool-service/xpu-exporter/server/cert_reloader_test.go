@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+// Package server implement the HTTP service for Prometheus to obtain monitoring data
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"huawei.com/xpu-exporter/common/filewatch"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert creates a certificate signed by parent, or a self-signed CA when parent is nil
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	assert.NoError(t, os.WriteFile(certFile, c.certPEM, 0600))
+	assert.NoError(t, os.WriteFile(keyFile, c.keyPEM, 0600))
+}
+
+func (c *testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	assert.NoError(t, err)
+	return cert
+}
+
+func TestCertReloaderWatch(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	first := newTestCert(t, "first", nil)
+	first.write(t, certFile, keyFile)
+
+	r, err := newCertReloader(certFile, keyFile, "")
+	assert.NoError(t, err)
+	cert, err := r.getCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, first.cert.Raw, cert.Certificate[0])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.watch(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	second := newTestCert(t, "second", nil)
+	second.write(t, certFile, keyFile)
+	assert.Eventually(t, func() bool {
+		cert, err := r.getCertificate(nil)
+		return err == nil && string(cert.Certificate[0]) == string(second.cert.Raw)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// an invalid certificate keeps the previous one
+	assert.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0600))
+	time.Sleep(2 * filewatch.Delay)
+	cert, err = r.getCertificate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, second.cert.Raw, cert.Certificate[0])
+}
+
+func TestCertReloaderMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	caFile := filepath.Join(dir, "ca.crt")
+	assert.NoError(t, os.WriteFile(caFile, ca.certPEM, 0600))
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	newTestCert(t, "server", ca).write(t, certFile, keyFile)
+
+	r, err := newCertReloader(certFile, keyFile, caFile)
+	assert.NoError(t, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = r.tlsConfig()
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(certs []tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs: roots, Certificates: certs, MinVersion: tls.VersionTLS13,
+		}}}
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	assert.Error(t, get(nil))
+	assert.Error(t, get([]tls.Certificate{newTestCert(t, "other", nil).tlsCertificate(t)}))
+	assert.NoError(t, get([]tls.Certificate{newTestCert(t, "prometheus", ca).tlsCertificate(t)}))
+}
+
+func TestVerifyTLSParams(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	newTestCert(t, "server", nil).write(t, certFile, keyFile)
+
+	s := &ExporterServer{CertFile: certFile}
+	assert.Error(t, s.verifyTLSParams())
+	s = &ExporterServer{ClientCAFile: certFile}
+	assert.Error(t, s.verifyTLSParams())
+	s = &ExporterServer{CertFile: certFile, KeyFile: keyFile, ClientCAFile: filepath.Join(dir, "none")}
+	assert.Error(t, s.verifyTLSParams())
+	s = &ExporterServer{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile}
+	assert.NoError(t, s.verifyTLSParams())
+	assert.Equal(t, HTTPS, s.ProtocolType)
+	assert.NotNil(t, s.certs)
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) Huawei Technologies Co., Ltd. 2025. All rights reserved.
+ */
+
+package server
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"huawei.com/vxpu-device-plugin/pkg/log"
+
+	"huawei.com/xpu-exporter/collector"
+)
+
+// collectorEntry a registered collector service and the cancel func of its running context.
+// A disabled service is stopped and started again once it is enabled.
+type collectorEntry struct {
+	service collector.ICollectorService
+	mutex   sync.Mutex
+	cancel  context.CancelFunc
+	enabled bool
+	// disabling whether the running service is stopped by disabling it rather than by the service itself
+	disabling bool
+	// wake is closed when the service is enabled
+	wake chan struct{}
+}
+
+func newCollectorEntry(c collector.ICollectorService) *collectorEntry {
+	return &collectorEntry{service: c, enabled: true, wake: make(chan struct{})}
+}
+
+func (e *collectorEntry) setCancel(cancel context.CancelFunc) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.cancel = cancel
+}
+
+// stop is passed to the service as its cancel, the service stops only itself by calling it
+func (e *collectorEntry) stop() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.cancel != nil {
+		e.cancel()
+	}
+}
+
+func (e *collectorEntry) isEnabled() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.enabled
+}
+
+// setEnabled stops the running service when it is disabled, and wakes up the waiting run when it is enabled
+func (e *collectorEntry) setEnabled(enabled bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.enabled == enabled {
+		return
+	}
+	e.enabled = enabled
+	if enabled {
+		close(e.wake)
+		e.wake = make(chan struct{})
+		log.Infof("collector service %s enabled", e.service.GetName())
+		return
+	}
+	if e.cancel != nil {
+		e.disabling = true
+		e.cancel()
+	}
+	log.Infof("collector service %s disabled", e.service.GetName())
+}
+
+// waitEnabled waits until the service is enabled, returns false when ctx is done first
+func (e *collectorEntry) waitEnabled(ctx context.Context) bool {
+	for {
+		e.mutex.Lock()
+		enabled, wake := e.enabled, e.wake
+		e.mutex.Unlock()
+		if enabled {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-wake:
+		}
+	}
+}
+
+// stoppedByDisabling returns whether the last run was stopped by disabling the service and resets it
+func (e *collectorEntry) stoppedByDisabling() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	disabling := e.disabling
+	e.disabling = false
+	e.cancel = nil
+	return disabling
+}
+
+// run starts the service whenever it is enabled until ctx is done, the service calls stop or returns
+// by itself
+func (e *collectorEntry) run(ctx context.Context) {
+	for e.waitEnabled(ctx) {
+		entryCtx, entryCancel := context.WithCancel(ctx)
+		e.setCancel(entryCancel)
+		e.start(entryCtx)
+		entryCancel()
+		if !e.stoppedByDisabling() || ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// start runs the service until ctx is done, the cancel passed to the service only stops this service
+func (e *collectorEntry) start(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("collector service %s panic: %v, stack: %s", e.service.GetName(), r, debug.Stack())
+		}
+	}()
+	log.Infof("collector service %s starting", e.service.GetName())
+	e.service.Start(ctx, e.stop)
+	log.Infof("collector service %s stopped", e.service.GetName())
+}
+
+// isolatedCollector recovers the panic in Collect of the wrapped collector, and exports nothing while
+// the service of the collector is disabled
+type isolatedCollector struct {
+	name      string
+	collector prometheus.Collector
+	entry     *collectorEntry
+}
+
+// Describe implements prometheus.Collector
+func (c *isolatedCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *isolatedCollector) Collect(ch chan<- prometheus.Metric) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("collector %s panic in collect: %v, stack: %s", c.name, r, debug.Stack())
+		}
+	}()
+	if c.entry != nil && !c.entry.isEnabled() {
+		return
+	}
+	c.collector.Collect(ch)
+}
@@ -17,13 +17,19 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"huawei.com/vxpu-device-plugin/pkg/log"
 
 	"huawei.com/xpu-exporter/collector"
 	"huawei.com/xpu-exporter/common/limiter"
+	"huawei.com/xpu-exporter/common/metadata"
+	"huawei.com/xpu-exporter/common/relabel"
+	"huawei.com/xpu-exporter/common/utils"
 )
 
 type ProtocolType int
@@ -57,12 +63,63 @@ type ExporterServer struct {
 	LimitIPConn int
 	// LimitTotalConn identifies the maximum number of connections that the service can handle
 	LimitTotalConn int
+	// TrustedProxies comma separated CIDRs of the proxies whose forwarded headers identify the client IP
+	TrustedProxies string
+	// AllowCIDRs comma separated CIDRs of the allowed client IPs, all are allowed when empty
+	AllowCIDRs string
+	// DenyCIDRs comma separated CIDRs of the denied client IPs
+	DenyCIDRs string
+	// Resolver resolves the namespace and name of the pods in the json api, optional
+	Resolver *metadata.Resolver
+	// Relabeler filters the families and relabels the gathered metrics, optional
+	Relabeler *relabel.Relabeler
 	// ProtocolType identifies which protocol to use (HTTP or HTTPS)
 	ProtocolType ProtocolType
-	// Collector service instance
-	collectService collector.ICollectorService
-	// cert stores the pre-loaded TLS certificate for HTTPS server to avoid repeated file I/O during handshakes.
-	cert *tls.Certificate
+	// HTTPS enables HTTPS with the certificate in the cert dir when CertFile is not set, the same as HTTPS_ENABLE=on
+	HTTPS bool
+	// CertFile and KeyFile identify the serving certificate, setting them enables HTTPS
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables mTLS, the client certificates are required and verified against it
+	ClientCAFile string
+	// the CIDRs parsed by VerifyServerParams
+	trustedProxies []*net.IPNet
+	allowCIDRs     []*net.IPNet
+	denyCIDRs      []*net.IPNet
+	// Collector service instances, each one runs with its own context
+	collectServices []*collectorEntry
+	// certs stores the pre-loaded TLS certificate for HTTPS server to avoid repeated file I/O during handshakes,
+	// it reloads the certificate when the files change.
+	certs *certReloader
+	// mu guards the limits reloaded at runtime and the handler serving with them
+	mu             sync.Mutex
+	handler        *swappableHandler
+	limiterMetrics *limiter.Metrics
+}
+
+// Limits the request limits of the http service which can be reloaded at runtime
+type Limits struct {
+	Concurrency    int
+	LimitIPReq     string
+	TrustedProxies string
+	AllowCIDRs     string
+	DenyCIDRs      string
+}
+
+// swappableHandler serves with the latest limit handler, so the limits are changed without closing the listener
+type swappableHandler struct {
+	handler atomic.Pointer[http.Handler]
+}
+
+func newSwappableHandler(h http.Handler) *swappableHandler {
+	sh := &swappableHandler{}
+	sh.handler.Store(&h)
+	return sh
+}
+
+// ServeHTTP implements http.Handler
+func (h *swappableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*h.handler.Load()).ServeHTTP(w, r)
 }
 
 func indexHandler(s *ExporterServer) http.HandlerFunc {
@@ -82,6 +139,7 @@ func (s *ExporterServer) indexHandler(w http.ResponseWriter, _ *http.Request) {
 		<body>
 		<h1 align="center">XPU-Exporter</h1>
 		<p align="center">Welcome to use XPU-Exporter, the Prometheus metrics url is ` + protocol + `://IP:` + strconv.Itoa(s.Port) + `/metrics: <a href="./metrics">Metrics</a></p>
+		<p align="center">The device json api url is ` + protocol + `://IP:` + strconv.Itoa(s.Port) + devicesPath + `: <a href=".` + devicesPath + `">Devices</a></p>
 		</body>
 		</html>`))
 }
@@ -97,40 +155,93 @@ func (s *ExporterServer) VerifyServerParams() error {
 	}
 	s.Ip = parsedIP.String()
 
-	reg := regexp.MustCompile(limiter.IPReqLimitReg)
-	if !reg.Match([]byte(s.LimitIPReq)) {
-		return errors.New("limitIPReq format error")
-	}
-
 	if s.LimitIPConn < 1 || s.LimitIPConn > maxIPConnLimit {
 		return errors.New("limitIPConn is invalid")
 	}
 	if s.LimitTotalConn < 1 || s.LimitTotalConn > maxConcurrency {
 		return errors.New("limitTotalConn is invalid")
 	}
+	if err := s.verifyLimitParams(); err != nil {
+		return err
+	}
+
+	return s.verifyTLSParams()
+}
+
+// verifyLimitParams verify the request limits and parse the CIDRs of them
+func (s *ExporterServer) verifyLimitParams() error {
+	reg := regexp.MustCompile(limiter.IPReqLimitReg)
+	if !reg.Match([]byte(s.LimitIPReq)) {
+		return errors.New("limitIPReq format error")
+	}
 	if s.Concurrency < 1 || s.Concurrency > maxConcurrency {
 		return errors.New("concurrency is invalid")
 	}
+	var err error
+	if s.trustedProxies, err = utils.ParseCIDRs(s.TrustedProxies); err != nil {
+		return fmt.Errorf("trustedProxies is invalid: %v", err)
+	}
+	if s.allowCIDRs, err = utils.ParseCIDRs(s.AllowCIDRs); err != nil {
+		return fmt.Errorf("allowCIDRs is invalid: %v", err)
+	}
+	if s.denyCIDRs, err = utils.ParseCIDRs(s.DenyCIDRs); err != nil {
+		return fmt.Errorf("denyCIDRs is invalid: %v", err)
+	}
+	return nil
+}
 
-	// Check HTTPS configuration if needed
-	if os.Getenv("HTTPS_ENABLE") == "on" {
-		s.ProtocolType = HTTPS
-		certPath, err := getCertsFile(".crt")
+// ReloadLimits verify the limits and serve the new requests with them, the listener and the connections are kept.
+// The request counts of the clients are reset.
+func (s *ExporterServer) ReloadLimits(l Limits) error {
+	next := &ExporterServer{Concurrency: l.Concurrency, LimitIPReq: l.LimitIPReq, TrustedProxies: l.TrustedProxies,
+		AllowCIDRs: l.AllowCIDRs, DenyCIDRs: l.DenyCIDRs}
+	if err := next.verifyLimitParams(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.handler != nil {
+		handler, err := limiter.NewLimitHandler(http.DefaultServeMux, next.initConfig(s.limiterMetrics))
 		if err != nil {
+			return err
+		}
+		s.handler.handler.Store(&handler)
+	}
+	s.Concurrency, s.LimitIPReq = next.Concurrency, next.LimitIPReq
+	s.TrustedProxies, s.AllowCIDRs, s.DenyCIDRs = next.TrustedProxies, next.AllowCIDRs, next.DenyCIDRs
+	s.trustedProxies, s.allowCIDRs, s.denyCIDRs = next.trustedProxies, next.allowCIDRs, next.denyCIDRs
+	return nil
+}
+
+// verifyTLSParams enables HTTPS when the cert and key files are set, HTTPS is set or HTTPS_ENABLE is on,
+// the latter takes the .crt and .key files in the cert dir when the files are not set.
+func (s *ExporterServer) verifyTLSParams() error {
+	if (s.CertFile == "") != (s.KeyFile == "") {
+		return errors.New("the cert file and key file must be set together")
+	}
+	if s.CertFile == "" && !s.HTTPS && os.Getenv("HTTPS_ENABLE") != "on" {
+		if s.ClientCAFile != "" {
+			return errors.New("the client ca file requires HTTPS")
+		}
+		s.ProtocolType = HTTP
+		return nil
+	}
+	s.ProtocolType = HTTPS
+	certPath, keyPath := s.CertFile, s.KeyFile
+	if certPath == "" {
+		var err error
+		if certPath, err = getCertsFile(".crt"); err != nil {
 			return fmt.Errorf("HTTPS certificate error: %v", err)
 		}
-		keyPath, err := getCertsFile(".key")
-		if err != nil {
+		if keyPath, err = getCertsFile(".key"); err != nil {
 			return fmt.Errorf("HTTPS key error: %v", err)
 		}
-		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
-		if err != nil {
-			return fmt.Errorf("HTTPS key pair error: %v", err)
-		}
-		s.cert = &cert
-	} else {
-		s.ProtocolType = HTTP
 	}
+	certs, err := newCertReloader(certPath, keyPath, s.ClientCAFile)
+	if err != nil {
+		return err
+	}
+	s.certs = certs
 	return nil
 }
 
@@ -158,7 +269,7 @@ func getCertsFile(suffix string) (string, error) {
 	return "", fmt.Errorf("no files with the suffix '%s' found in directory: %s", suffix, certFilePath)
 }
 
-func (s *ExporterServer) initConfig() *limiter.HandlerConfig {
+func (s *ExporterServer) initConfig(metrics *limiter.Metrics) *limiter.HandlerConfig {
 	conf := &limiter.HandlerConfig{
 		PrintLog:         true,
 		Method:           http.MethodGet,
@@ -166,6 +277,10 @@ func (s *ExporterServer) initConfig() *limiter.HandlerConfig {
 		TotalConCurrency: s.Concurrency,
 		IPConCurrency:    s.LimitIPReq,
 		CacheSize:        limiter.DefaultCacheSize,
+		TrustedProxies:   s.trustedProxies,
+		AllowCIDRs:       s.allowCIDRs,
+		DenyCIDRs:        s.denyCIDRs,
+		Metrics:          metrics,
 	}
 	return conf
 }
@@ -175,10 +290,14 @@ func (s *ExporterServer) newServer(conf *limiter.HandlerConfig) (*http.Server, n
 	if err != nil {
 		return nil, nil, err
 	}
+	s.mu.Lock()
+	s.handler = newSwappableHandler(handler)
+	s.limiterMetrics = conf.Metrics
+	s.mu.Unlock()
 
 	server := &http.Server{
 		Addr:           s.Ip + ":" + strconv.Itoa(s.Port),
-		Handler:        handler,
+		Handler:        s.handler,
 		ReadTimeout:    timeout * time.Second,
 		WriteTimeout:   timeout * time.Second,
 		MaxHeaderBytes: maxHeaderBytes,
@@ -186,12 +305,13 @@ func (s *ExporterServer) newServer(conf *limiter.HandlerConfig) (*http.Server, n
 
 	// Configure TLS if needed
 	if s.ProtocolType == HTTPS {
-		server.TLSConfig = getTLSConfig()
-		server.TLSConfig.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
-			if s.cert == nil {
+		if s.certs != nil {
+			server.TLSConfig = s.certs.tlsConfig()
+		} else {
+			server.TLSConfig = getTLSConfig()
+			server.TLSConfig.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
 				return nil, fmt.Errorf("certificate not loaded")
 			}
-			return s.cert, nil
 		}
 	}
 
@@ -200,7 +320,8 @@ func (s *ExporterServer) newServer(conf *limiter.HandlerConfig) (*http.Server, n
 		return nil, nil, fmt.Errorf("listen IP and port error: %v", err)
 	}
 
-	limitLs, err := limiter.LimitListener(l, s.LimitTotalConn, s.LimitIPConn, limiter.DefaultCacheSize)
+	limitLs, err := limiter.LimitListenerWithMetrics(l, s.LimitTotalConn, s.LimitIPConn, limiter.DefaultCacheSize,
+		conf.Metrics)
 	if err != nil {
 		return nil, nil, fmt.Errorf("limit listener error: %v", err)
 	}
@@ -210,16 +331,28 @@ func (s *ExporterServer) newServer(conf *limiter.HandlerConfig) (*http.Server, n
 
 // StartServe starts the HTTP or HTTPS server based on configuration
 func (s *ExporterServer) StartServe(ctx context.Context, cancel context.CancelFunc, reg *prometheus.Registry) {
-	http.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError}))
+	http.Handle("/metrics", promhttp.HandlerFor(s.Gatherer(reg),
+		promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError}))
 	http.Handle("/", indexHandler(s))
+	s.registerAPI(http.DefaultServeMux)
 
-	conf := s.initConfig()
+	metrics, err := limiter.NewMetrics(reg)
+	if err != nil {
+		log.Errorf("register limiter metrics error: %v", err)
+	}
+	s.mu.Lock()
+	conf := s.initConfig(metrics)
+	s.mu.Unlock()
 	server, listener, err := s.newServer(conf)
 	if err != nil {
 		cancel()
 		return
 	}
 
+	if s.ProtocolType == HTTPS && s.certs != nil {
+		go s.certs.watch(ctx)
+	}
+
 	go func() {
 		var err error
 		if s.ProtocolType == HTTPS {
@@ -241,7 +374,16 @@ func (s *ExporterServer) StartServe(ctx context.Context, cancel context.CancelFu
 	}
 }
 
-// RegisterCollectorService register the collectorService based on the XPU type.
+// Gatherer returns the gatherer of the exported metrics, the families of reg are filtered and relabeled
+// by the Relabeler when it is set
+func (s *ExporterServer) Gatherer(reg *prometheus.Registry) prometheus.Gatherer {
+	if s.Relabeler == nil {
+		return reg
+	}
+	return s.Relabeler.Gatherer(reg)
+}
+
+// RegisterCollectorService register a collectorService, one service can be registered for each XPU type.
 func (s *ExporterServer) RegisterCollectorService(c collector.ICollectorService) error {
 	if s == nil {
 		return fmt.Errorf("s is nil")
@@ -249,16 +391,65 @@ func (s *ExporterServer) RegisterCollectorService(c collector.ICollectorService)
 	if c == nil {
 		return fmt.Errorf("collector service is nil")
 	}
-	s.collectService = c
+	for _, entry := range s.collectServices {
+		if entry.service.GetName() == c.GetName() {
+			return fmt.Errorf("collector service %s is already registered", c.GetName())
+		}
+	}
+	s.collectServices = append(s.collectServices, newCollectorEntry(c))
 	return nil
 }
 
-// CreateCollector create a matching collector.
-func (s *ExporterServer) CreateCollector(cacheTime time.Duration, updateTime time.Duration) prometheus.Collector {
-	return s.collectService.CreateCollector(cacheTime, updateTime)
+// CreateCollectors create the collectors of all registered services.
+// A panic in the Collect of one collector is recovered so that the metrics of the others are still exported.
+func (s *ExporterServer) CreateCollectors(cacheTime time.Duration, updateTime time.Duration) []prometheus.Collector {
+	collectors := make([]prometheus.Collector, 0, len(s.collectServices))
+	for _, entry := range s.collectServices {
+		c := entry.service.CreateCollector(cacheTime, updateTime)
+		if c == nil {
+			log.Errorf("collector service %s created no collector", entry.service.GetName())
+			continue
+		}
+		collectors = append(collectors, &isolatedCollector{name: entry.service.GetName(), collector: c, entry: entry})
+	}
+	return collectors
+}
+
+// StartCollect starting periodic XPU information collection of all registered services and wait for them to stop.
+// Each service gets its own context, cancelling it or a panic in the service stops only that service.
+// A disabled service waits until it is enabled.
+func (s *ExporterServer) StartCollect(ctx context.Context, _ context.CancelFunc) {
+	wg := &sync.WaitGroup{}
+	for _, entry := range s.collectServices {
+		wg.Add(1)
+		go func(entry *collectorEntry) {
+			defer wg.Done()
+			entry.run(ctx)
+		}(entry)
+	}
+	wg.Wait()
+}
+
+// SetCollectorsEnabled enables or disables the services by name, the services absent in enabled are enabled
+// and the names not registered are ignored, e.g. the xpu type is not detected on the node.
+// A disabled service stops collecting and its collector exports nothing until it is enabled again.
+func (s *ExporterServer) SetCollectorsEnabled(enabled map[string]bool) {
+	for name := range enabled {
+		if !s.hasCollectorService(name) {
+			log.Warningf("collector service %s is not registered, ignore its switch", name)
+		}
+	}
+	for _, entry := range s.collectServices {
+		on, ok := enabled[entry.service.GetName()]
+		entry.setEnabled(!ok || on)
+	}
 }
 
-// StartCollect starting periodic XPU information collection
-func (s *ExporterServer) StartCollect(ctx context.Context, fn context.CancelFunc) {
-	s.collectService.Start(ctx, fn)
+func (s *ExporterServer) hasCollectorService(name string) bool {
+	for _, entry := range s.collectServices {
+		if entry.service.GetName() == name {
+			return true
+		}
+	}
+	return false
 }
@@ -11,13 +11,17 @@ import (
 	"errors"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/agiledragon/gomonkey/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"huawei.com/vxpu-device-plugin/pkg/log"
+
+	"huawei.com/xpu-exporter/common/limiter"
 )
 
 type mockCollectorService struct{}
@@ -53,10 +57,10 @@ func TestVerifyServerParams(t *testing.T) {
 }
 
 func getTestCases() []struct {
-	name   string
-	server *ExporterServer
+	name    string
+	server  *ExporterServer
 	wantErr bool
-	setup  func() *gomonkey.Patches
+	setup   func() *gomonkey.Patches
 } {
 	baseServer := func() *ExporterServer {
 		return &ExporterServer{
@@ -70,10 +74,10 @@ func getTestCases() []struct {
 	}
 
 	return []struct {
-		name   string
-		server *ExporterServer
+		name    string
+		server  *ExporterServer
 		wantErr bool
-		setup  func() *gomonkey.Patches
+		setup   func() *gomonkey.Patches
 	}{
 		{
 			name:   "valid http config",
@@ -99,10 +103,10 @@ func getTestCases() []struct {
 			wantErr: true,
 		},
 		{
-			name: "https with invalid certs",
-			server: baseServer(),
+			name:    "https with invalid certs",
+			server:  baseServer(),
 			wantErr: true,
-			setup:  func() *gomonkey.Patches { return setupHTTPSFailure() },
+			setup:   func() *gomonkey.Patches { return setupHTTPSFailure() },
 		},
 	}
 }
@@ -128,7 +132,14 @@ func TestRegisterCollectorService(t *testing.T) {
 	t.Run("valid registration", func(t *testing.T) {
 		err := s.RegisterCollectorService(mockCollector)
 		assert.NoError(t, err)
-		assert.Equal(t, mockCollector, s.collectService)
+		assert.Len(t, s.collectServices, 1)
+		assert.Equal(t, mockCollector, s.collectServices[0].service)
+	})
+
+	t.Run("duplicate registration", func(t *testing.T) {
+		err := s.RegisterCollectorService(&mockCollectorService{})
+		assert.Error(t, err)
+		assert.Len(t, s.collectServices, 1)
 	})
 
 	t.Run("nil collector", func(t *testing.T) {
@@ -137,6 +148,84 @@ func TestRegisterCollectorService(t *testing.T) {
 	})
 }
 
+var testDesc = prometheus.NewDesc("test_metric", "test metric", nil, nil)
+
+// namedCollectorService collector service whose collector panics or exports test_metric
+type namedCollectorService struct {
+	name    string
+	panics  bool
+	started chan struct{}
+}
+
+func (m *namedCollectorService) CreateCollector(_, _ time.Duration) prometheus.Collector {
+	return &testCollector{panics: m.panics}
+}
+
+func (m *namedCollectorService) Start(ctx context.Context, fn context.CancelFunc) {
+	close(m.started)
+	if m.panics {
+		panic("start failed")
+	}
+	<-ctx.Done()
+}
+
+func (m *namedCollectorService) GetName() string {
+	return m.name
+}
+
+type testCollector struct {
+	panics bool
+}
+
+func (c *testCollector) Describe(ch chan<- *prometheus.Desc) {
+	if !c.panics {
+		ch <- testDesc
+	}
+}
+
+func (c *testCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.panics {
+		panic("collect failed")
+	}
+	ch <- prometheus.MustNewConstMetric(testDesc, prometheus.GaugeValue, 1)
+}
+
+func TestCollectorIsolation(t *testing.T) {
+	s := &ExporterServer{}
+	good := &namedCollectorService{name: "good", started: make(chan struct{})}
+	bad := &namedCollectorService{name: "bad", panics: true, started: make(chan struct{})}
+	assert.NoError(t, s.RegisterCollectorService(bad))
+	assert.NoError(t, s.RegisterCollectorService(good))
+
+	reg := prometheus.NewRegistry()
+	for _, c := range s.CreateCollectors(time.Minute, time.Second) {
+		assert.NoError(t, reg.Register(c))
+	}
+	families, err := reg.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, families, 1)
+	assert.Equal(t, "test_metric", families[0].GetName())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		s.StartCollect(ctx, cancel)
+		close(done)
+	}()
+	<-good.started
+	<-bad.started
+	// the panic of bad does not stop good, which stops only when the collection is cancelled
+	select {
+	case <-done:
+		t.Fatal("StartCollect returned before the collection was cancelled")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.NoError(t, ctx.Err())
+	cancel()
+	<-done
+}
+
 func TestStartServe(t *testing.T) {
 	patches := gomonkey.NewPatches()
 	defer patches.Reset()
@@ -154,7 +243,7 @@ func TestStartServe(t *testing.T) {
 		return nil
 	})
 
-	patches.ApplyFunc(log.Info, func(format string, args ...interface{}) {})
+	patches.ApplyFunc(log.Infof, func(format string, args ...interface{}) {})
 	patches.ApplyFunc(log.Errorf, func(format string, args ...interface{}) {})
 
 	t.Run("start http server", func(t *testing.T) {
@@ -163,8 +252,8 @@ func TestStartServe(t *testing.T) {
 		defer cancel()
 
 		s := &ExporterServer{
-			Port:        9119,
-			Ip:          "127.0.0.1",
+			Port:         9119,
+			Ip:           "127.0.0.1",
 			ProtocolType: HTTP,
 		}
 		reg := prometheus.NewRegistry()
@@ -185,8 +274,8 @@ func TestStartServe(t *testing.T) {
 		})
 
 		s := &ExporterServer{
-			Port:        9119,
-			Ip:          "127.0.0.1",
+			Port:         9119,
+			Ip:           "127.0.0.1",
 			ProtocolType: HTTPS,
 		}
 		reg := prometheus.NewRegistry()
@@ -206,4 +295,89 @@ func (m *mockListener) Close() error {
 
 func (m *mockListener) Addr() net.Addr {
 	return &net.TCPAddr{}
-}
\ No newline at end of file
+}
+
+// restartableCollectorService collector service reporting every start
+type restartableCollectorService struct {
+	starts chan struct{}
+}
+
+func (m *restartableCollectorService) CreateCollector(_, _ time.Duration) prometheus.Collector {
+	return &testCollector{}
+}
+
+func (m *restartableCollectorService) Start(ctx context.Context, _ context.CancelFunc) {
+	m.starts <- struct{}{}
+	<-ctx.Done()
+}
+
+func (m *restartableCollectorService) GetName() string {
+	return "restartable"
+}
+
+func TestSetCollectorsEnabled(t *testing.T) {
+	s := &ExporterServer{}
+	service := &restartableCollectorService{starts: make(chan struct{}, 1)}
+	assert.NoError(t, s.RegisterCollectorService(service))
+	reg := prometheus.NewRegistry()
+	for _, c := range s.CreateCollectors(time.Minute, time.Second) {
+		assert.NoError(t, reg.Register(c))
+	}
+	// a disabled service is not started until it is enabled
+	s.SetCollectorsEnabled(map[string]bool{"restartable": false, "unknown": true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.StartCollect(ctx, cancel)
+		close(done)
+	}()
+	select {
+	case <-service.starts:
+		t.Fatal("the disabled service is started")
+	case <-time.After(50 * time.Millisecond):
+	}
+	families, err := reg.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, families, 0)
+
+	s.SetCollectorsEnabled(nil)
+	<-service.starts
+	families, err = reg.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, families, 1)
+
+	// disabling stops the service, which starts again after it is enabled
+	s.SetCollectorsEnabled(map[string]bool{"restartable": false})
+	s.SetCollectorsEnabled(map[string]bool{"restartable": true})
+	<-service.starts
+	cancel()
+	<-done
+}
+
+func TestReloadLimits(t *testing.T) {
+	s := &ExporterServer{Concurrency: 1, LimitIPReq: "20/1"}
+	assert.NoError(t, s.verifyLimitParams())
+	handler, err := limiter.NewLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), s.initConfig(nil))
+	assert.NoError(t, err)
+	s.handler = newSwappableHandler(handler)
+	serve := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/devices", nil)
+		req.RemoteAddr = "192.168.0.1:1234"
+		w := httptest.NewRecorder()
+		s.handler.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, serve())
+
+	assert.Error(t, s.ReloadLimits(Limits{Concurrency: 0, LimitIPReq: "20/1"}))
+	assert.Error(t, s.ReloadLimits(Limits{Concurrency: 1, LimitIPReq: "20/1", DenyCIDRs: "invalid"}))
+	assert.Equal(t, http.StatusOK, serve())
+
+	assert.NoError(t, s.ReloadLimits(Limits{Concurrency: 2, LimitIPReq: "10/1", DenyCIDRs: "192.168.0.0/24"}))
+	assert.Equal(t, http.StatusForbidden, serve())
+	assert.Equal(t, 2, s.Concurrency)
+	assert.Equal(t, "192.168.0.0/24", s.DenyCIDRs)
+}